@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/rs/zerolog"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/services/requester"
+)
+
+// AdminAPI offers the RPC methods for the operators of the gateway.
+type AdminAPI struct {
+	logger zerolog.Logger
+	keys   *requester.KeyPool
+	// checking is held while the keys are checked, so only a single check runs at a time
+	checking sync.Mutex
+}
+
+func NewAdminAPI(logger zerolog.Logger, keys *requester.KeyPool) *AdminAPI {
+	return &AdminAPI{
+		logger: logger,
+		keys:   keys,
+	}
+}
+
+// KeyStatus is the status of a COA proposal key.
+type KeyStatus struct {
+	Index          hexutil.Uint64 `json:"index"`
+	PublicKey      string         `json:"publicKey"`
+	SequenceNumber hexutil.Uint64 `json:"sequenceNumber"`
+	Weight         hexutil.Uint64 `json:"weight"`
+	// Status is either available, leased or quarantined
+	Status     string         `json:"status"`
+	Quarantine string         `json:"quarantineReason,omitempty"`
+	Failures   hexutil.Uint64 `json:"failures"`
+	CheckedAt  string         `json:"checkedAt"`
+}
+
+// KeyStatus returns the status of each of the COA proposal keys.
+func (a *AdminAPI) KeyStatus() []*KeyStatus {
+	keys := a.keys.Status()
+
+	statuses := make([]*KeyStatus, len(keys))
+	for i, key := range keys {
+		status := "available"
+		switch {
+		case key.Quarantine != "":
+			status = "quarantined"
+		case key.Leased:
+			status = "leased"
+		}
+
+		statuses[i] = &KeyStatus{
+			Index:          hexutil.Uint64(key.Index),
+			PublicKey:      key.PublicKey,
+			SequenceNumber: hexutil.Uint64(key.SequenceNumber),
+			Weight:         hexutil.Uint64(key.Weight),
+			Status:         status,
+			Quarantine:     key.Quarantine,
+			Failures:       hexutil.Uint64(key.Failures),
+			CheckedAt:      key.CheckedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return statuses
+}
+
+// CheckKeys checks the COA proposal keys against the account right away, instead of
+// waiting for the periodic check, and returns the status of each of the keys.
+// The request is rejected if the keys are already being checked.
+func (a *AdminAPI) CheckKeys(ctx context.Context) ([]*KeyStatus, error) {
+	if !a.checking.TryLock() {
+		return nil, fmt.Errorf("%w: the keys are already being checked", errs.ErrRateLimit)
+	}
+	defer a.checking.Unlock()
+
+	if err := a.keys.CheckHealth(ctx); err != nil {
+		a.logger.Error().Err(err).Msg("failed to check the health of the keys")
+		return nil, err
+	}
+
+	return a.KeyStatus(), nil
+}
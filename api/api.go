@@ -32,7 +32,8 @@ import (
 
 const maxFeeHistoryBlockCount = 1024
 
-var baseFeesPerGas = big.NewInt(1)
+// errCodeVMError is the error code of the simulated calls which failed with a VM error.
+const errCodeVMError = -32015
 
 // A map containing all the valid method names that are found
 // in the Ethereum JSON-RPC API specification.
@@ -71,6 +72,7 @@ var validMethods = map[string]struct{}{
 	"eth_hashrate":                            {},
 	"eth_getProof":                            {},
 	"eth_createAccessList":                    {},
+	"eth_simulateV1":                          {},
 
 	// debug namespace
 	"debug_traceTransaction":   {},
@@ -91,6 +93,13 @@ var validMethods = map[string]struct{}{
 	"txpool_contentFrom": {},
 	"txpool_status":      {},
 	"txpool_inspect":     {},
+
+	// flow namespace
+	"flow_getTransactionStatus": {},
+
+	// admin namespace
+	"admin_keyStatus": {},
+	"admin_checkKeys": {},
 }
 
 // Returns whether the given method name is a valid method from
@@ -108,6 +117,8 @@ func SupportedAPIs(
 	pullAPI *PullAPI,
 	debugAPI *DebugAPI,
 	walletAPI *WalletAPI,
+	txPoolAPI *TxPool,
+	flowAPI *FlowAPI,
 	config *config.Config,
 ) []rpc.API {
 	apis := []rpc.API{{
@@ -127,7 +138,10 @@ func SupportedAPIs(
 		Service:   NewNetAPI(config),
 	}, {
 		Namespace: "txpool",
-		Service:   NewTxPoolAPI(),
+		Service:   txPoolAPI,
+	}, {
+		Namespace: "flow",
+		Service:   flowAPI,
 	}}
 
 	// optional debug api
@@ -156,6 +170,7 @@ type BlockChainAPI struct {
 	transactions          storage.TransactionIndexer
 	receipts              storage.ReceiptIndexer
 	accounts              storage.AccountIndexer
+	gasPrice              requester.GasPriceOracle
 	indexingResumedHeight uint64
 	limiter               limiter.Store
 	collector             metrics.Collector
@@ -169,6 +184,7 @@ func NewBlockChainAPI(
 	transactions storage.TransactionIndexer,
 	receipts storage.ReceiptIndexer,
 	accounts storage.AccountIndexer,
+	gasPrice requester.GasPriceOracle,
 	ratelimiter limiter.Store,
 	collector metrics.Collector,
 ) (*BlockChainAPI, error) {
@@ -186,6 +202,7 @@ func NewBlockChainAPI(
 		transactions:          transactions,
 		receipts:              receipts,
 		accounts:              accounts,
+		gasPrice:              gasPrice,
 		indexingResumedHeight: indexingResumedHeight,
 		limiter:               ratelimiter,
 		collector:             collector,
@@ -282,12 +299,12 @@ func (b *BlockChainAPI) GetBalance(
 		return nil, err
 	}
 
-	evmHeight, err := b.getBlockNumber(&blockNumberOrHash)
+	evmHeight, err := b.getBlockNumber(ctx, &blockNumberOrHash)
 	if err != nil {
 		return handleError[*hexutil.Big](err, l, b.collector)
 	}
 
-	balance, err := b.evm.GetBalance(ctx, address, evmHeight)
+	balance, err := b.getBalance(ctx, address, evmHeight)
 	if err != nil {
 		return handleError[*hexutil.Big](err, l, b.collector)
 	}
@@ -295,6 +312,27 @@ func (b *BlockChainAPI) GetBalance(
 	return (*hexutil.Big)(balance), nil
 }
 
+// getBalance returns the balance of the address at the EVM height from the indexed
+// accounts. If the height isn't indexed yet, or the account didn't change since the
+// accounts are indexed, the balance is read from the EVM state.
+func (b *BlockChainAPI) getBalance(ctx context.Context, address common.Address, evmHeight int64) (*big.Int, error) {
+	height, ok, err := b.indexedAccountsHeight(evmHeight)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		balance, err := b.accounts.GetBalance(address, height)
+		if err == nil {
+			return balance, nil
+		}
+		if !errors.Is(err, errs.ErrEntityNotFound) {
+			return nil, err
+		}
+	}
+
+	return b.evm.GetBalance(ctx, address, evmHeight)
+}
+
 // GetTransactionByHash returns the transaction for the given hash
 func (b *BlockChainAPI) GetTransactionByHash(
 	ctx context.Context,
@@ -373,15 +411,12 @@ func (b *BlockChainAPI) GetTransactionByBlockNumberAndIndex(
 		return nil, err
 	}
 
-	if blockNumber < rpc.EarliestBlockNumber {
-		latestBlockNumber, err := b.blocks.LatestEVMHeight()
-		if err != nil {
-			return handleError[*Transaction](err, l, b.collector)
-		}
-		blockNumber = rpc.BlockNumber(latestBlockNumber)
+	height, err := resolveBlockNumber(ctx, blockNumber, b.blocks, b.evm)
+	if err != nil {
+		return handleError[*Transaction](err, l, b.collector)
 	}
 
-	block, err := b.blocks.GetByHeight(uint64(blockNumber))
+	block, err := b.blocks.GetByHeight(height)
 	if err != nil {
 		return handleError[*Transaction](err, l, b.collector)
 	}
@@ -481,13 +516,9 @@ func (b *BlockChainAPI) GetBlockByNumber(
 		return nil, err
 	}
 
-	height := uint64(blockNumber)
-	var err error
-	if blockNumber < 0 {
-		height, err = b.blocks.LatestEVMHeight()
-		if err != nil {
-			return handleError[*Block](err, l, b.collector)
-		}
+	height, err := resolveBlockNumber(ctx, blockNumber, b.blocks, b.evm)
+	if err != nil {
+		return handleError[*Block](err, l, b.collector)
 	}
 
 	block, err := b.blocks.GetByHeight(height)
@@ -525,7 +556,11 @@ func (b *BlockChainAPI) GetBlockReceipts(
 	if blockNumberOrHash.BlockHash != nil {
 		block, err = b.blocks.GetByID(*blockNumberOrHash.BlockHash)
 	} else if blockNumberOrHash.BlockNumber != nil {
-		block, err = b.blocks.GetByHeight(uint64(blockNumberOrHash.BlockNumber.Int64()))
+		var height uint64
+		height, err = resolveBlockNumber(ctx, *blockNumberOrHash.BlockNumber, b.blocks, b.evm)
+		if err == nil {
+			block, err = b.blocks.GetByHeight(height)
+		}
 	} else {
 		return handleError[[]map[string]interface{}](
 			fmt.Errorf("%w: block number or hash not provided", errs.ErrInvalid),
@@ -597,15 +632,12 @@ func (b *BlockChainAPI) GetBlockTransactionCountByNumber(
 		return nil, err
 	}
 
-	if blockNumber < rpc.EarliestBlockNumber {
-		latestBlockNumber, err := b.blocks.LatestEVMHeight()
-		if err != nil {
-			return handleError[*hexutil.Uint](err, l, b.collector)
-		}
-		blockNumber = rpc.BlockNumber(latestBlockNumber)
+	height, err := resolveBlockNumber(ctx, blockNumber, b.blocks, b.evm)
+	if err != nil {
+		return handleError[*hexutil.Uint](err, l, b.collector)
 	}
 
-	block, err := b.blocks.GetByHeight(uint64(blockNumber))
+	block, err := b.blocks.GetByHeight(height)
 	if err != nil {
 		return handleError[*hexutil.Uint](err, l, b.collector)
 	}
@@ -622,8 +654,8 @@ func (b *BlockChainAPI) Call(
 	ctx context.Context,
 	args TransactionArgs,
 	blockNumberOrHash *rpc.BlockNumberOrHash,
-	overrides *StateOverride,
-	blockOverrides *BlockOverrides,
+	overrides *models.StateOverride,
+	blockOverrides *models.BlockOverrides,
 ) (hexutil.Bytes, error) {
 	l := b.logger.With().
 		Str("endpoint", "call").
@@ -644,12 +676,12 @@ func (b *BlockChainAPI) Call(
 		blockNumberOrHash = &latestBlockNumberOrHash
 	}
 
-	evmHeight, err := b.getBlockNumber(blockNumberOrHash)
+	evmHeight, err := b.getBlockNumber(ctx, blockNumberOrHash)
 	if err != nil {
 		return handleError[hexutil.Bytes](err, l, b.collector)
 	}
 
-	tx, err := encodeTxFromArgs(args)
+	tx, err := encodeTxFromArgs(args, b.config.RPCGasCap)
 	if err != nil {
 		return handleError[hexutil.Bytes](err, l, b.collector)
 	}
@@ -660,7 +692,12 @@ func (b *BlockChainAPI) Call(
 		from = *args.From
 	}
 
-	res, err := b.evm.Call(ctx, tx, from, evmHeight)
+	var stateOverrides models.StateOverride
+	if overrides != nil {
+		stateOverrides = *overrides
+	}
+
+	res, err := b.evm.Call(ctx, tx, from, evmHeight, stateOverrides, blockOverrides)
 	if err != nil {
 		return handleError[hexutil.Bytes](err, l, b.collector)
 	}
@@ -714,21 +751,16 @@ func (b *BlockChainAPI) GetLogs(
 		to = criteria.ToBlock
 	}
 
-	h, err := b.blocks.LatestEVMHeight()
+	fromHeight, err := resolveBlockNumber(ctx, rpc.BlockNumber(from.Int64()), b.blocks, b.evm)
 	if err != nil {
 		return handleError[[]*types.Log](err, l, b.collector)
 	}
-	latest := big.NewInt(int64(h))
-
-	// if special value, use latest block number
-	if from.Cmp(models.EarliestBlockNumber) < 0 {
-		from = latest
-	}
-	if to.Cmp(models.EarliestBlockNumber) < 0 {
-		to = latest
+	toHeight, err := resolveBlockNumber(ctx, rpc.BlockNumber(to.Int64()), b.blocks, b.evm)
+	if err != nil {
+		return handleError[[]*types.Log](err, l, b.collector)
 	}
 
-	f, err := logs.NewRangeFilter(from.Uint64(), to.Uint64(), filter, b.receipts)
+	f, err := logs.NewRangeFilter(fromHeight, toHeight, filter, b.receipts)
 	if err != nil {
 		return handleError[[]*types.Log](err, l, b.collector)
 	}
@@ -762,30 +794,63 @@ func (b *BlockChainAPI) GetTransactionCount(
 		return nil, err
 	}
 
-	evmHeight, err := b.getBlockNumber(&blockNumberOrHash)
+	evmHeight, err := b.getBlockNumber(ctx, &blockNumberOrHash)
 	if err != nil {
 		return handleError[*hexutil.Uint64](err, l, b.collector)
 	}
 
-	networkNonce, err := b.evm.GetNonce(ctx, address, evmHeight)
+	nonce, err := b.getNonce(ctx, address, evmHeight)
 	if err != nil {
 		return handleError[*hexutil.Uint64](err, l, b.collector)
 	}
 
-	nonce, err := b.accounts.GetNonce(address)
+	// the pending nonce includes the submitted transactions that are not yet sealed
+	if number, ok := blockNumberOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		nonce = b.evm.GetPendingNonce(address, nonce)
+	}
+
+	return (*hexutil.Uint64)(&nonce), nil
+}
+
+// getNonce returns the nonce of the address at the EVM height from the indexed
+// accounts. If the height isn't indexed yet, or the account didn't change since the
+// accounts are indexed, the nonce is read from the EVM state.
+func (b *BlockChainAPI) getNonce(ctx context.Context, address common.Address, evmHeight int64) (uint64, error) {
+	height, ok, err := b.indexedAccountsHeight(evmHeight)
 	if err != nil {
-		return handleError[*hexutil.Uint64](errs.ErrInternal, l, b.collector)
+		return 0, err
+	}
+	if ok {
+		nonce, err := b.accounts.GetNonce(address, height)
+		if err == nil {
+			return nonce, nil
+		}
+		if !errors.Is(err, errs.ErrEntityNotFound) {
+			return 0, err
+		}
 	}
 
-	// compare both until we gain confidence in db nonce tracking working correctly
-	if nonce != networkNonce {
-		l.Error().
-			Uint64("network-nonce", networkNonce).
-			Uint64("db-nonce", nonce).
-			Msg("network nonce does not equal db nonce")
+	return b.evm.GetNonce(ctx, address, evmHeight)
+}
+
+// indexedAccountsHeight resolves the EVM height, which can be a block tag, to the
+// height of the indexed accounts. The accounts are only indexed together with the
+// state registers, and it returns false if they are not indexed at the height.
+func (b *BlockChainAPI) indexedAccountsHeight(evmHeight int64) (uint64, bool, error) {
+	if !b.config.IndexState && !b.config.ArchiveMode {
+		return 0, false, nil
+	}
+
+	latest, err := b.blocks.LatestEVMHeight()
+	if err != nil {
+		return 0, false, err
+	}
+	// the block tags are resolved to the latest indexed height
+	if evmHeight < 0 {
+		return latest, true, nil
 	}
 
-	return (*hexutil.Uint64)(&networkNonce), nil
+	return uint64(evmHeight), uint64(evmHeight) <= latest, nil
 }
 
 // EstimateGas returns the lowest possible gas limit that allows the transaction to run
@@ -797,7 +862,8 @@ func (b *BlockChainAPI) EstimateGas(
 	ctx context.Context,
 	args TransactionArgs,
 	blockNumberOrHash *rpc.BlockNumberOrHash,
-	overrides *StateOverride,
+	overrides *models.StateOverride,
+	blockOverrides *models.BlockOverrides,
 ) (hexutil.Uint64, error) {
 	l := b.logger.With().
 		Str("endpoint", "estimateGas").
@@ -813,7 +879,7 @@ func (b *BlockChainAPI) EstimateGas(
 		return handleError[hexutil.Uint64](err, l, b.collector)
 	}
 
-	tx, err := encodeTxFromArgs(args)
+	tx, err := encodeTxFromArgs(args, b.config.RPCGasCap)
 	if err != nil {
 		return hexutil.Uint64(blockGasLimit), nil // return block gas limit
 	}
@@ -828,12 +894,17 @@ func (b *BlockChainAPI) EstimateGas(
 		blockNumberOrHash = &latestBlockNumberOrHash
 	}
 
-	evmHeight, err := b.getBlockNumber(blockNumberOrHash)
+	evmHeight, err := b.getBlockNumber(ctx, blockNumberOrHash)
 	if err != nil {
 		return handleError[hexutil.Uint64](err, l, b.collector)
 	}
 
-	estimatedGas, err := b.evm.EstimateGas(ctx, tx, from, evmHeight)
+	var stateOverrides models.StateOverride
+	if overrides != nil {
+		stateOverrides = *overrides
+	}
+
+	estimatedGas, err := b.evm.EstimateGas(ctx, tx, from, evmHeight, stateOverrides, blockOverrides)
 	if err != nil {
 		return handleError[hexutil.Uint64](err, l, b.collector)
 	}
@@ -841,6 +912,160 @@ func (b *BlockChainAPI) EstimateGas(
 	return hexutil.Uint64(estimatedGas), nil
 }
 
+// SimulateV1 executes series of blocks of calls on top of the state for the given
+// block number or hash, where each call observes the state changes of all the previous
+// calls. The state and block overrides can be provided for each block.
+func (b *BlockChainAPI) SimulateV1(
+	ctx context.Context,
+	payload SimulatePayload,
+	blockNumberOrHash *rpc.BlockNumberOrHash,
+) ([]*SimulateBlockResult, error) {
+	l := b.logger.With().
+		Str("endpoint", "simulateV1").
+		Int("blocks", len(payload.BlockStateCalls)).
+		Logger()
+
+	if err := rateLimit(ctx, b.limiter, l); err != nil {
+		return nil, err
+	}
+
+	if len(payload.BlockStateCalls) == 0 {
+		return handleError[[]*SimulateBlockResult](
+			fmt.Errorf("%w: empty input", errs.ErrInvalid),
+			l,
+			b.collector,
+		)
+	}
+	if payload.Validation {
+		return handleError[[]*SimulateBlockResult](
+			fmt.Errorf("%w: simulation with validation is not supported", errs.ErrInvalid),
+			l,
+			b.collector,
+		)
+	}
+
+	blocks := make([]requester.SimulateBlock, len(payload.BlockStateCalls))
+	for i, blockArgs := range payload.BlockStateCalls {
+		calls := make([]requester.SimulateCall, len(blockArgs.Calls))
+		for j, args := range blockArgs.Calls {
+			if err := args.Validate(); err != nil {
+				return handleError[[]*SimulateBlockResult](err, l, b.collector)
+			}
+
+			tx, err := encodeTxFromArgs(args, b.config.RPCGasCap)
+			if err != nil {
+				return handleError[[]*SimulateBlockResult](err, l, b.collector)
+			}
+
+			// Default address in case user does not provide one
+			from := b.config.Coinbase
+			if args.From != nil {
+				from = *args.From
+			}
+
+			calls[j] = requester.SimulateCall{From: from, Data: tx}
+		}
+
+		var stateOverrides models.StateOverride
+		if blockArgs.StateOverrides != nil {
+			stateOverrides = *blockArgs.StateOverrides
+		}
+
+		blocks[i] = requester.SimulateBlock{
+			BlockOverrides: blockArgs.BlockOverrides,
+			StateOverrides: stateOverrides,
+			Calls:          calls,
+		}
+	}
+
+	// Default to "latest" block tag
+	if blockNumberOrHash == nil {
+		blockNumberOrHash = &latestBlockNumberOrHash
+	}
+
+	evmHeight, err := b.getBlockNumber(ctx, blockNumberOrHash)
+	if err != nil {
+		return handleError[[]*SimulateBlockResult](err, l, b.collector)
+	}
+
+	simulated, err := b.evm.Simulate(ctx, blocks, evmHeight, payload.TraceTransfers)
+	if err != nil {
+		return handleError[[]*SimulateBlockResult](err, l, b.collector)
+	}
+
+	results := make([]*SimulateBlockResult, len(simulated))
+	for i, block := range simulated {
+		miner := evmTypes.CoinbaseAddress.ToCommon()
+		if overrides := payload.BlockStateCalls[i].BlockOverrides; overrides != nil && overrides.Coinbase != nil {
+			miner = *overrides.Coinbase
+		}
+
+		results[i], err = b.prepareSimulatedBlockResponse(block, miner, payload.ReturnFullTransactions)
+		if err != nil {
+			return handleError[[]*SimulateBlockResult](err, l, b.collector)
+		}
+	}
+
+	return results, nil
+}
+
+// CreateAccessList creates an EIP-2930 type AccessList for the given transaction.
+// BlockNumberOrHash can be specified to create the accessList on top of a certain state.
+func (b *BlockChainAPI) CreateAccessList(
+	ctx context.Context,
+	args TransactionArgs,
+	blockNumberOrHash *rpc.BlockNumberOrHash,
+) (*AccessListResult, error) {
+	l := b.logger.With().
+		Str("endpoint", "createAccessList").
+		Str("args", fmt.Sprintf("%v", args)).
+		Logger()
+
+	if err := rateLimit(ctx, b.limiter, l); err != nil {
+		return nil, err
+	}
+
+	err := args.Validate()
+	if err != nil {
+		return handleError[*AccessListResult](err, l, b.collector)
+	}
+
+	tx, err := encodeTxFromArgs(args, b.config.RPCGasCap)
+	if err != nil {
+		return handleError[*AccessListResult](err, l, b.collector)
+	}
+
+	// Default address in case user does not provide one
+	from := b.config.Coinbase
+	if args.From != nil {
+		from = *args.From
+	}
+
+	if blockNumberOrHash == nil {
+		blockNumberOrHash = &latestBlockNumberOrHash
+	}
+
+	evmHeight, err := b.getBlockNumber(ctx, blockNumberOrHash)
+	if err != nil {
+		return handleError[*AccessListResult](err, l, b.collector)
+	}
+
+	accessList, res, err := b.evm.CreateAccessList(ctx, tx, from, evmHeight)
+	if err != nil {
+		return handleError[*AccessListResult](err, l, b.collector)
+	}
+
+	result := &AccessListResult{
+		Accesslist: &accessList,
+		GasUsed:    hexutil.Uint64(res.GasConsumed),
+	}
+	if res.VMError != nil {
+		result.Error = res.VMError.Error()
+	}
+
+	return result, nil
+}
+
 // GetCode returns the code stored at the given address in
 // the state for the given block number.
 func (b *BlockChainAPI) GetCode(
@@ -857,7 +1082,7 @@ func (b *BlockChainAPI) GetCode(
 		return nil, err
 	}
 
-	evmHeight, err := b.getBlockNumber(&blockNumberOrHash)
+	evmHeight, err := b.getBlockNumber(ctx, &blockNumberOrHash)
 	if err != nil {
 		return handleError[hexutil.Bytes](err, l, b.collector)
 	}
@@ -898,16 +1123,32 @@ func (b *BlockChainAPI) FeeHistory(
 		)
 	}
 
-	lastBlockNumber := uint64(lastBlock)
-	var err error
-	if lastBlock < 0 {
-		// From the special block tags, we only support "latest".
-		lastBlockNumber, err = b.blocks.LatestEVMHeight()
-		if err != nil {
-			return handleError[*FeeHistoryResult](err, l, b.collector)
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < rewardPercentiles[i-1]) {
+			return handleError[*FeeHistoryResult](
+				fmt.Errorf("%w: invalid reward percentile: %f", errs.ErrInvalid, p),
+				l,
+				b.collector,
+			)
 		}
 	}
 
+	lastBlockNumber, err := resolveBlockNumber(ctx, lastBlock, b.blocks, b.evm)
+	if err != nil {
+		return handleError[*FeeHistoryResult](err, l, b.collector)
+	}
+
+	minGasPrice, err := b.gasPrice.MinGasPrice()
+	if err != nil {
+		return handleError[*FeeHistoryResult](err, l, b.collector)
+	}
+	// the rewards are the tips above the base fee, so the lowest reward is the
+	// tip of the minimum gas price
+	minTip := big.NewInt(0)
+	if minGasPrice.Cmp(models.BaseFeePerGas) > 0 {
+		minTip.Sub(minGasPrice, models.BaseFeePerGas)
+	}
+
 	var (
 		oldestBlock   *hexutil.Big
 		baseFees      []*hexutil.Big
@@ -920,11 +1161,6 @@ func (b *BlockChainAPI) FeeHistory(
 		maxCount = lastBlockNumber
 	}
 
-	blockRewards := make([]*hexutil.Big, len(rewardPercentiles))
-	for i := range rewardPercentiles {
-		blockRewards[i] = (*hexutil.Big)(b.config.GasPrice)
-	}
-
 	for i := maxCount; i >= uint64(1); i-- {
 		// If the requested block count is 5, and the last block number
 		// is 20, then we need the blocks [16, 17, 18, 19, 20] in this
@@ -939,9 +1175,14 @@ func (b *BlockChainAPI) FeeHistory(
 			oldestBlock = (*hexutil.Big)(big.NewInt(int64(block.Height)))
 		}
 
-		baseFees = append(baseFees, (*hexutil.Big)(baseFeesPerGas))
+		fees, err := b.receipts.FeesByBlockHeight(blockHeight)
+		if err != nil && !errors.Is(err, errs.ErrEntityNotFound) {
+			return handleError[*FeeHistoryResult](err, l, b.collector)
+		}
+
+		baseFees = append(baseFees, (*hexutil.Big)(models.BaseFeePerGas))
 
-		rewards = append(rewards, blockRewards)
+		rewards = append(rewards, feeRewards(fees, rewardPercentiles, minTip))
 
 		gasUsedRatio := float64(block.TotalGasUsed) / float64(blockGasLimit)
 		gasUsedRatios = append(gasUsedRatios, gasUsedRatio)
@@ -955,6 +1196,24 @@ func (b *BlockChainAPI) FeeHistory(
 	}, nil
 }
 
+// feeRewards returns the effective tips at the reward percentiles of the block transaction
+// fees, sorted by tip, where each transaction is weighted by the gas it used.
+// The tips below the tip of the minimum gas price, including the tips of the empty
+// blocks, are raised to the minimum tip, since lower tips aren't accepted.
+func feeRewards(fees []*models.TransactionFee, percentiles []float64, minTip *big.Int) []*hexutil.Big {
+	rewards := make([]*hexutil.Big, len(percentiles))
+	for i := range rewards {
+		rewards[i] = (*hexutil.Big)(minTip)
+	}
+	for i, tip := range models.TipsAtPercentiles(fees, percentiles) {
+		if tip.Cmp(minTip) > 0 {
+			rewards[i] = (*hexutil.Big)(tip)
+		}
+	}
+
+	return rewards
+}
+
 // GetStorageAt returns the storage from the state at the given address, key and
 // block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta block
 // numbers are also allowed.
@@ -982,7 +1241,7 @@ func (b *BlockChainAPI) GetStorageAt(
 		)
 	}
 
-	evmHeight, err := b.getBlockNumber(&blockNumberOrHash)
+	evmHeight, err := b.getBlockNumber(ctx, &blockNumberOrHash)
 	if err != nil {
 		return handleError[hexutil.Bytes](err, l, b.collector)
 	}
@@ -995,6 +1254,20 @@ func (b *BlockChainAPI) GetStorageAt(
 	return result[:], nil
 }
 
+// GetProof returns the Merkle-proof for a given account and optionally some storage keys.
+// It's not supported, since EVM on Flow doesn't keep the state in a Merkle-Patricia trie,
+// and the access API doesn't provide the inclusion proofs of the EVM storage account
+// registers against the Flow execution state commitment, which would be needed to
+// verify the account and storage values without trusting the gateway.
+func (b *BlockChainAPI) GetProof(
+	ctx context.Context,
+	address common.Address,
+	storageKeys []string,
+	blockNumberOrHash rpc.BlockNumberOrHash,
+) (*AccountResult, error) {
+	return nil, errs.NewEndpointNotSupportedError("eth_getProof")
+}
+
 func (b *BlockChainAPI) fetchBlockTransactions(
 	block *models.Block,
 ) ([]*Transaction, error) {
@@ -1055,7 +1328,7 @@ func (b *BlockChainAPI) prepareBlockResponse(
 		GasLimit:         hexutil.Uint64(blockGasLimit),
 		Nonce:            types.BlockNonce{0x1},
 		Timestamp:        hexutil.Uint64(block.Timestamp),
-		BaseFeePerGas:    hexutil.Big(*baseFeesPerGas),
+		BaseFeePerGas:    hexutil.Big(*models.BaseFeePerGas),
 		LogsBloom:        types.LogsBloom([]*types.Log{}),
 		Miner:            evmTypes.CoinbaseAddress.ToCommon(),
 		Sha3Uncles:       types.EmptyUncleHash,
@@ -1097,19 +1370,133 @@ func (b *BlockChainAPI) prepareBlockResponse(
 	return blockResponse, nil
 }
 
-func (b *BlockChainAPI) getBlockNumber(blockNumberOrHash *rpc.BlockNumberOrHash) (int64, error) {
+func (b *BlockChainAPI) prepareSimulatedBlockResponse(
+	simulated *requester.SimulatedBlock,
+	miner common.Address,
+	fullTx bool,
+) (*SimulateBlockResult, error) {
+	block := simulated.Block
+	h, err := block.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	blockResponse := &Block{
+		Hash:             h,
+		Number:           hexutil.Uint64(block.Height),
+		ParentHash:       block.ParentBlockHash,
+		ReceiptsRoot:     block.ReceiptRoot,
+		TransactionsRoot: block.TransactionHashRoot,
+		Transactions:     block.TransactionHashes,
+		Uncles:           []common.Hash{},
+		GasLimit:         hexutil.Uint64(blockGasLimit),
+		GasUsed:          hexutil.Uint64(block.TotalGasUsed),
+		Nonce:            types.BlockNonce{0x1},
+		Timestamp:        hexutil.Uint64(block.Timestamp),
+		BaseFeePerGas:    hexutil.Big(*models.BaseFeePerGas),
+		MixHash:          block.PrevRandao,
+		Miner:            miner,
+		Sha3Uncles:       types.EmptyUncleHash,
+	}
+
+	blockBytes, err := block.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	blockSize := rlp.ListSize(uint64(len(blockBytes)))
+
+	logs := make([]*types.Log, 0)
+	transactions := make([]*Transaction, len(simulated.Calls))
+	calls := make([]SimulateCallResult, len(simulated.Calls))
+	for i, call := range simulated.Calls {
+		tx, err := NewTransaction(call.Transaction, b.config.EVMNetworkID)
+		if err != nil {
+			return nil, err
+		}
+		index := hexutil.Uint64(i)
+		tx.TransactionIndex = &index
+		tx.BlockHash = &h
+		tx.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(block.Height))
+		transactions[i] = tx
+		blockSize += tx.Size()
+
+		callLogs := call.Logs
+		if callLogs == nil {
+			callLogs = []*types.Log{}
+		}
+		logs = append(logs, callLogs...)
+
+		res := call.Result
+		calls[i] = SimulateCallResult{
+			ReturnData: res.ReturnedData,
+			Logs:       callLogs,
+			GasUsed:    hexutil.Uint64(res.GasConsumed),
+			Status:     hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if res.VMError != nil {
+			calls[i].Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			calls[i].Error = &SimulateCallError{
+				Code:    errCodeVMError,
+				Message: res.VMError.Error(),
+			}
+			if res.ResultSummary().ErrorCode == evmTypes.ExecutionErrCodeExecutionReverted {
+				revertErr := errs.NewRevertError(res.ReturnedData)
+				calls[i].Error = &SimulateCallError{
+					Code:    revertErr.ErrorCode(),
+					Message: revertErr.Error(),
+					Data:    revertErr.Reason,
+				}
+			}
+		}
+	}
+
+	blockResponse.LogsBloom = types.LogsBloom(logs)
+	blockResponse.Size = hexutil.Uint64(rlp.ListSize(blockSize))
+	if fullTx {
+		blockResponse.Transactions = transactions
+	}
+
+	return &SimulateBlockResult{
+		Block: blockResponse,
+		Calls: calls,
+	}, nil
+}
+
+func (b *BlockChainAPI) getBlockNumber(
+	ctx context.Context,
+	blockNumberOrHash *rpc.BlockNumberOrHash,
+) (int64, error) {
+	return getBlockNumber(ctx, blockNumberOrHash, b.blocks, b.evm, b.logger)
+}
+
+// getBlockNumber resolves the block number or hash to an EVM height, the latest
+// and pending block numbers are resolved by the requester.
+func getBlockNumber(
+	ctx context.Context,
+	blockNumberOrHash *rpc.BlockNumberOrHash,
+	blocks storage.BlockIndexer,
+	evm requester.Requester,
+	logger zerolog.Logger,
+) (int64, error) {
 	err := fmt.Errorf("%w: neither block number nor hash specified", errs.ErrInvalid)
 	if blockNumberOrHash == nil {
 		return 0, err
 	}
 	if number, ok := blockNumberOrHash.Number(); ok {
+		if number == rpc.FinalizedBlockNumber || number == rpc.SafeBlockNumber {
+			height, err := evm.GetSealedEVMHeight(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return int64(height), nil
+		}
 		return number.Int64(), nil
 	}
 
 	if hash, ok := blockNumberOrHash.Hash(); ok {
-		evmHeight, err := b.blocks.GetHeightByID(hash)
+		evmHeight, err := blocks.GetHeightByID(hash)
 		if err != nil {
-			b.logger.Error().Err(err).Msg("failed to get block by hash")
+			logger.Error().Err(err).Msg("failed to get block by hash")
 			return 0, err
 		}
 		return int64(evmHeight), nil
@@ -1118,6 +1505,29 @@ func (b *BlockChainAPI) getBlockNumber(blockNumberOrHash *rpc.BlockNumberOrHash)
 	return 0, err
 }
 
+// resolveBlockNumber resolves the block number, which can be a block tag, to an
+// indexed EVM height. The "latest" and "pending" tags resolve to the latest indexed
+// height, and the "finalized" and "safe" tags resolve to the latest indexed height
+// of a sealed Cadence block, since the sealed blocks are final.
+func resolveBlockNumber(
+	ctx context.Context,
+	number rpc.BlockNumber,
+	blocks storage.BlockIndexer,
+	evm requester.Requester,
+) (uint64, error) {
+	switch number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return blocks.LatestEVMHeight()
+	case rpc.FinalizedBlockNumber, rpc.SafeBlockNumber:
+		return evm.GetSealedEVMHeight(ctx)
+	}
+	if number < rpc.EarliestBlockNumber {
+		return 0, fmt.Errorf("%w: unsupported block number: %d", errs.ErrInvalid, number)
+	}
+
+	return uint64(number), nil
+}
+
 // handleError takes in an error and in case the error is of type ErrEntityNotFound
 // it returns nil instead of an error since that is according to the API spec,
 // if the error is not of type ErrEntityNotFound it will return the error and the generic
@@ -1191,7 +1601,12 @@ func (b *BlockChainAPI) Coinbase(ctx context.Context) (common.Address, error) {
 
 // GasPrice returns a suggestion for a gas price for legacy transactions.
 func (b *BlockChainAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
-	return (*hexutil.Big)(b.config.GasPrice), nil
+	gasPrice, err := b.gasPrice.GasPrice()
+	if err != nil {
+		return handleError[*hexutil.Big](err, b.logger, b.collector)
+	}
+
+	return (*hexutil.Big)(gasPrice), nil
 }
 
 // GetUncleCountByBlockHash returns number of uncles in the block for the given block hash
@@ -1232,7 +1647,12 @@ func (b *BlockChainAPI) GetUncleByBlockNumberAndIndex(
 
 // MaxPriorityFeePerGas returns a suggestion for a gas tip cap for dynamic fee transactions.
 func (b *BlockChainAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
-	return (*hexutil.Big)(b.config.GasPrice), nil
+	gasPrice, err := b.gasPrice.GasPrice()
+	if err != nil {
+		return handleError[*hexutil.Big](err, b.logger, b.collector)
+	}
+
+	return (*hexutil.Big)(gasPrice), nil
 }
 
 // Mining returns true if client is actively mining new blocks.
@@ -1249,31 +1669,3 @@ func (b *BlockChainAPI) Mining() bool {
 func (b *BlockChainAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(0)
 }
-
-/*
-Not supported section
-
-The API endpoints bellow return a non-supported error indicating the API requested is not supported (yet).
-This is because a decision to not support this API was made either because we don't intend to support it
-ever or we don't support it at this phase.
-*/
-
-// GetProof returns the Merkle-proof for a given account and optionally some storage keys.
-func (b *BlockChainAPI) GetProof(
-	ctx context.Context,
-	address common.Address,
-	storageKeys []string,
-	blockNumberOrHash rpc.BlockNumberOrHash,
-) (*AccountResult, error) {
-	return nil, errs.NewEndpointNotSupportedError("eth_getProof")
-}
-
-// CreateAccessList creates an EIP-2930 type AccessList for the given transaction.
-// Reexec and blockNumberOrHash can be specified to create the accessList on top of a certain state.
-func (b *BlockChainAPI) CreateAccessList(
-	ctx context.Context,
-	args TransactionArgs,
-	blockNumberOrHash *rpc.BlockNumberOrHash,
-) (*AccessListResult, error) {
-	return nil, errs.NewEndpointNotSupportedError("eth_createAccessList")
-}
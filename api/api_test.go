@@ -0,0 +1,41 @@
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-evm-gateway/models"
+)
+
+func TestFeeRewards(t *testing.T) {
+	minTip := big.NewInt(100)
+	percentiles := []float64{0, 25, 50, 75, 100}
+
+	t.Run("empty block", func(t *testing.T) {
+		rewards := feeRewards(nil, percentiles, minTip)
+		for _, reward := range rewards {
+			assert.Equal(t, minTip, reward.ToInt())
+		}
+	})
+
+	t.Run("rewards weighted by gas used", func(t *testing.T) {
+		fees := []*models.TransactionFee{
+			{GasUsed: 10, Tip: big.NewInt(50)},
+			{GasUsed: 20, Tip: big.NewInt(200)},
+			{GasUsed: 60, Tip: big.NewInt(300)},
+			{GasUsed: 10, Tip: big.NewInt(400)},
+		}
+
+		rewards := feeRewards(fees, percentiles, minTip)
+		assert.Equal(t, []*hexutil.Big{
+			(*hexutil.Big)(minTip), // the tip below the minimum is raised
+			(*hexutil.Big)(big.NewInt(200)),
+			(*hexutil.Big)(big.NewInt(300)),
+			(*hexutil.Big)(big.NewInt(300)),
+			(*hexutil.Big)(big.NewInt(400)),
+		}, rewards)
+	})
+}
@@ -16,7 +16,8 @@ const blockGasLimit uint64 = 120_000_000
 // `EVM.dryRun` inside Cadence scripts, meaning that no state change
 // will occur.
 // This is only useful for `eth_estimateGas` and `eth_call` endpoints.
-func encodeTxFromArgs(args TransactionArgs) ([]byte, error) {
+// The gas limit is capped by the given gas cap, unless the gas cap is 0.
+func encodeTxFromArgs(args TransactionArgs, gasCap uint64) ([]byte, error) {
 	var data []byte
 	if args.Data != nil {
 		data = *args.Data
@@ -30,6 +31,9 @@ func encodeTxFromArgs(args TransactionArgs) ([]byte, error) {
 	if args.Gas != nil {
 		gasLimit = uint64(*args.Gas)
 	}
+	if gasCap != 0 && gasLimit > gasCap {
+		gasLimit = gasCap
+	}
 
 	value := big.NewInt(0)
 	if args.Value != nil {
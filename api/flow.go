@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+	"github.com/sethvargo/go-limiter"
+
+	"github.com/onflow/flow-evm-gateway/metrics"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/services/requester"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+const (
+	// TransactionReceived is the status of the EVM transaction accepted by the gateway,
+	// which is queued or collected into a batch, and not yet submitted in a Flow transaction.
+	TransactionReceived = "received"
+	// TransactionIndexed is the status of the EVM transaction indexed by the gateway,
+	// so its receipt is available.
+	TransactionIndexed = "indexed"
+)
+
+// FlowAPI offers the Flow specific RPC methods.
+type FlowAPI struct {
+	logger    zerolog.Logger
+	evm       requester.Requester
+	pool      *requester.TxPool
+	blocks    storage.BlockIndexer
+	receipts  storage.ReceiptIndexer
+	limiter   limiter.Store
+	collector metrics.Collector
+}
+
+func NewFlowAPI(
+	logger zerolog.Logger,
+	evm requester.Requester,
+	pool *requester.TxPool,
+	blocks storage.BlockIndexer,
+	receipts storage.ReceiptIndexer,
+	ratelimiter limiter.Store,
+	collector metrics.Collector,
+) *FlowAPI {
+	return &FlowAPI{
+		logger:    logger,
+		evm:       evm,
+		pool:      pool,
+		blocks:    blocks,
+		receipts:  receipts,
+		limiter:   ratelimiter,
+		collector: collector,
+	}
+}
+
+// TransactionStatus is the lifecycle status of an EVM transaction, which is one of
+// received, submitted, sealed, failed or indexed.
+type TransactionStatus struct {
+	Status            string          `json:"status"`
+	FlowTransactionID string          `json:"flowTransactionId,omitempty"`
+	BlockNumber       *hexutil.Big    `json:"blockNumber,omitempty"`
+	SubmittedAt       *hexutil.Uint64 `json:"submittedAt,omitempty"`
+	Error             string          `json:"error,omitempty"`
+}
+
+// GetTransactionStatus returns the lifecycle status of the EVM transaction, sourced from
+// the index, the submissions made by the gateway and its transaction pool. It returns null
+// if the transaction is neither indexed nor was received by the gateway.
+func (f *FlowAPI) GetTransactionStatus(
+	ctx context.Context,
+	hash common.Hash,
+) (*TransactionStatus, error) {
+	l := f.logger.With().
+		Str("endpoint", "getTransactionStatus").
+		Str("hash", hash.String()).
+		Logger()
+
+	if err := rateLimit(ctx, f.limiter, l); err != nil {
+		return nil, err
+	}
+
+	submission, err := f.pool.Submission(hash)
+	if err != nil && !errors.Is(err, errs.ErrEntityNotFound) {
+		return handleError[*TransactionStatus](err, l, f.collector)
+	}
+
+	var status *TransactionStatus
+	if submission != nil {
+		status = &TransactionStatus{
+			Status: string(submission.Status),
+			Error:  submission.Error,
+		}
+		// the submission could fail before the flow transaction was built
+		if submission.FlowID != flow.EmptyID {
+			status.FlowTransactionID = submission.FlowID.String()
+		}
+		if submission.SubmittedAt != 0 {
+			submittedAt := hexutil.Uint64(submission.SubmittedAt)
+			status.SubmittedAt = &submittedAt
+		}
+	}
+
+	receipt, err := f.receipts.GetByTransactionID(hash)
+	switch {
+	case err == nil:
+		if status == nil {
+			status = &TransactionStatus{}
+		}
+		status.Status = TransactionIndexed
+		status.BlockNumber = (*hexutil.Big)(receipt.BlockNumber)
+		if receipt.Status == gethTypes.ReceiptStatusFailed {
+			status.Error = errs.NewRevertError(receipt.RevertReason).Error()
+		}
+	case !errors.Is(err, errs.ErrEntityNotFound):
+		return handleError[*TransactionStatus](err, l, f.collector)
+	case status == nil && f.pool.Received(hash):
+		status = &TransactionStatus{Status: TransactionReceived}
+	}
+
+	return status, nil
+}
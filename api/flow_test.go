@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/crypto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/metrics"
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/services/requester"
+	"github.com/onflow/flow-evm-gateway/storage/mocks"
+	"github.com/onflow/flow-evm-gateway/storage/pebble"
+)
+
+func TestGetTransactionStatus(t *testing.T) {
+	store, err := pebble.New(t.TempDir(), zerolog.Nop())
+	require.NoError(t, err)
+	submissions := pebble.NewSubmissions(store)
+
+	pool := requester.NewTxPool(
+		nil,
+		nil,
+		nil,
+		submissions,
+		nil,
+		&config.Config{TxQueueLimit: 1, TxQueueTTL: time.Minute},
+		metrics.NopCollector,
+		zerolog.Nop(),
+	)
+	receipts := mocks.NewReceiptIndexer(t)
+	flowAPI := NewFlowAPI(zerolog.Nop(), nil, pool, nil, receipts, nil, metrics.NopCollector)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := gethTypes.LatestSignerForChainID(big.NewInt(646))
+	newTx := func(nonce uint64) *gethTypes.Transaction {
+		tx, err := gethTypes.SignNewTx(key, signer, &gethTypes.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+		receipts.On("GetByTransactionID", tx.Hash()).Return(nil, errs.ErrEntityNotFound).Maybe()
+		return tx
+	}
+
+	t.Run("unknown transaction", func(t *testing.T) {
+		status, err := flowAPI.GetTransactionStatus(context.Background(), newTx(0).Hash())
+		require.NoError(t, err)
+		require.Nil(t, status)
+	})
+
+	t.Run("received transaction", func(t *testing.T) {
+		tx := newTx(1)
+		require.NoError(t, pool.Queue(crypto.PubkeyToAddress(key.PublicKey), tx))
+
+		status, err := flowAPI.GetTransactionStatus(context.Background(), tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, &TransactionStatus{Status: TransactionReceived}, status)
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+		tx := newTx(2)
+		require.NoError(t, submissions.Store(&models.Submission{
+			Hash:        tx.Hash(),
+			FlowID:      flow.Identifier{0x1},
+			Status:      models.SubmissionFailed,
+			Error:       "nonce too low",
+			SubmittedAt: 100,
+		}))
+
+		status, err := flowAPI.GetTransactionStatus(context.Background(), tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, string(models.SubmissionFailed), status.Status)
+		require.Equal(t, flow.Identifier{0x1}.String(), status.FlowTransactionID)
+		require.Equal(t, hexutil.Uint64(100), *status.SubmittedAt)
+		require.Equal(t, "nonce too low", status.Error)
+	})
+
+	t.Run("indexed transaction", func(t *testing.T) {
+		hash := common.HexToHash("0x1")
+		require.NoError(t, submissions.Store(&models.Submission{
+			Hash:   hash,
+			FlowID: flow.Identifier{0x2},
+			Status: models.SubmissionSealed,
+		}))
+		receipts.
+			On("GetByTransactionID", hash).
+			Return(&models.Receipt{Status: gethTypes.ReceiptStatusSuccessful, BlockNumber: big.NewInt(5)}, nil)
+
+		status, err := flowAPI.GetTransactionStatus(context.Background(), hash)
+		require.NoError(t, err)
+		require.Equal(t, &TransactionStatus{
+			Status:            TransactionIndexed,
+			FlowTransactionID: flow.Identifier{0x2}.String(),
+			BlockNumber:       (*hexutil.Big)(big.NewInt(5)),
+		}, status)
+	})
+}
@@ -95,6 +95,44 @@ type AccessListResult struct {
 	GasUsed    hexutil.Uint64    `json:"gasUsed"`
 }
 
+// SimulatePayload is the payload of the `eth_simulateV1` RPC call.
+type SimulatePayload struct {
+	BlockStateCalls        []SimulateBlockArgs `json:"blockStateCalls"`
+	TraceTransfers         bool                `json:"traceTransfers"`
+	Validation             bool                `json:"validation"`
+	ReturnFullTransactions bool                `json:"returnFullTransactions"`
+}
+
+// SimulateBlockArgs contains the calls of a simulated block,
+// and the overrides applied before the calls are executed.
+type SimulateBlockArgs struct {
+	BlockOverrides *models.BlockOverrides `json:"blockOverrides"`
+	StateOverrides *models.StateOverride  `json:"stateOverrides"`
+	Calls          []TransactionArgs      `json:"calls"`
+}
+
+// SimulateBlockResult is a simulated block, with the results of its calls.
+type SimulateBlockResult struct {
+	*Block
+	Calls []SimulateCallResult `json:"calls"`
+}
+
+// SimulateCallResult is the result of a simulated call.
+type SimulateCallResult struct {
+	ReturnData hexutil.Bytes      `json:"returnData"`
+	Logs       []*types.Log       `json:"logs"`
+	GasUsed    hexutil.Uint64     `json:"gasUsed"`
+	Status     hexutil.Uint64     `json:"status"`
+	Error      *SimulateCallError `json:"error,omitempty"`
+}
+
+// SimulateCallError is the error of a failed simulated call.
+type SimulateCallError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
 type FeeHistoryResult struct {
 	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
 	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
@@ -238,35 +276,6 @@ type SignTransactionResult struct {
 	Tx  *types.Transaction `json:"tx"`
 }
 
-// OverrideAccount indicates the overriding fields of account during the execution
-// of a message call.
-// Note, state and stateDiff can't be specified at the same time. If state is
-// set, message execution will only use the data in the given state. Otherwise
-// if statDiff is set, all diff will be applied first and then execute the call
-// message.
-type OverrideAccount struct {
-	Nonce     *hexutil.Uint64              `json:"nonce"`
-	Code      *hexutil.Bytes               `json:"code"`
-	Balance   **hexutil.Big                `json:"balance"`
-	State     *map[common.Hash]common.Hash `json:"state"`
-	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
-}
-
-// StateOverride is the collection of overridden accounts.
-type StateOverride map[common.Address]OverrideAccount
-
-// BlockOverrides is a set of header fields to override.
-type BlockOverrides struct {
-	Number      *hexutil.Big
-	Difficulty  *hexutil.Big
-	Time        *hexutil.Uint64
-	GasLimit    *hexutil.Uint64
-	Coinbase    *common.Address
-	Random      *common.Hash
-	BaseFee     *hexutil.Big
-	BlobBaseFee *hexutil.Big
-}
-
 type Block struct {
 	Number           hexutil.Uint64   `json:"number"`
 	Hash             common.Hash      `json:"hash"`
@@ -1,14 +1,38 @@
 package api
 
 import (
+	"fmt"
+	"math/big"
+	"strconv"
+
 	"github.com/onflow/go-ethereum/common"
 	"github.com/onflow/go-ethereum/common/hexutil"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	"github.com/onflow/flow-evm-gateway/services/requester"
 )
 
-type TxPool struct{}
+// TxPool exposes the transactions submitted by the gateway, which are not yet sealed,
+// as the pending transactions, and the transactions with a nonce ahead of the next
+// nonce of the sender, held until the nonce gap is filled, as the queued transactions.
+type TxPool struct {
+	logger    zerolog.Logger
+	pool      *requester.TxPool
+	networkID *big.Int
+}
 
-func NewTxPoolAPI() *TxPool {
-	return &TxPool{}
+func NewTxPoolAPI(
+	logger zerolog.Logger,
+	pool *requester.TxPool,
+	networkID *big.Int,
+) *TxPool {
+	return &TxPool{
+		logger:    logger,
+		pool:      pool,
+		networkID: networkID,
+	}
 }
 
 type content struct {
@@ -16,28 +40,88 @@ type content struct {
 	Queued  any `json:"queued"`
 }
 
-func emptyPool() content {
+// Content returns the transactions in the pool, grouped by the sender and nonce.
+func (s *TxPool) Content() content {
 	return content{
-		Pending: struct{}{},
-		Queued:  struct{}{},
+		Pending: s.content(s.pool.Pending()),
+		Queued:  s.content(s.pool.Queued()),
 	}
 }
 
-func (s *TxPool) Content() content {
-	return emptyPool()
-}
-
+// ContentFrom returns the transactions in the pool sent by the address, grouped by nonce.
 func (s *TxPool) ContentFrom(addr common.Address) content {
-	return emptyPool()
+	return content{
+		Pending: s.transactions(s.pool.Pending()[addr]),
+		Queued:  s.transactions(s.pool.Queued()[addr]),
+	}
 }
 
+// Status returns the number of the pending and queued transactions in the pool.
 func (s *TxPool) Status() map[string]hexutil.Uint {
 	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(0),
-		"queued":  hexutil.Uint(0),
+		"pending": count(s.pool.Pending()),
+		"queued":  count(s.pool.Queued()),
 	}
 }
 
+// Inspect returns a textual summary of the transactions in the pool,
+// grouped by the sender and nonce.
 func (s *TxPool) Inspect() content {
-	return emptyPool()
+	return content{
+		Pending: inspect(s.pool.Pending()),
+		Queued:  inspect(s.pool.Queued()),
+	}
+}
+
+func (s *TxPool) content(senders map[common.Address][]*gethTypes.Transaction) map[common.Address]map[string]*Transaction {
+	result := make(map[common.Address]map[string]*Transaction, len(senders))
+	for from, txs := range senders {
+		result[from] = s.transactions(txs)
+	}
+	return result
+}
+
+// transactions converts the transactions of a sender to the RPC transactions, keyed by nonce.
+func (s *TxPool) transactions(txs []*gethTypes.Transaction) map[string]*Transaction {
+	result := make(map[string]*Transaction, len(txs))
+	for _, tx := range txs {
+		rpcTx, err := NewTransaction(models.TransactionCall{Transaction: tx}, s.networkID)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("evm-id", tx.Hash().Hex()).Msg("failed to convert pool transaction")
+			continue
+		}
+		result[strconv.FormatUint(tx.Nonce(), 10)] = rpcTx
+	}
+
+	return result
+}
+
+func count(senders map[common.Address][]*gethTypes.Transaction) hexutil.Uint {
+	total := 0
+	for _, txs := range senders {
+		total += len(txs)
+	}
+	return hexutil.Uint(total)
+}
+
+func inspect(senders map[common.Address][]*gethTypes.Transaction) map[common.Address]map[string]string {
+	result := make(map[common.Address]map[string]string, len(senders))
+	for from, txs := range senders {
+		summaries := make(map[string]string, len(txs))
+		for _, tx := range txs {
+			to := "contract creation"
+			if tx.To() != nil {
+				to = tx.To().Hex()
+			}
+			summaries[strconv.FormatUint(tx.Nonce(), 10)] = fmt.Sprintf(
+				"%s: %v wei + %v gas × %v wei",
+				to,
+				tx.Value(),
+				tx.Gas(),
+				tx.GasPrice(),
+			)
+		}
+		result[from] = summaries
+	}
+	return result
 }
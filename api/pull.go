@@ -17,6 +17,7 @@ import (
 	"github.com/onflow/flow-evm-gateway/config"
 	errs "github.com/onflow/flow-evm-gateway/models/errors"
 	"github.com/onflow/flow-evm-gateway/services/logs"
+	"github.com/onflow/flow-evm-gateway/services/requester"
 	"github.com/onflow/flow-evm-gateway/storage"
 )
 
@@ -129,6 +130,7 @@ func newLogsFilter(
 type PullAPI struct {
 	logger       zerolog.Logger
 	config       *config.Config
+	evm          requester.Requester
 	blocks       storage.BlockIndexer
 	transactions storage.TransactionIndexer
 	receipts     storage.ReceiptIndexer
@@ -140,6 +142,7 @@ type PullAPI struct {
 func NewPullAPI(
 	logger zerolog.Logger,
 	config *config.Config,
+	evm requester.Requester,
 	blocks storage.BlockIndexer,
 	transactions storage.TransactionIndexer,
 	receipts storage.ReceiptIndexer,
@@ -148,6 +151,7 @@ func NewPullAPI(
 	api := &PullAPI{
 		logger:       logger,
 		config:       config,
+		evm:          evm,
 		blocks:       blocks,
 		transactions: transactions,
 		receipts:     receipts,
@@ -256,8 +260,11 @@ func (api *PullAPI) NewFilter(ctx context.Context, criteria filters.FilterCriter
 	}
 
 	// if from block is actually set we use it as from value otherwise use latest
-	if criteria.FromBlock != nil && criteria.FromBlock.Int64() >= 0 {
-		latest = criteria.FromBlock.Uint64()
+	if criteria.FromBlock != nil {
+		latest, err = resolveBlockNumber(ctx, rpc.BlockNumber(criteria.FromBlock.Int64()), api.blocks, api.evm)
+		if err != nil {
+			return "", err
+		}
 		// if to block is set and doesn't have a special value
 		// (e.g. latest which is less than 0) make sure it's not less than from block
 		if criteria.ToBlock != nil &&
@@ -316,7 +323,7 @@ func (api *PullAPI) GetFilterLogs(
 		)
 	}
 
-	current, err := api.blocks.LatestEVMHeight()
+	current, err := api.logsFilterHeight(ctx, logsFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -369,6 +376,10 @@ func (api *PullAPI) GetFilterChanges(ctx context.Context, id rpc.ID) (any, error
 	case *transactionsFilter:
 		result, err = api.getTransactions(current, filterType)
 	case *logsFilter:
+		current, err = api.logsFilterHeight(ctx, filterType)
+		if err != nil {
+			return nil, err
+		}
 		result, err = api.getLogs(current, filterType)
 	default:
 		return nil, fmt.Errorf("non-supported filter type: %T", filterType)
@@ -481,6 +492,19 @@ func (api *PullAPI) getTransactions(latestHeight uint64, filter *transactionsFil
 	return hashes, nil
 }
 
+// logsFilterHeight returns the height up to which the logs filter is matched, which is
+// the latest indexed height, unless the filter is limited to the finalized blocks.
+func (api *PullAPI) logsFilterHeight(ctx context.Context, filter *logsFilter) (uint64, error) {
+	if to := filter.criteria.ToBlock; to != nil {
+		number := rpc.BlockNumber(to.Int64())
+		if number == rpc.FinalizedBlockNumber || number == rpc.SafeBlockNumber {
+			return api.evm.GetSealedEVMHeight(ctx)
+		}
+	}
+
+	return api.blocks.LatestEVMHeight()
+}
+
 func (api *PullAPI) getLogs(latestHeight uint64, filter *logsFilter) (any, error) {
 	nextHeight := filter.next()
 	criteria := logs.FilterCriteria{
@@ -11,6 +11,7 @@ import (
 	"github.com/onflow/flow-go-sdk/access/grpc"
 	"github.com/onflow/flow-go-sdk/crypto"
 	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/rpc"
 	"github.com/rs/zerolog"
 	"github.com/sethvargo/go-limiter/memorystore"
 	grpcOpts "google.golang.org/grpc"
@@ -34,6 +35,8 @@ type Storages struct {
 	Receipts     storage.ReceiptIndexer
 	Accounts     storage.AccountIndexer
 	Traces       storage.TraceIndexer
+	Registers    storage.RegisterIndexer
+	Submissions  storage.SubmissionIndexer
 }
 
 type Publishers struct {
@@ -50,6 +53,7 @@ type Bootstrap struct {
 	publishers *Publishers
 	collector  metrics.Collector
 	server     *api.Server
+	admin      *api.Server
 	metrics    *metrics.Server
 	events     *ingestion.Engine
 	traces     *traces.Engine
@@ -124,14 +128,26 @@ func (b *Bootstrap) StartEventIngestion(ctx context.Context) error {
 		b.logger,
 	)
 
+	// create register subscriber, if the state is indexed
+	var registerSubscriber ingestion.RegisterSubscriber
+	if b.config.IndexState || b.config.ArchiveMode {
+		registerSubscriber = ingestion.NewRPCRegisterSubscriber(
+			b.client,
+			b.config.FlowNetworkID,
+			b.logger,
+		)
+	}
+
 	// initialize event ingestion engine
 	b.events = ingestion.NewEventIngestionEngine(
 		subscriber,
+		registerSubscriber,
 		b.storages.Storage,
 		b.storages.Blocks,
 		b.storages.Receipts,
 		b.storages.Transactions,
 		b.storages.Accounts,
+		b.storages.Registers,
 		b.publishers.Block,
 		b.publishers.Logs,
 		b.logger,
@@ -235,27 +251,86 @@ func (b *Bootstrap) StartAPIServer(ctx context.Context) error {
 			b.config.COACloudKMSKeys,
 			b.logger,
 		)
+	case len(b.config.COAKeystoreFiles) > 0:
+		signer, err = requester.NewKeystoreSigner(
+			b.config.COAKeystoreFiles,
+			b.config.COAKeystorePassphrase,
+			crypto.SHA3_256,
+		)
+	case b.config.COARemoteSignerURL != "":
+		tlsConfig, tlsErr := requester.NewRemoteSignerTLSConfig(
+			b.config.COARemoteSignerTLSCert,
+			b.config.COARemoteSignerTLSKey,
+			b.config.COARemoteSignerTLSCA,
+		)
+		if tlsErr != nil {
+			return fmt.Errorf("failed to create a COA signer: %w", tlsErr)
+		}
+		signer, err = requester.NewRemoteKeyRotationSigner(
+			ctx,
+			requester.RemoteSignerOptions{
+				URL:     b.config.COARemoteSignerURL,
+				KeyIDs:  b.config.COARemoteSignerKeyIDs,
+				TLS:     tlsConfig,
+				Timeout: b.config.COARemoteSignerTimeout,
+				Retries: b.config.COARemoteSignerRetries,
+			},
+			b.logger,
+		)
 	default:
-		return fmt.Errorf("must provide either single COA / keylist of COA keys / COA cloud KMS keys")
+		return fmt.Errorf("must provide either single COA / keylist of COA keys / COA cloud KMS keys / COA keystore files / COA remote signer")
 	}
 	if err != nil {
 		return fmt.Errorf("failed to create a COA signer: %w", err)
 	}
 
+	keys, err := requester.NewKeyPool(ctx, b.client, b.config, signer, b.collector, b.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create the COA key pool: %w", err)
+	}
+
+	gasPrice, err := requester.NewGasPriceOracle(b.config, b.storages.Blocks, b.storages.Receipts)
+	if err != nil {
+		return fmt.Errorf("failed to create gas price oracle: %w", err)
+	}
+
 	// create transaction pool
 	txPool := requester.NewTxPool(
 		b.client,
 		b.publishers.Transaction,
+		gasPrice,
+		b.storages.Submissions,
+		keys,
+		b.config,
+		b.collector,
 		b.logger,
 	)
 
+	// track again the submissions not yet sealed before the restart
+	if err := txPool.Resume(); err != nil {
+		return fmt.Errorf("failed to resume transaction submissions: %w", err)
+	}
+
+	// detect the keys used by another gateway instance sharing the COA account
+	if b.config.KeyOverlapCheckPeriod > 0 {
+		b.logger.Info().
+			Dur("period", b.config.KeyOverlapCheckPeriod).
+			Msg("checking the COA keys are not used by another gateway instance")
+		if err := keys.CheckOverlap(ctx, b.config.KeyOverlapCheckPeriod); err != nil {
+			return fmt.Errorf("failed to check the COA keys overlap: %w", err)
+		}
+	}
+
 	evm, err := requester.NewEVM(
+		ctx,
 		b.client,
 		b.config,
-		signer,
+		keys,
 		b.logger,
 		b.storages.Blocks,
+		b.storages.Registers,
 		txPool,
+		gasPrice,
 		b.collector,
 	)
 	if err != nil {
@@ -282,6 +357,7 @@ func (b *Bootstrap) StartAPIServer(ctx context.Context) error {
 		b.storages.Transactions,
 		b.storages.Receipts,
 		b.storages.Accounts,
+		gasPrice,
 		ratelimiter,
 		b.collector,
 	)
@@ -303,6 +379,7 @@ func (b *Bootstrap) StartAPIServer(ctx context.Context) error {
 	pullAPI := api.NewPullAPI(
 		b.logger,
 		b.config,
+		evm,
 		b.storages.Blocks,
 		b.storages.Transactions,
 		b.storages.Receipts,
@@ -319,12 +396,25 @@ func (b *Bootstrap) StartAPIServer(ctx context.Context) error {
 		walletAPI = api.NewWalletAPI(b.config, blockchainAPI)
 	}
 
+	txPoolAPI := api.NewTxPoolAPI(b.logger, txPool, b.config.EVMNetworkID)
+	flowAPI := api.NewFlowAPI(
+		b.logger,
+		evm,
+		txPool,
+		b.storages.Blocks,
+		b.storages.Receipts,
+		ratelimiter,
+		b.collector,
+	)
+
 	supportedAPIs := api.SupportedAPIs(
 		blockchainAPI,
 		streamAPI,
 		pullAPI,
 		debugAPI,
 		walletAPI,
+		txPoolAPI,
+		flowAPI,
 		b.config,
 	)
 
@@ -347,6 +437,30 @@ func (b *Bootstrap) StartAPIServer(ctx context.Context) error {
 	}
 
 	b.logger.Info().Msgf("API server started: %s", b.server.ListenAddr())
+
+	// the admin api is served separately, so it's not exposed together with the public apis
+	if b.config.AdminAPIEnabled {
+		b.admin = api.NewServer(b.logger, b.collector, b.config)
+
+		adminAPIs := []rpc.API{{
+			Namespace: "admin",
+			Service:   api.NewAdminAPI(b.logger, keys),
+		}}
+		if err := b.admin.EnableRPC(adminAPIs); err != nil {
+			return err
+		}
+
+		if err := b.admin.SetListenAddr(b.config.AdminAPIHost, b.config.AdminAPIPort); err != nil {
+			return err
+		}
+
+		if err := b.admin.Start(); err != nil {
+			return err
+		}
+
+		b.logger.Info().Msgf("Admin API server started: %s", b.admin.ListenAddr())
+	}
+
 	return nil
 }
 
@@ -356,6 +470,11 @@ func (b *Bootstrap) StopAPIServer() {
 	}
 	b.logger.Warn().Msg("shutting down API server")
 	b.server.Stop()
+
+	if b.admin != nil {
+		b.logger.Warn().Msg("shutting down admin API server")
+		b.admin.Stop()
+	}
 }
 
 func (b *Bootstrap) StartMetricsServer(_ context.Context) error {
@@ -516,14 +635,24 @@ func setupStorage(
 		logger.Info().Msgf("database initialized with cadence height: %d", cadenceHeight)
 	}
 
-	return &Storages{
+	storages := &Storages{
 		Storage:      store,
 		Blocks:       blocks,
 		Transactions: pebble.NewTransactions(store),
 		Receipts:     pebble.NewReceipts(store),
 		Accounts:     pebble.NewAccounts(store),
 		Traces:       pebble.NewTraces(store),
-	}, nil
+		Submissions:  pebble.NewSubmissions(store),
+	}
+
+	switch {
+	case config.ArchiveMode:
+		storages.Registers = pebble.NewArchiveLedger(store)
+	case config.IndexState:
+		storages.Registers = pebble.NewLedger(store)
+	}
+
+	return storages, nil
 }
 
 // Run will run complete bootstrap of the EVM gateway with all the engines.
@@ -0,0 +1,92 @@
+package keystore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/go-ethereum/accounts/keystore"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-evm-gateway/services/requester"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Encrypts a COA private key read from stdin into a keystore file",
+	RunE: func(*cobra.Command, []string) error {
+		if out == "" {
+			return fmt.Errorf("path of the keystore file must be provided with the out flag")
+		}
+
+		sigAlgo := crypto.StringToSignatureAlgorithm(keyAlg)
+		if sigAlgo == crypto.UnknownSignatureAlgorithm {
+			return fmt.Errorf("invalid signature algorithm: %s", keyAlg)
+		}
+
+		// the key is read from stdin, so it's not exposed in the process arguments
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read the COA private key from stdin: %w", err)
+		}
+		key, err := crypto.DecodePrivateKeyHex(sigAlgo, strings.TrimSpace(line))
+		if err != nil {
+			return fmt.Errorf("invalid COA private key: %w", err)
+		}
+
+		passphrase, err := requester.ReadKeystorePassphrase(passphraseEnv, passphraseFD)
+		if err != nil {
+			return fmt.Errorf("invalid keystore passphrase: %w", err)
+		}
+		if passphrase == "" {
+			return fmt.Errorf("keystore passphrase must not be empty")
+		}
+
+		scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+		if light {
+			scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+		}
+		keyJSON, err := requester.EncryptKeystore(key, passphrase, scryptN, scryptP)
+		if err != nil {
+			return err
+		}
+
+		// the file is only readable by the owner, and an existing file is never overwritten
+		file, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create the keystore file: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := file.Write(keyJSON); err != nil {
+			return fmt.Errorf("failed to write the keystore file: %w", err)
+		}
+
+		log.Info().
+			Str("file", out).
+			Str("public-key", key.PublicKey().String()).
+			Msg("keystore file created")
+		return nil
+	},
+}
+
+var (
+	keyAlg,
+	out,
+	passphraseEnv string
+
+	passphraseFD int
+
+	light bool
+)
+
+func init() {
+	Cmd.Flags().StringVar(&keyAlg, "key-alg", "ECDSA_P256", "Private key algorithm of the COA private key (ECDSA_P256 / ECDSA_secp256k1 / BLS_BLS12_381)")
+	Cmd.Flags().StringVar(&out, "out", "", "Path of the keystore file to create")
+	Cmd.Flags().StringVar(&passphraseEnv, "password-env", "", "Name of the environment variable holding the passphrase to encrypt the keystore file with")
+	Cmd.Flags().IntVar(&passphraseFD, "password-fd", -1, "File descriptor to read the passphrase to encrypt the keystore file with, this is exclusive with password-env flag")
+	Cmd.Flags().BoolVar(&light, "light-scrypt", false, "Use the light scrypt parameters, which decrypt faster but are less secure")
+}
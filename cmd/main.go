@@ -3,7 +3,9 @@ package main
 import (
 	"os"
 
+	"github.com/onflow/flow-evm-gateway/cmd/keystore"
 	"github.com/onflow/flow-evm-gateway/cmd/run"
+	"github.com/onflow/flow-evm-gateway/cmd/signer"
 	"github.com/onflow/flow-evm-gateway/cmd/version"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -24,6 +26,8 @@ func Execute() {
 func main() {
 	rootCmd.AddCommand(version.Cmd)
 	rootCmd.AddCommand(run.Cmd)
+	rootCmd.AddCommand(keystore.Cmd)
+	rootCmd.AddCommand(signer.Cmd)
 
 	Execute()
 }
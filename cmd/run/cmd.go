@@ -7,12 +7,14 @@ import (
 	"math/big"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/onflow/flow-evm-gateway/bootstrap"
 	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/services/requester"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
 	flowGoKMS "github.com/onflow/flow-go-sdk/crypto/cloudkms"
@@ -76,6 +78,22 @@ func parseConfigFromFlags() error {
 		return fmt.Errorf("invalid gas price")
 	}
 
+	if gasFloor != "" {
+		g, ok := new(big.Int).SetString(gasFloor, 10)
+		if !ok {
+			return fmt.Errorf("invalid gas price floor: %s", gasFloor)
+		}
+		cfg.GasPriceFloor = g
+	}
+
+	if gasCeiling != "" {
+		g, ok := new(big.Int).SetString(gasCeiling, 10)
+		if !ok {
+			return fmt.Errorf("invalid gas price ceiling: %s", gasCeiling)
+		}
+		cfg.GasPriceCeiling = g
+	}
+
 	cfg.COAAddress = flow.HexToAddress(coa)
 	if cfg.COAAddress == flow.EmptyAddress {
 		return fmt.Errorf("COA address value is the empty address")
@@ -136,12 +154,67 @@ func parseConfigFromFlags() error {
 				KeyVersion: keyParts[1],
 			}
 		}
+	} else if keystoreFiles != "" {
+		cfg.COAKeystoreFiles = strings.Split(keystoreFiles, ",")
+
+		passphrase, err := requester.ReadKeystorePassphrase(keystorePassphraseEnv, keystorePassphraseFD)
+		if err != nil {
+			return fmt.Errorf("invalid COA keystore passphrase: %w", err)
+		}
+		cfg.COAKeystorePassphrase = passphrase
+	} else if remoteSignerURL != "" {
+		if remoteSignerKeys == "" {
+			return fmt.Errorf("using coa-remote-signer-url requires also coa-remote-signer-keys")
+		}
+		if (cfg.COARemoteSignerTLSCert == "") != (cfg.COARemoteSignerTLSKey == "") {
+			return fmt.Errorf("coa-remote-signer-tls-cert and coa-remote-signer-tls-key must be provided together")
+		}
+		cfg.COARemoteSignerURL = remoteSignerURL
+		cfg.COARemoteSignerKeyIDs = strings.Split(remoteSignerKeys, ",")
+
+		timeout, err := time.ParseDuration(remoteSignerTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid unit %s for remote signer timeout: %w", remoteSignerTimeout, err)
+		}
+		cfg.COARemoteSignerTimeout = timeout
 	} else {
 		return fmt.Errorf(
-			"must either provide coa-key / coa-key-path / coa-cloud-kms-keys",
+			"must either provide coa-key / coa-key-path / coa-cloud-kms-keys / coa-keystore / coa-remote-signer-url",
 		)
 	}
 
+	if keyIndices != "" && keyPartition != "" {
+		return fmt.Errorf("coa-key-indices and coa-key-partition can't be used together")
+	}
+
+	if keyIndices != "" {
+		for _, index := range strings.Split(keyIndices, ",") {
+			i, err := strconv.ParseUint(strings.TrimSpace(index), 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid COA key index: %s", index)
+			}
+			cfg.COAKeyIndices = append(cfg.COAKeyIndices, uint32(i))
+		}
+	}
+
+	if keyPartition != "" {
+		// partition has the form "{partition}/{count}"
+		parts := strings.Split(keyPartition, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("wrong format for COA key partition: %s", keyPartition)
+		}
+		partition, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid COA key partition: %s", keyPartition)
+		}
+		count, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil || count == 0 || partition >= count {
+			return fmt.Errorf("invalid COA key partition: %s, the partition must be less than the count", keyPartition)
+		}
+		cfg.COAKeyPartition = uint32(partition)
+		cfg.COAKeyPartitionCount = uint32(count)
+	}
+
 	switch flowNetwork {
 	case "flow-previewnet":
 		cfg.FlowNetworkID = flowGo.Previewnet
@@ -186,6 +259,30 @@ func parseConfigFromFlags() error {
 
 	cfg.StreamTimeout = time.Second * time.Duration(streamTimeout)
 
+	batchInterval, err := time.ParseDuration(txBatchInterval)
+	if err != nil {
+		return fmt.Errorf("invalid unit %s for transaction batch interval: %w", txBatchInterval, err)
+	}
+	cfg.TxBatchInterval = batchInterval
+
+	queueTTL, err := time.ParseDuration(txQueueTTL)
+	if err != nil {
+		return fmt.Errorf("invalid unit %s for transaction queue TTL: %w", txQueueTTL, err)
+	}
+	cfg.TxQueueTTL = queueTTL
+
+	keyHealthInterval, err := time.ParseDuration(keyHealthCheckInterval)
+	if err != nil {
+		return fmt.Errorf("invalid unit %s for key health check interval: %w", keyHealthCheckInterval, err)
+	}
+	cfg.KeyHealthCheckInterval = keyHealthInterval
+
+	overlapPeriod, err := time.ParseDuration(keyOverlapCheckPeriod)
+	if err != nil {
+		return fmt.Errorf("invalid unit %s for key overlap check period: %w", keyOverlapCheckPeriod, err)
+	}
+	cfg.KeyOverlapCheckPeriod = overlapPeriod
+
 	exp, err := time.ParseDuration(filterExpiry)
 	if err != nil {
 		return fmt.Errorf("invalid unit %s for filter expiry: %w", filterExpiry, err)
@@ -225,6 +322,8 @@ var cfg = &config.Config{}
 var (
 	coinbase,
 	gas,
+	gasFloor,
+	gasCeiling,
 	coa,
 	key,
 	keyAlg,
@@ -233,14 +332,26 @@ var (
 	logLevel,
 	logWriter,
 	filterExpiry,
+	txBatchInterval,
+	txQueueTTL,
+	keyHealthCheckInterval,
+	keyOverlapCheckPeriod,
+	keyIndices,
+	keyPartition,
 	accessSporkHosts,
 	cloudKMSKeys,
 	cloudKMSProjectID,
 	cloudKMSLocationID,
 	cloudKMSKeyRingID,
+	keystoreFiles,
+	keystorePassphraseEnv,
+	remoteSignerURL,
+	remoteSignerKeys,
+	remoteSignerTimeout,
 	walletKey string
 
-	streamTimeout int
+	streamTimeout,
+	keystorePassphraseFD int
 
 	initHeight,
 	forceStartHeight uint64
@@ -258,10 +369,42 @@ func init() {
 	Cmd.Flags().StringVar(&coinbase, "coinbase", "", "Coinbase address to use for fee collection")
 	Cmd.Flags().Uint64Var(&initHeight, "init-cadence-height", 0, "Define the Cadence block height at which to start the indexing, if starting on a new network this flag should not be used.")
 	Cmd.Flags().StringVar(&gas, "gas-price", "1", "Static gas price used for EVM transactions")
+	Cmd.Flags().StringVar(&cfg.GasPriceStrategy, "gas-price-strategy", "fixed", "Gas price oracle strategy (fixed, percentile), fixed quotes the gas price, percentile quotes a percentile of the tips paid in the recent blocks")
+	Cmd.Flags().StringVar(&gasFloor, "gas-price-floor", "", "Lowest gas price quoted and accepted by the percentile strategy, defaults to the gas price")
+	Cmd.Flags().StringVar(&gasCeiling, "gas-price-ceiling", "", "Highest gas price quoted and accepted by the percentile strategy, empty means no ceiling")
+	Cmd.Flags().Uint64Var(&cfg.GasPriceBlocks, "gas-price-blocks", 20, "Number of recent blocks the percentile strategy samples the tips from")
+	Cmd.Flags().Float64Var(&cfg.GasPricePercentile, "gas-price-percentile", 60, "Percentile of the sampled tips quoted by the percentile strategy")
+	Cmd.Flags().BoolVar(&cfg.AsyncTxSubmission, "async-tx-submission", false, "Return the transaction hash as soon as the Flow transaction is accepted by the Access Node, and await its sealing in the background")
+	Cmd.Flags().Uint64Var(&cfg.TxBatchSize, "tx-batch-size", 0, "Maximum number of EVM transactions submitted in a single Flow transaction, lower than 2 disables the batching")
+	Cmd.Flags().StringVar(&txBatchInterval, "tx-batch-interval", "500ms", "Longest time the EVM transactions are collected into a batch before it's submitted")
+	Cmd.Flags().Uint64Var(&cfg.TxQueueLimit, "tx-queue-limit", 0, "Maximum number of transactions per sender with a nonce ahead of the next nonce, queued until the nonce gap is filled, 0 disables the queueing")
+	Cmd.Flags().StringVar(&txQueueTTL, "tx-queue-ttl", "5m", "Longest time a transaction is queued for, waiting for the nonce gap to be filled")
+	Cmd.Flags().Uint64Var(&cfg.TxResubmitLimit, "tx-resubmit-limit", 3, "Maximum number of times an expired or sequence-conflicted Flow transaction is rebuilt and resubmitted, 0 disables the resubmission")
+	Cmd.Flags().StringVar(&keyHealthCheckInterval, "key-health-interval", "1m", "Interval the COA keys are checked against the account, quarantining the unhealthy keys, 0 disables the periodic checks")
+	Cmd.Flags().BoolVar(&cfg.AdminAPIEnabled, "admin-api", false, "Enable the admin APIs, which expose the status of the COA keys, on a separate server. WARNING: This should only be exposed to the operators")
+	Cmd.Flags().StringVar(&cfg.AdminAPIHost, "admin-api-host", "localhost", "Host for the admin API server")
+	Cmd.Flags().IntVar(&cfg.AdminAPIPort, "admin-api-port", 8555, "Port for the admin API server")
+	Cmd.Flags().Uint64Var(&cfg.RPCGasCap, "rpc-gas-cap", 50_000_000, "Global gas cap for eth_call and eth_estimateGas executions, 0 means no cap")
+	Cmd.Flags().BoolVar(&cfg.LocalExecution, "local-execution", false, "Execute calls and state reads in-process over the EVM state registers fetched from the Access Node, falling back to Cadence scripts if the local execution fails")
+	Cmd.Flags().BoolVar(&cfg.IndexState, "index-state", false, "Index the EVM state register updates from the Access Node execution data stream, so the state at the latest block is read from the local database. The registers not updated since the indexing started are still fetched from the Access Node, unless indexing from the start of the EVM history")
+	Cmd.Flags().BoolVar(&cfg.ArchiveMode, "archive-mode", false, "Keep the indexed EVM state registers at every height, so the state at any indexed block is read from the local database, implies --index-state")
 	Cmd.Flags().StringVar(&coa, "coa-address", "", "Flow address that holds COA account used for submitting transactions")
 	Cmd.Flags().StringVar(&key, "coa-key", "", "Private key value for the COA address used for submitting transactions")
 	Cmd.Flags().StringVar(&keyAlg, "coa-key-alg", "ECDSA_P256", "Private key algorithm for the COA private key, only effective if coa-key/coa-key-file is present. Available values (ECDSA_P256 / ECDSA_secp256k1 / BLS_BLS12_381), defaults to ECDSA_P256.")
 	Cmd.Flags().StringVar(&keysPath, "coa-key-file", "", "File path that contains JSON array of COA keys used in key-rotation mechanism, this is exclusive with coa-key flag.")
+	Cmd.Flags().StringVar(&keyIndices, "coa-key-indices", "", `Indexes of the COA keys used by the gateway instance as a comma separated list (e.g. "0,1,2"), so the instances sharing the COA account use disjoint keys, this is exclusive with coa-key-partition flag.`)
+	Cmd.Flags().StringVar(&keyPartition, "coa-key-partition", "", `Partition of the COA keys used by the gateway instance in the form {partition}/{count} (e.g. "0/3"), the instance uses the keys with the index modulo count equal to the partition.`)
+	Cmd.Flags().StringVar(&keyOverlapCheckPeriod, "coa-key-overlap-check", "0", "Period the unused COA keys are observed at startup, failing the startup if their sequence numbers advance, as the keys are used by another gateway instance, disabled by default with 0")
+	Cmd.Flags().StringVar(&keystoreFiles, "coa-keystore", "", `Paths of the encrypted keystore files of the COA keys as a comma separated list (e.g. "key-0.json,key-1.json"), multiple keys are used in key-rotation mechanism, this is exclusive with coa-key flag.`)
+	Cmd.Flags().StringVar(&keystorePassphraseEnv, "coa-keystore-password-env", "", "Name of the environment variable holding the passphrase of the COA keystore files, the variable is unset once read")
+	Cmd.Flags().IntVar(&keystorePassphraseFD, "coa-keystore-password-fd", -1, "File descriptor to read the passphrase of the COA keystore files from, this is exclusive with coa-keystore-password-env flag")
+	Cmd.Flags().StringVar(&remoteSignerURL, "coa-remote-signer-url", "", "URL of the remote signer holding the COA keys, e.g. 'https://signer.internal:9443', this is exclusive with coa-key flag.")
+	Cmd.Flags().StringVar(&remoteSignerKeys, "coa-remote-signer-keys", "", `IDs of the COA keys of the remote signer as a comma separated list (e.g. "gw-key-0,gw-key-1"), multiple keys are used in key-rotation mechanism`)
+	Cmd.Flags().StringVar(&cfg.COARemoteSignerTLSCert, "coa-remote-signer-tls-cert", "", "Client certificate file authenticating the gateway to the remote signer with mTLS")
+	Cmd.Flags().StringVar(&cfg.COARemoteSignerTLSKey, "coa-remote-signer-tls-key", "", "Client key file authenticating the gateway to the remote signer with mTLS")
+	Cmd.Flags().StringVar(&cfg.COARemoteSignerTLSCA, "coa-remote-signer-tls-ca", "", "CA certificate file verifying the remote signer, the system CAs are used if not provided")
+	Cmd.Flags().StringVar(&remoteSignerTimeout, "coa-remote-signer-timeout", "5s", "Timeout of each request to the remote signer")
+	Cmd.Flags().Uint64Var(&cfg.COARemoteSignerRetries, "coa-remote-signer-retries", 3, "Number of times a failed request to the remote signer is retried")
 	Cmd.Flags().BoolVar(&cfg.CreateCOAResource, "coa-resource-create", false, "Auto-create the COA resource in the Flow COA account provided if one doesn't exist")
 	Cmd.Flags().StringVar(&logLevel, "log-level", "debug", "Define verbosity of the log output ('debug', 'info', 'warn', 'error', 'fatal', 'panic')")
 	Cmd.Flags().StringVar(&logWriter, "log-writer", "stderr", "Log writer used for output ('stderr', 'console')")
@@ -270,6 +413,7 @@ func init() {
 	Cmd.Flags().StringVar(&cfg.AddressHeader, "address-header", "", "Address header that contains the client IP, this is useful when the server is behind a proxy that sets the source IP of the client. Leave empty if no proxy is used.")
 	Cmd.Flags().Uint64Var(&cfg.HeartbeatInterval, "heartbeat-interval", 100, "Heartbeat interval for AN event subscription")
 	Cmd.Flags().UintVar(&cfg.CacheSize, "script-cache-size", 10000, "Cache size used for script execution in items kept in cache")
+	Cmd.Flags().UintVar(&cfg.RegisterCacheSize, "register-cache-size", 50000, "Cache size used for register values fetched from the execution data API in items kept in cache, 0 disables the cache")
 	Cmd.Flags().IntVar(&streamTimeout, "stream-timeout", 3, "Defines the timeout in seconds the server waits for the event to be sent to the client")
 	Cmd.Flags().Uint64Var(&forceStartHeight, "force-start-height", 0, "Force set starting Cadence height. WARNING: This should only be used locally or for testing, never in production.")
 	Cmd.Flags().StringVar(&filterExpiry, "filter-expiry", "5m", "Filter defines the time it takes for an idle filter to expire")
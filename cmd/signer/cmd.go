@@ -0,0 +1,139 @@
+package signer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-evm-gateway/services/requester"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "remote-signer",
+	Short: "Runs the reference remote signer of the COA keys, for tests and local development",
+	RunE: func(*cobra.Command, []string) error {
+		logger := zerolog.New(zerolog.NewConsoleWriter()).With().Timestamp().Logger()
+
+		if keystoreFiles == "" {
+			return fmt.Errorf("must provide the keystore files of the keys with the keystore flag")
+		}
+
+		// the clients must be authenticated if the remote signer is reachable from other hosts
+		loopback, err := isLoopback(listenAddr)
+		if err != nil {
+			return fmt.Errorf("invalid listen address %s: %w", listenAddr, err)
+		}
+		if !loopback && tlsClientCA == "" {
+			return fmt.Errorf("the remote signer listening on the non-loopback address %s requires the tls-client-ca flag", listenAddr)
+		}
+		if tlsClientCA != "" && tlsCert == "" {
+			return fmt.Errorf("the tls-client-ca flag requires the server certificate with the tls-cert flag")
+		}
+
+		passphrase, err := requester.ReadKeystorePassphrase(passphraseEnv, passphraseFD)
+		if err != nil {
+			return fmt.Errorf("invalid keystore passphrase: %w", err)
+		}
+
+		// the key ID is the name of the keystore file, without the extension
+		keys := make(map[string]crypto.PrivateKey)
+		for _, file := range strings.Split(keystoreFiles, ",") {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read the keystore file %s: %w", file, err)
+			}
+			key, err := requester.DecryptKeystore(raw, passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to load the keystore file %s: %w", file, err)
+			}
+
+			keyID := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			keys[keyID] = key
+			logger.Info().
+				Str("key-id", keyID).
+				Str("public-key", key.PublicKey().String()).
+				Msg("key loaded")
+		}
+
+		handler, err := requester.NewRemoteSignerServer(keys, crypto.SHA3_256, logger)
+		if err != nil {
+			return err
+		}
+
+		server := &http.Server{
+			Addr:              listenAddr,
+			Handler:           handler,
+			ReadHeaderTimeout: time.Second * 10,
+		}
+
+		if tlsCert == "" {
+			logger.Warn().Msg("remote signer is served without TLS, only use it for local development")
+			return server.ListenAndServe()
+		}
+
+		// the clients are authenticated with mTLS, if the client CA is provided
+		if tlsClientCA != "" {
+			ca, err := os.ReadFile(tlsClientCA)
+			if err != nil {
+				return fmt.Errorf("failed to read the client CA certificate: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return fmt.Errorf("no valid certificates found in the client CA file %s", tlsClientCA)
+			}
+			server.TLSConfig = &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				ClientCAs:  pool,
+				ClientAuth: tls.RequireAndVerifyClientCert,
+			}
+		}
+
+		logger.Info().Str("address", listenAddr).Msg("remote signer started")
+		return server.ListenAndServeTLS(tlsCert, tlsKey)
+	},
+}
+
+// isLoopback returns whether the listen address only accepts connections from the local host.
+func isLoopback(addr string) (bool, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false, err
+	}
+	if host == "localhost" {
+		return true, nil
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback(), nil
+}
+
+var (
+	keystoreFiles,
+	passphraseEnv,
+	listenAddr,
+	tlsCert,
+	tlsKey,
+	tlsClientCA string
+
+	passphraseFD int
+)
+
+func init() {
+	Cmd.Flags().StringVar(&keystoreFiles, "keystore", "", "Paths of the keystore files of the keys as a comma separated list, the key ID is the name of the file without the extension")
+	Cmd.Flags().StringVar(&passphraseEnv, "password-env", "", "Name of the environment variable holding the passphrase of the keystore files")
+	Cmd.Flags().IntVar(&passphraseFD, "password-fd", -1, "File descriptor to read the passphrase of the keystore files from, this is exclusive with password-env flag")
+	Cmd.Flags().StringVar(&listenAddr, "listen", "localhost:9443", "Address the remote signer listens on, the non-loopback addresses require the tls-client-ca flag")
+	Cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Server certificate file, the remote signer is served without TLS if not provided")
+	Cmd.Flags().StringVar(&tlsKey, "tls-key", "", "Server key file")
+	Cmd.Flags().StringVar(&tlsClientCA, "tls-client-ca", "", "CA certificate file verifying the client certificates, enabling mTLS")
+}
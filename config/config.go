@@ -51,11 +51,91 @@ type Config struct {
 	COAKeys []crypto.PrivateKey
 	// COACloudKMSKeys is a slice of all the keys and their versions that will be used in Cloud KMS key-rotation mechanism.
 	COACloudKMSKeys []flowGoKMS.Key
+	// COAKeystoreFiles are the encrypted keystore files of the COA keys, decrypted with the
+	// COAKeystorePassphrase. Multiple keys are used in key-rotation mechanism.
+	COAKeystoreFiles []string
+	// COAKeystorePassphrase is the passphrase of the COA keystore files.
+	COAKeystorePassphrase string
+	// COARemoteSignerURL is the URL of the remote signer holding the COA keys.
+	COARemoteSignerURL string
+	// COARemoteSignerKeyIDs are the IDs of the COA keys of the remote signer, which will be used
+	// in key-rotation mechanism.
+	COARemoteSignerKeyIDs []string
+	// COARemoteSignerTLSCert and COARemoteSignerTLSKey are the client certificate and key files
+	// authenticating the gateway to the remote signer with mTLS.
+	COARemoteSignerTLSCert string
+	COARemoteSignerTLSKey  string
+	// COARemoteSignerTLSCA is the CA certificate file verifying the remote signer.
+	COARemoteSignerTLSCA string
+	// COARemoteSignerTimeout is the timeout of each request to the remote signer.
+	COARemoteSignerTimeout time.Duration
+	// COARemoteSignerRetries is the number of times a failed request to the remote signer is retried.
+	COARemoteSignerRetries uint64
 	// CreateCOAResource indicates if the COA resource should be auto-created on
 	// startup if one doesn't exist in the COA Flow address account
 	CreateCOAResource bool
 	// GasPrice is a fixed gas price that will be used when submitting transactions.
 	GasPrice *big.Int
+	// GasPriceStrategy is the strategy of the gas price oracle, either fixed or percentile.
+	GasPriceStrategy string
+	// GasPriceFloor is the lowest gas price quoted and accepted by the percentile strategy,
+	// if not set the GasPrice is used.
+	GasPriceFloor *big.Int
+	// GasPriceCeiling is the highest gas price quoted and accepted by the percentile strategy,
+	// if not set the gas price is not capped.
+	GasPriceCeiling *big.Int
+	// GasPriceBlocks is the number of the recent blocks the percentile strategy samples the tips from.
+	GasPriceBlocks uint64
+	// GasPricePercentile is the percentile of the sampled tips quoted by the percentile strategy.
+	GasPricePercentile float64
+	// AsyncTxSubmission returns the EVM transaction hash as soon as the Flow transaction is
+	// accepted by the Access Node, and awaits the Flow transaction to be sealed in the background.
+	AsyncTxSubmission bool
+	// TxBatchSize is the maximum number of EVM transactions submitted in a single Flow transaction,
+	// the batching is disabled if the size is lower than 2.
+	TxBatchSize uint64
+	// TxBatchInterval is the longest duration the EVM transactions are collected into a batch.
+	TxBatchInterval time.Duration
+	// TxQueueLimit is the maximum number of transactions of a sender, with a nonce ahead of the
+	// next nonce of the sender, queued until the nonce gap is filled. The queueing is disabled if 0.
+	TxQueueLimit uint64
+	// TxQueueTTL is the longest duration a transaction is queued for.
+	TxQueueTTL time.Duration
+	// TxResubmitLimit is the maximum number of times the Flow transaction executing the EVM
+	// transactions is rebuilt and resubmitted, if it expired or had a sequence number mismatch.
+	TxResubmitLimit uint64
+	// KeyHealthCheckInterval is the interval the COA proposal keys are checked against the account,
+	// so the unhealthy keys are quarantined, and the healthy ones are returned into rotation.
+	KeyHealthCheckInterval time.Duration
+	// COAKeyIndices are the indexes of the COA proposal keys the gateway instance is assigned, so
+	// the instances sharing the COA account use disjoint keys. All the keys are used if empty.
+	COAKeyIndices []uint32
+	// COAKeyPartition is the partition of the COA proposal keys the gateway instance is assigned,
+	// which are the keys with the index modulo COAKeyPartitionCount equal to the partition.
+	COAKeyPartition uint32
+	// COAKeyPartitionCount is the number of the partitions of the COA proposal keys, usually the
+	// number of the gateway instances sharing the COA account. The partitioning is disabled if 0.
+	COAKeyPartitionCount uint32
+	// KeyOverlapCheckPeriod is the period the sequence numbers of the unused COA proposal keys are
+	// observed at startup, to detect the keys used by another gateway instance. Disabled if 0.
+	KeyOverlapCheckPeriod time.Duration
+	// AdminAPIEnabled sets whether the admin APIs are enabled
+	AdminAPIEnabled bool
+	// AdminAPIHost is the host the admin API server listens to, separately from the RPC API server
+	AdminAPIHost string
+	// AdminAPIPort is the port the admin API server listens to
+	AdminAPIPort int
+	// RPCGasCap is the global gas cap for eth_call and eth_estimateGas executions.
+	RPCGasCap uint64
+	// LocalExecution executes calls and state reads in-process over the EVM state registers
+	// fetched from the Access Node, instead of executing Cadence scripts.
+	LocalExecution bool
+	// IndexState indexes the EVM state registers from the execution data register updates,
+	// so the state reads at the latest block are served from the local database.
+	IndexState bool
+	// ArchiveMode keeps the indexed EVM state registers at every height, so the state
+	// at any block indexed since the archive started is read from the local database.
+	ArchiveMode bool
 	// InitCadenceHeight is used for initializing the database on a local emulator or a live network.
 	InitCadenceHeight uint64
 	// LogLevel defines how verbose the output log is
@@ -94,6 +174,9 @@ type Config struct {
 	IndexOnly bool
 	// Cache size in units of items in cache, one unit in cache takes approximately 64 bytes
 	CacheSize uint
+	// RegisterCacheSize is the number of register values fetched from the execution
+	// data API kept in cache, a zero value disables the cache
+	RegisterCacheSize uint
 	// ProfilerEnabled sets whether the profiler server is enabled
 	ProfilerEnabled bool
 	// ProfilerHost is the host for the profiler server will listen to (e.g. localhost, 0.0.0.0)
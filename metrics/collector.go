@@ -19,6 +19,13 @@ type Collector interface {
 	EVMAccountInteraction(address string)
 	MeasureRequestDuration(start time.Time, method string)
 	OperatorBalance(account *flow.Account)
+	RegisterCacheHit()
+	RegisterCacheMiss()
+	RegistersFetched(count int, start time.Time)
+	TransactionSubmissionCompleted(sealed bool, start time.Time)
+	TransactionResubmitted(reason string)
+	HealthyKeys(count int)
+	KeyQuarantined(reason string)
 }
 
 var _ Collector = &DefaultCollector{}
@@ -35,6 +42,15 @@ type DefaultCollector struct {
 	operatorBalance           prometheus.Gauge
 	evmAccountCallCounters    *prometheus.CounterVec
 	requestDurations          *prometheus.HistogramVec
+	registerCacheHits         prometheus.Counter
+	registerCacheMisses       prometheus.Counter
+	registersFetched          prometheus.Counter
+	registerFetchDurations    prometheus.Histogram
+	txSubmissionsCounters     *prometheus.CounterVec
+	txSealDurations           prometheus.Histogram
+	txResubmissionsCounters   *prometheus.CounterVec
+	healthyKeys               prometheus.Gauge
+	keyQuarantinesCounters    *prometheus.CounterVec
 }
 
 func NewCollector(logger zerolog.Logger) Collector {
@@ -90,6 +106,53 @@ func NewCollector(logger zerolog.Logger) Collector {
 		Buckets: prometheus.DefBuckets,
 	}, []string{"method"})
 
+	registerCacheHits := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: prefixedName("register_cache_hits_total"),
+		Help: "Total number of register reads served from the register cache",
+	})
+
+	registerCacheMisses := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: prefixedName("register_cache_misses_total"),
+		Help: "Total number of register reads not found in the register cache",
+	})
+
+	registersFetched := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: prefixedName("registers_fetched_total"),
+		Help: "Total number of registers fetched from the access node",
+	})
+
+	registerFetchDurations := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    prefixedName("register_fetch_duration_seconds"),
+		Help:    "Duration of the register values requests made to the access node",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	txSubmissionsCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prefixedName("tx_submissions_total"),
+		Help: "Total number of completed transaction submissions by status (sealed, failed)",
+	}, []string{"status"})
+
+	txSealDurations := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    prefixedName("tx_seal_duration_seconds"),
+		Help:    "Duration between the submission of the Flow transaction and its sealing or failure",
+		Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120, 180},
+	})
+
+	txResubmissionsCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prefixedName("tx_resubmissions_total"),
+		Help: "Total number of Flow transactions rebuilt and resubmitted by reason (expired, sequence_mismatch)",
+	}, []string{"reason"})
+
+	healthyKeys := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: prefixedName("healthy_keys"),
+		Help: "Number of the COA proposal keys in rotation, which are not quarantined",
+	})
+
+	keyQuarantinesCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prefixedName("key_quarantines_total"),
+		Help: "Total number of COA proposal keys pulled out of rotation by reason",
+	}, []string{"reason"})
+
 	metrics := []prometheus.Collector{
 		apiErrors,
 		traceDownloadErrorCounter,
@@ -101,6 +164,15 @@ func NewCollector(logger zerolog.Logger) Collector {
 		operatorBalance,
 		evmAccountCallCounters,
 		requestDurations,
+		registerCacheHits,
+		registerCacheMisses,
+		registersFetched,
+		registerFetchDurations,
+		txSubmissionsCounters,
+		txSealDurations,
+		txResubmissionsCounters,
+		healthyKeys,
+		keyQuarantinesCounters,
 	}
 	if err := registerMetrics(logger, metrics...); err != nil {
 		logger.Info().Msg("using noop collector as metric register failed")
@@ -118,6 +190,15 @@ func NewCollector(logger zerolog.Logger) Collector {
 		evmAccountCallCounters:    evmAccountCallCounters,
 		requestDurations:          requestDurations,
 		operatorBalance:           operatorBalance,
+		registerCacheHits:         registerCacheHits,
+		registerCacheMisses:       registerCacheMisses,
+		registersFetched:          registersFetched,
+		registerFetchDurations:    registerFetchDurations,
+		txSubmissionsCounters:     txSubmissionsCounters,
+		txSealDurations:           txSealDurations,
+		txResubmissionsCounters:   txResubmissionsCounters,
+		healthyKeys:               healthyKeys,
+		keyQuarantinesCounters:    keyQuarantinesCounters,
 	}
 }
 
@@ -172,6 +253,40 @@ func (c *DefaultCollector) MeasureRequestDuration(start time.Time, method string
 		Observe(time.Since(start).Seconds())
 }
 
+func (c *DefaultCollector) RegisterCacheHit() {
+	c.registerCacheHits.Inc()
+}
+
+func (c *DefaultCollector) RegisterCacheMiss() {
+	c.registerCacheMisses.Inc()
+}
+
+func (c *DefaultCollector) RegistersFetched(count int, start time.Time) {
+	c.registersFetched.Add(float64(count))
+	c.registerFetchDurations.Observe(time.Since(start).Seconds())
+}
+
+func (c *DefaultCollector) TransactionSubmissionCompleted(sealed bool, start time.Time) {
+	status := "sealed"
+	if !sealed {
+		status = "failed"
+	}
+	c.txSubmissionsCounters.With(prometheus.Labels{"status": status}).Inc()
+	c.txSealDurations.Observe(time.Since(start).Seconds())
+}
+
+func (c *DefaultCollector) TransactionResubmitted(reason string) {
+	c.txResubmissionsCounters.With(prometheus.Labels{"reason": reason}).Inc()
+}
+
+func (c *DefaultCollector) HealthyKeys(count int) {
+	c.healthyKeys.Set(float64(count))
+}
+
+func (c *DefaultCollector) KeyQuarantined(reason string) {
+	c.keyQuarantinesCounters.With(prometheus.Labels{"reason": reason}).Inc()
+}
+
 func prefixedName(name string) string {
 	return fmt.Sprintf("evm_gateway_%s", name)
 }
@@ -12,12 +12,19 @@ var _ Collector = &nopCollector{}
 
 var NopCollector = &nopCollector{}
 
-func (c *nopCollector) ApiErrorOccurred()                        {}
-func (c *nopCollector) TraceDownloadFailed()                     {}
-func (c *nopCollector) ServerPanicked(string)                    {}
-func (c *nopCollector) CadenceHeightIndexed(uint64)              {}
-func (c *nopCollector) EVMHeightIndexed(uint64)                  {}
-func (c *nopCollector) EVMTransactionIndexed(int)                {}
-func (c *nopCollector) EVMAccountInteraction(string)             {}
-func (c *nopCollector) MeasureRequestDuration(time.Time, string) {}
-func (c *nopCollector) OperatorBalance(*flow.Account)            {}
+func (c *nopCollector) ApiErrorOccurred()                              {}
+func (c *nopCollector) TraceDownloadFailed()                           {}
+func (c *nopCollector) ServerPanicked(string)                          {}
+func (c *nopCollector) CadenceHeightIndexed(uint64)                    {}
+func (c *nopCollector) EVMHeightIndexed(uint64)                        {}
+func (c *nopCollector) EVMTransactionIndexed(int)                      {}
+func (c *nopCollector) EVMAccountInteraction(string)                   {}
+func (c *nopCollector) MeasureRequestDuration(time.Time, string)       {}
+func (c *nopCollector) OperatorBalance(*flow.Account)                  {}
+func (c *nopCollector) RegisterCacheHit()                              {}
+func (c *nopCollector) RegisterCacheMiss()                             {}
+func (c *nopCollector) RegistersFetched(int, time.Time)                {}
+func (c *nopCollector) TransactionSubmissionCompleted(bool, time.Time) {}
+func (c *nopCollector) TransactionResubmitted(string)                  {}
+func (c *nopCollector) HealthyKeys(int)                                {}
+func (c *nopCollector) KeyQuarantined(string)                          {}
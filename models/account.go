@@ -0,0 +1,15 @@
+package models
+
+import (
+	"math/big"
+
+	"github.com/onflow/go-ethereum/common"
+)
+
+// Account is the nonce and balance of an EVM account after the changes
+// of an EVM block were applied.
+type Account struct {
+	Address common.Address
+	Nonce   uint64
+	Balance *big.Int
+}
@@ -30,6 +30,7 @@ var (
 
 	ErrFailedTransaction  = errors.New("failed transaction")
 	ErrInvalidTransaction = fmt.Errorf("%w: %w", ErrInvalid, ErrFailedTransaction)
+	ErrInvalidOverride    = fmt.Errorf("%w: %w", ErrInvalid, errors.New("override"))
 
 	// Storage errors
 
@@ -41,6 +42,8 @@ var (
 	ErrInvalidBlockRange = fmt.Errorf("%w %w", ErrInvalid, errors.New("block height range"))
 	// ErrHeightOutOfRange indicates the requested height is out of available range
 	ErrHeightOutOfRange = fmt.Errorf("%w %w", ErrInvalid, errors.New("height not in available range"))
+	// ErrHeightNotArchived indicates the requested height is before the height the state archive started at
+	ErrHeightNotArchived = fmt.Errorf("%w: %w", ErrHeightOutOfRange, errors.New("height before the state archive start"))
 )
 
 func NewEndpointNotSupportedError(endpoint string) error {
@@ -51,6 +54,10 @@ func NewHeightOutOfRangeError(height uint64) error {
 	return fmt.Errorf("%w: %d", ErrHeightOutOfRange, height)
 }
 
+func NewHeightNotArchivedError(height uint64, archiveStart uint64) error {
+	return fmt.Errorf("%w: height %d, archive started at height %d", ErrHeightNotArchived, height, archiveStart)
+}
+
 func NewFailedTransactionError(reason string) error {
 	return fmt.Errorf("%w: %w", ErrFailedTransaction, errors.New(reason))
 }
@@ -59,6 +66,10 @@ func NewInvalidTransactionError(err error) error {
 	return fmt.Errorf("%w: %w", ErrInvalidTransaction, err)
 }
 
+func NewInvalidOverrideError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOverride, reason)
+}
+
 func NewTxGasPriceTooLowError(gasPrice *big.Int) error {
 	return NewInvalidTransactionError(fmt.Errorf(
 		"the minimum accepted gas price for transactions is: %d",
@@ -3,7 +3,9 @@ package models
 import (
 	"fmt"
 	"math/big"
+	"sort"
 
+	"github.com/onflow/flow-go/fvm/evm/types"
 	"github.com/onflow/go-ethereum/common"
 	gethTypes "github.com/onflow/go-ethereum/core/types"
 	"github.com/onflow/go-ethereum/rlp"
@@ -69,6 +71,77 @@ func ReceiptsFromBytes(data []byte) ([]*Receipt, error) {
 	return receipts, nil
 }
 
+// BaseFeePerGas is the base fee per gas reported for the EVM blocks. The EVM has no
+// base fee, so the reported base fee is the lowest accepted gas price.
+var BaseFeePerGas = big.NewInt(1)
+
+// TransactionFee is the gas used by a transaction and the effective tip it paid per gas.
+type TransactionFee struct {
+	GasUsed uint64
+	Tip     *big.Int
+}
+
+// TransactionFees returns the fees of the transactions in the receipts, sorted by the
+// tip in ascending order. The tip is the effective gas price above the base fee, so
+// the tip and the base fee add up to the gas price. The direct calls are not priced,
+// so they are not included.
+func TransactionFees(receipts []*Receipt) []*TransactionFee {
+	fees := make([]*TransactionFee, 0, len(receipts))
+	for _, receipt := range receipts {
+		if receipt.Type == types.DirectCallTxType {
+			continue
+		}
+
+		tip := big.NewInt(0)
+		if receipt.EffectiveGasPrice != nil && receipt.EffectiveGasPrice.Cmp(BaseFeePerGas) > 0 {
+			tip.Sub(receipt.EffectiveGasPrice, BaseFeePerGas)
+		}
+		fees = append(fees, &TransactionFee{GasUsed: receipt.GasUsed, Tip: tip})
+	}
+
+	sort.SliceStable(fees, func(i, j int) bool {
+		return fees[i].Tip.Cmp(fees[j].Tip) < 0
+	})
+
+	return fees
+}
+
+// TipsAtPercentiles returns the tips at the percentiles of the fees sorted by tip,
+// where each transaction is weighted by the gas it used. The percentiles must be
+// in ascending order. If there are no fees, nil is returned.
+func TipsAtPercentiles(fees []*TransactionFee, percentiles []float64) []*big.Int {
+	if len(fees) == 0 {
+		return nil
+	}
+
+	var totalGasUsed uint64
+	for _, fee := range fees {
+		totalGasUsed += fee.GasUsed
+	}
+
+	tips := make([]*big.Int, len(percentiles))
+	index := 0
+	sumGasUsed := fees[0].GasUsed
+	for i, p := range percentiles {
+		threshold := uint64(float64(totalGasUsed) * p / 100)
+		for sumGasUsed < threshold && index < len(fees)-1 {
+			index++
+			sumGasUsed += fees[index].GasUsed
+		}
+		tips[i] = fees[index].Tip
+	}
+
+	return tips
+}
+
+func TransactionFeesFromBytes(data []byte) ([]*TransactionFee, error) {
+	var fees []*TransactionFee
+	if err := rlp.DecodeBytes(data, &fees); err != nil {
+		return nil, fmt.Errorf("failed to RLP-decode transaction fees [%x] %w", data, err)
+	}
+	return fees, nil
+}
+
 type BloomsHeight struct {
 	Blooms []*gethTypes.Bloom
 	Height uint64
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// RegisterUpdates is a wrapper around the register updates streamed
+// for a Cadence height, and it also contains an error
+type RegisterUpdates struct {
+	CadenceHeight uint64
+	Registers     flow.RegisterEntries
+	Err           error
+}
+
+func NewRegisterUpdates(cadenceHeight uint64, registers flow.RegisterEntries) RegisterUpdates {
+	return RegisterUpdates{
+		CadenceHeight: cadenceHeight,
+		Registers:     registers,
+	}
+}
+
+func NewRegisterUpdatesError(err error) RegisterUpdates {
+	return RegisterUpdates{
+		Err: err,
+	}
+}
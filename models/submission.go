@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/go-ethereum/common"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/rlp"
+)
+
+// SubmissionStatus is the status of the Flow transaction submitted for an EVM transaction.
+type SubmissionStatus string
+
+const (
+	// SubmissionSubmitted is the status of the Flow transaction sent to the Access Node,
+	// which is not yet sealed.
+	SubmissionSubmitted SubmissionStatus = "submitted"
+	// SubmissionSealed is the status of the sealed Flow transaction, which executed the EVM transaction.
+	SubmissionSealed SubmissionStatus = "sealed"
+	// SubmissionFailed is the status of the Flow transaction, which failed or wasn't sealed in time.
+	SubmissionFailed SubmissionStatus = "failed"
+)
+
+// Submission is the submission of an EVM transaction in a Flow transaction by the gateway.
+type Submission struct {
+	Hash common.Hash
+	// RawTx is the encoded EVM transaction
+	RawTx  []byte
+	FlowID flow.Identifier
+	// KeyIndex is the index of the COA key which signed the Flow transaction
+	KeyIndex uint32
+	Status   SubmissionStatus
+	// Error is the reason the submission failed
+	Error string
+	// SubmittedAt is the unix time in seconds the Flow transaction was sent at
+	SubmittedAt uint64
+}
+
+func NewSubmissionFromBytes(data []byte) (*Submission, error) {
+	var s *Submission
+	if err := rlp.DecodeBytes(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to RLP-decode submission [%x] %w", data, err)
+	}
+	return s, nil
+}
+
+func (s *Submission) ToBytes() ([]byte, error) {
+	return rlp.EncodeToBytes(s)
+}
+
+// Transaction decodes the submitted EVM transaction.
+func (s *Submission) Transaction() (*gethTypes.Transaction, error) {
+	tx := &gethTypes.Transaction{}
+	if err := tx.UnmarshalBinary(s.RawTx); err != nil {
+		return nil, fmt.Errorf("failed to decode submitted transaction %s: %w", s.Hash, err)
+	}
+	return tx, nil
+}
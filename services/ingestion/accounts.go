@@ -0,0 +1,174 @@
+package ingestion
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+var _ atree.Ledger = &registersLedger{}
+
+// registersLedger is a read-only ledger of the register updates, which reads
+// the registers that weren't updated from the indexed registers.
+type registersLedger struct {
+	updates   map[flowGo.RegisterID]flowGo.RegisterValue
+	registers storage.RegisterView
+}
+
+func (l *registersLedger) GetValue(owner, key []byte) ([]byte, error) {
+	id := flowGo.RegisterID{Owner: string(owner), Key: string(key)}
+	if value, ok := l.updates[id]; ok {
+		return value, nil
+	}
+	if l.registers == nil {
+		return nil, nil
+	}
+
+	value, _, err := l.registers.GetValue(owner, key)
+	return value, err
+}
+
+func (l *registersLedger) ValueExists(owner, key []byte) (bool, error) {
+	val, err := l.GetValue(owner, key)
+	return len(val) > 0, err
+}
+
+func (l *registersLedger) SetValue(owner, key, value []byte) error {
+	panic("read only")
+}
+
+func (l *registersLedger) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
+	panic("read only")
+}
+
+// changedAccounts returns the EVM accounts changed by the register updates.
+//
+// The accounts are stored in an atree map of the EVM storage account, so each account
+// change updates the register of the map slab holding the account. The accounts are
+// decoded from the updated slabs and compared to the same slabs before the update,
+// read from the indexed registers. The accounts which are no longer found in any
+// of the updated slabs were deleted, so they are returned with the zero values.
+// If the slab wasn't indexed before, all its accounts are returned as changed.
+func changedAccounts(
+	registers flowGo.RegisterEntries,
+	indexed storage.RegisterView,
+) ([]*models.Account, error) {
+	updates := make(map[flowGo.RegisterID]flowGo.RegisterValue, len(registers))
+	for _, register := range registers {
+		updates[register.Key] = register.Value
+	}
+
+	before, err := newSlabStorage(&registersLedger{registers: indexed})
+	if err != nil {
+		return nil, err
+	}
+	after, err := newSlabStorage(&registersLedger{updates: updates, registers: indexed})
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make(map[common.Address]*models.Account)
+	current := make(map[common.Address]*models.Account)
+	for id := range updates {
+		slabID, ok := mapSlabID(id)
+		if !ok {
+			continue
+		}
+
+		if err := slabAccounts(before, slabID, previous); err != nil {
+			return nil, fmt.Errorf("failed to decode accounts of slab %s before the update: %w", slabID, err)
+		}
+		if err := slabAccounts(after, slabID, current); err != nil {
+			return nil, fmt.Errorf("failed to decode accounts of slab %s: %w", slabID, err)
+		}
+	}
+
+	accounts := make([]*models.Account, 0, len(current))
+	for address, account := range current {
+		prev, ok := previous[address]
+		if ok && prev.Nonce == account.Nonce && prev.Balance.Cmp(account.Balance) == 0 {
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	for address := range previous {
+		if _, ok := current[address]; !ok {
+			accounts = append(accounts, &models.Account{Address: address, Balance: big.NewInt(0)})
+		}
+	}
+
+	return accounts, nil
+}
+
+// newSlabStorage returns the slab storage of the EVM state read from the ledger.
+func newSlabStorage(ledger atree.Ledger) (*atree.PersistentSlabStorage, error) {
+	return state.NewPersistentSlabStorage(atree.NewLedgerBaseStorage(ledger))
+}
+
+// mapSlabID returns the ID of the slab stored in the register,
+// if the register stores a slab.
+func mapSlabID(id flowGo.RegisterID) (atree.SlabID, bool) {
+	if len(id.Key) != 1+len(atree.SlabIndex{}) || id.Key[0] != '$' {
+		return atree.SlabID{}, false
+	}
+
+	var address atree.Address
+	var index atree.SlabIndex
+	copy(address[:], id.Owner)
+	copy(index[:], id.Key[1:])
+	return atree.NewSlabID(address, index), true
+}
+
+// slabAccounts decodes the accounts stored in the map slab, and adds them to the accounts.
+// The accounts are the map elements with the address as the key, and the encoded account
+// with the same address as the value. The elements of the other maps, like the contract
+// code and storage slots, don't match this, so they are skipped.
+func slabAccounts(
+	slabs *atree.PersistentSlabStorage,
+	id atree.SlabID,
+	accounts map[common.Address]*models.Account,
+) error {
+	slab, found, err := slabs.Retrieve(id)
+	if err != nil || !found {
+		return err
+	}
+
+	dataSlab, ok := slab.(*atree.MapDataSlab)
+	if !ok {
+		return nil
+	}
+
+	return dataSlab.PopIterate(slabs, func(key atree.Storable, value atree.Storable) {
+		k, ok := key.(state.ByteStringValue)
+		if !ok || len(k.Bytes()) != common.AddressLength {
+			return
+		}
+		v, ok := value.(state.ByteStringValue)
+		if !ok {
+			return
+		}
+
+		account, err := state.DecodeAccount(v.Bytes())
+		if err != nil || account == nil || !bytes.Equal(account.Address.Bytes(), k.Bytes()) {
+			return
+		}
+
+		balance := big.NewInt(0)
+		if account.Balance != nil {
+			balance = account.Balance.ToBig()
+		}
+		accounts[account.Address] = &models.Account{
+			Address: account.Address,
+			Nonce:   account.Nonce,
+			Balance: balance,
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package ingestion
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	"github.com/onflow/flow-go/fvm/evm/types"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	gethCommon "github.com/onflow/go-ethereum/common"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+func TestChangedAccounts(t *testing.T) {
+	root := flowGo.Address{1, 2, 3, 4, 5, 6, 7, 8}
+	ledger := newTestLedger()
+
+	first := gethCommon.HexToAddress("0x01")
+	second := gethCommon.HexToAddress("0x02")
+	contract := gethCommon.HexToAddress("0x03")
+
+	view, err := state.NewBaseView(ledger, root)
+	require.NoError(t, err)
+	require.NoError(t, view.CreateAccount(first, uint256.NewInt(100), 1, nil, gethTypes.EmptyCodeHash))
+	require.NoError(t, view.CreateAccount(second, uint256.NewInt(200), 2, nil, gethTypes.EmptyCodeHash))
+	code := []byte{1, 2, 3}
+	require.NoError(t, view.CreateAccount(contract, uint256.NewInt(0), 1, code, gethCommon.Hash{1}))
+	require.NoError(t, view.UpdateSlot(types.SlotAddress{Address: contract, Key: gethCommon.Hash{1}}, gethCommon.Hash{2}))
+	require.NoError(t, view.Commit())
+
+	// all the accounts of the slabs which weren't indexed are changed
+	accounts, err := changedAccounts(ledger.updates(), nil)
+	require.NoError(t, err)
+	assertAccounts(t, []*models.Account{
+		{Address: first, Nonce: 1, Balance: big.NewInt(100)},
+		{Address: second, Nonce: 2, Balance: big.NewInt(200)},
+		{Address: contract, Nonce: 1, Balance: big.NewInt(0)},
+	}, accounts)
+
+	indexed := ledger.view()
+
+	view, err = state.NewBaseView(ledger, root)
+	require.NoError(t, err)
+	require.NoError(t, view.UpdateAccount(first, uint256.NewInt(50), 2, nil, gethTypes.EmptyCodeHash))
+	require.NoError(t, view.DeleteAccount(second))
+	require.NoError(t, view.UpdateSlot(types.SlotAddress{Address: contract, Key: gethCommon.Hash{1}}, gethCommon.Hash{3}))
+	require.NoError(t, view.Commit())
+
+	// only the updated and deleted accounts are changed
+	accounts, err = changedAccounts(ledger.updates(), indexed)
+	require.NoError(t, err)
+	assertAccounts(t, []*models.Account{
+		{Address: first, Nonce: 2, Balance: big.NewInt(50)},
+		{Address: second, Nonce: 0, Balance: big.NewInt(0)},
+	}, accounts)
+}
+
+func assertAccounts(t *testing.T, expected []*models.Account, actual []*models.Account) {
+	require.Len(t, actual, len(expected))
+	for _, e := range expected {
+		found := false
+		for _, a := range actual {
+			if a.Address == e.Address {
+				assert.Equal(t, e.Nonce, a.Nonce)
+				assert.Zero(t, e.Balance.Cmp(a.Balance), "balance of %s", e.Address)
+				found = true
+			}
+		}
+		assert.True(t, found, "account %s not changed", e.Address)
+	}
+}
+
+// testLedger is an in-memory ledger, which records the updated registers.
+type testLedger struct {
+	values  map[flowGo.RegisterID][]byte
+	updated map[flowGo.RegisterID]struct{}
+	indexes map[string]uint64
+}
+
+var _ atree.Ledger = &testLedger{}
+
+func newTestLedger() *testLedger {
+	return &testLedger{
+		values:  make(map[flowGo.RegisterID][]byte),
+		updated: make(map[flowGo.RegisterID]struct{}),
+		indexes: make(map[string]uint64),
+	}
+}
+
+func (l *testLedger) GetValue(owner, key []byte) ([]byte, error) {
+	return l.values[flowGo.RegisterID{Owner: string(owner), Key: string(key)}], nil
+}
+
+func (l *testLedger) SetValue(owner, key, value []byte) error {
+	id := flowGo.RegisterID{Owner: string(owner), Key: string(key)}
+	l.values[id] = value
+	l.updated[id] = struct{}{}
+	return nil
+}
+
+func (l *testLedger) ValueExists(owner, key []byte) (bool, error) {
+	value, err := l.GetValue(owner, key)
+	return len(value) > 0, err
+}
+
+func (l *testLedger) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
+	l.indexes[string(owner)]++
+	var index atree.SlabIndex
+	binary.BigEndian.PutUint64(index[:], l.indexes[string(owner)])
+	return index, nil
+}
+
+// updates returns the registers updated since the previous call.
+func (l *testLedger) updates() flowGo.RegisterEntries {
+	entries := make(flowGo.RegisterEntries, 0, len(l.updated))
+	for id := range l.updated {
+		entries = append(entries, flowGo.RegisterEntry{Key: id, Value: l.values[id]})
+	}
+	l.updated = make(map[flowGo.RegisterID]struct{})
+	return entries
+}
+
+// view returns a copy of the current registers.
+func (l *testLedger) view() storage.RegisterView {
+	view := make(testRegisterView, len(l.values))
+	for id, value := range l.values {
+		view[id] = value
+	}
+	return view
+}
+
+// testRegisterView is an in-memory view of the indexed registers.
+type testRegisterView map[flowGo.RegisterID][]byte
+
+func (v testRegisterView) GetValue(owner, key []byte) ([]byte, bool, error) {
+	value, ok := v[flowGo.RegisterID{Owner: string(owner), Key: string(key)}]
+	return value, ok, nil
+}
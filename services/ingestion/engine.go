@@ -2,15 +2,18 @@ package ingestion
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	pebbleDB "github.com/cockroachdb/pebble"
 	"github.com/onflow/flow-go-sdk"
+	flowGo "github.com/onflow/flow-go/model/flow"
 	gethTypes "github.com/onflow/go-ethereum/core/types"
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-evm-gateway/metrics"
 	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
 	"github.com/onflow/flow-evm-gateway/storage"
 	"github.com/onflow/flow-evm-gateway/storage/pebble"
 )
@@ -28,6 +31,9 @@ var _ models.Engine = &Engine{}
 //
 // The ingested events explained above are then indexed in a local database and
 // used in any queries from the RPC APIs.
+// If the register subscriber is provided, the EVM storage account register updates
+// are indexed together with the events of the same height, so the local state
+// always matches the latest indexed block.
 // Ingestion of the events is idempotent so if a reindex needs to happen it can, since
 // it will just overwrite the current indexed data. Idempotency is an important
 // requirement of the implementation of this engine.
@@ -40,20 +46,29 @@ type Engine struct {
 	receipts        storage.ReceiptIndexer
 	transactions    storage.TransactionIndexer
 	accounts        storage.AccountIndexer
+	registers       storage.RegisterIndexer
 	log             zerolog.Logger
 	evmLastHeight   *models.SequentialHeight
 	blocksPublisher *models.Publisher[*models.Block]
 	logsPublisher   *models.Publisher[[]*gethTypes.Log]
 	collector       metrics.Collector
+
+	registerSubscriber RegisterSubscriber
+	registerUpdates    <-chan models.RegisterUpdates
+	// pendingRegisters are the received register updates of a height
+	// that wasn't indexed yet
+	pendingRegisters *models.RegisterUpdates
 }
 
 func NewEventIngestionEngine(
 	subscriber EventSubscriber,
+	registerSubscriber RegisterSubscriber,
 	store *pebble.Storage,
 	blocks storage.BlockIndexer,
 	receipts storage.ReceiptIndexer,
 	transactions storage.TransactionIndexer,
 	accounts storage.AccountIndexer,
+	registers storage.RegisterIndexer,
 	blocksPublisher *models.Publisher[*models.Block],
 	logsPublisher *models.Publisher[[]*gethTypes.Log],
 	log zerolog.Logger,
@@ -70,10 +85,13 @@ func NewEventIngestionEngine(
 		receipts:        receipts,
 		transactions:    transactions,
 		accounts:        accounts,
+		registers:       registers,
 		log:             log,
 		blocksPublisher: blocksPublisher,
 		logsPublisher:   logsPublisher,
 		collector:       collector,
+
+		registerSubscriber: registerSubscriber,
 	}
 }
 
@@ -105,6 +123,24 @@ func (e *Engine) Run(ctx context.Context) error {
 
 	e.log.Info().Uint64("start-cadence-height", latestCadence).Msg("starting ingestion")
 
+	if e.registerSubscriber != nil {
+		// the registers not updated while the indexing was disabled would be stale
+		registersHeight, err := e.registers.LatestCadenceHeight()
+		if err != nil && !errors.Is(err, errs.ErrStorageNotInitialized) {
+			return fmt.Errorf("failed to get latest register height: %w", err)
+		}
+		if err == nil && registersHeight < latestCadence {
+			return fmt.Errorf(
+				"registers are indexed up to cadence height %d, behind the latest cadence height %d, the database must be reindexed",
+				registersHeight,
+				latestCadence,
+			)
+		}
+
+		e.registerUpdates = e.registerSubscriber.Subscribe(ctx, latestCadence)
+		e.pendingRegisters = nil
+	}
+
 	e.MarkReady()
 
 	for events := range e.subscriber.Subscribe(ctx, latestCadence) {
@@ -144,24 +180,32 @@ func (e *Engine) processEvents(events *models.CadenceEvents) error {
 		Int("cadence-event-length", events.Length()).
 		Msg("received new cadence evm events")
 
+	batch := e.store.NewBatch()
+	defer batch.Close()
+
+	registers, err := e.indexRegisters(events.CadenceHeight(), batch)
+	if err != nil {
+		return fmt.Errorf("failed to index registers at cadence height %d: %w", events.CadenceHeight(), err)
+	}
+
 	// if heartbeat interval with no data still update the cadence height
 	if events.Empty() {
-		if err := e.blocks.SetLatestCadenceHeight(events.CadenceHeight(), nil); err != nil {
+		if err := e.blocks.SetLatestCadenceHeight(events.CadenceHeight(), batch); err != nil {
 			return fmt.Errorf(
 				"failed to update to latest cadence height: %d, during events ingestion: %w",
 				events.CadenceHeight(),
 				err,
 			)
 		}
+		if err := batch.Commit(pebbleDB.Sync); err != nil {
+			return fmt.Errorf("failed to commit indexed data for Cadence block %d: %w", events.CadenceHeight(), err)
+		}
 		e.collector.CadenceHeightIndexed(events.CadenceHeight())
 		return nil // nothing else to do this was heartbeat event with not event payloads
 	}
 
-	batch := e.store.NewBatch()
-	defer batch.Close()
-
 	// we first index the block
-	err := e.indexBlock(
+	err = e.indexBlock(
 		events.CadenceHeight(),
 		events.CadenceBlockID(),
 		events.Block(),
@@ -171,6 +215,11 @@ func (e *Engine) processEvents(events *models.CadenceEvents) error {
 		return fmt.Errorf("failed to index block %d event: %w", events.Block().Height, err)
 	}
 
+	err = e.indexAccounts(registers, events.Block().Height, batch)
+	if err != nil {
+		return fmt.Errorf("failed to index accounts for block %d: %w", events.Block().Height, err)
+	}
+
 	for i, tx := range events.Transactions() {
 		receipt := events.Receipts()[i]
 
@@ -236,6 +285,80 @@ func (e *Engine) indexBlock(
 	return e.blocks.Store(cadenceHeight, cadenceID, block, batch)
 }
 
+// indexRegisters indexes the register updates of all the heights up to and including
+// the provided Cadence height, since the events are not received for every height.
+// The indexed register updates are returned.
+func (e *Engine) indexRegisters(cadenceHeight uint64, batch *pebbleDB.Batch) (flowGo.RegisterEntries, error) {
+	if e.registerSubscriber == nil {
+		return nil, nil
+	}
+
+	var indexed flowGo.RegisterEntries
+	for {
+		if e.pendingRegisters == nil {
+			updates, ok := <-e.registerUpdates
+			if !ok {
+				return nil, fmt.Errorf("register updates subscription ended before height %d", cadenceHeight)
+			}
+			if updates.Err != nil {
+				return nil, fmt.Errorf("failure in register updates subscription: %w", updates.Err)
+			}
+			e.pendingRegisters = &updates
+		}
+
+		if e.pendingRegisters.CadenceHeight > cadenceHeight {
+			return indexed, nil
+		}
+
+		updates := e.pendingRegisters
+		e.pendingRegisters = nil
+		if err := e.registers.Store(updates.Registers, updates.CadenceHeight, batch); err != nil {
+			return nil, err
+		}
+		indexed = append(indexed, updates.Registers...)
+		if updates.CadenceHeight == cadenceHeight {
+			return indexed, nil
+		}
+	}
+}
+
+// indexAccounts indexes the nonce and balance of the accounts changed by the register
+// updates at the EVM height. The registers indexed before the updates are read from
+// the database, since the updates are not yet committed.
+func (e *Engine) indexAccounts(
+	registers flowGo.RegisterEntries,
+	evmHeight uint64,
+	batch *pebbleDB.Batch,
+) error {
+	if len(registers) == 0 {
+		return nil
+	}
+
+	var indexed storage.RegisterView
+	snapshot, err := e.registers.Snapshot()
+	switch {
+	case err == nil:
+		defer func() {
+			if err := snapshot.Close(); err != nil {
+				e.log.Error().Err(err).Msg("failed to close registers snapshot")
+			}
+		}()
+		indexed = snapshot
+	case !errors.Is(err, errs.ErrStorageNotInitialized):
+		return err
+	}
+
+	accounts, err := changedAccounts(registers, indexed)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	return e.accounts.Update(accounts, evmHeight, batch)
+}
+
 func (e *Engine) indexTransaction(
 	tx models.Transaction,
 	receipt *models.Receipt,
@@ -257,10 +380,6 @@ func (e *Engine) indexTransaction(
 		return fmt.Errorf("failed to store tx: %s, with: %w", tx.Hash(), err)
 	}
 
-	if err := e.accounts.Update(tx, receipt, batch); err != nil {
-		return fmt.Errorf("failed to update accounts for tx: %s, with: %w", tx.Hash(), err)
-	}
-
 	return nil
 }
 
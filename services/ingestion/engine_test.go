@@ -63,11 +63,13 @@ func TestSerialBlockIngestion(t *testing.T) {
 
 		engine := NewEventIngestionEngine(
 			subscriber,
+			nil,
 			store,
 			blocks,
 			receipts,
 			transactions,
 			accounts,
+			nil,
 			models.NewPublisher[*models.Block](),
 			models.NewPublisher[[]*gethTypes.Log](),
 			zerolog.Nop(),
@@ -143,11 +145,13 @@ func TestSerialBlockIngestion(t *testing.T) {
 
 		engine := NewEventIngestionEngine(
 			subscriber,
+			nil,
 			store,
 			blocks,
 			receipts,
 			transactions,
 			accounts,
+			nil,
 			models.NewPublisher[*models.Block](),
 			models.NewPublisher[[]*gethTypes.Log](),
 			zerolog.Nop(),
@@ -258,11 +262,13 @@ func TestBlockAndTransactionIngestion(t *testing.T) {
 
 		engine := NewEventIngestionEngine(
 			subscriber,
+			nil,
 			store,
 			blocks,
 			receipts,
 			transactions,
 			accounts,
+			nil,
 			models.NewPublisher[*models.Block](),
 			models.NewPublisher[[]*gethTypes.Log](),
 			zerolog.Nop(),
@@ -361,11 +367,13 @@ func TestBlockAndTransactionIngestion(t *testing.T) {
 
 		engine := NewEventIngestionEngine(
 			subscriber,
+			nil,
 			store,
 			blocks,
 			receipts,
 			transactions,
 			accounts,
+			nil,
 			models.NewPublisher[*models.Block](),
 			models.NewPublisher[[]*gethTypes.Log](),
 			zerolog.Nop(),
@@ -457,11 +465,13 @@ func TestBlockAndTransactionIngestion(t *testing.T) {
 
 		engine := NewEventIngestionEngine(
 			subscriber,
+			nil,
 			store,
 			blocks,
 			receipts,
 			transactions,
 			accounts,
+			nil,
 			models.NewPublisher[*models.Block](),
 			models.NewPublisher[[]*gethTypes.Log](),
 			zerolog.Nop(),
@@ -548,6 +558,160 @@ func TestBlockAndTransactionIngestion(t *testing.T) {
 	})
 }
 
+func TestRegisterIngestion(t *testing.T) {
+	owner := string([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	registerA := flowGo.RegisterID{Owner: owner, Key: "a"}
+	registerB := flowGo.RegisterID{Owner: owner, Key: "b"}
+
+	t.Run("index register updates with blocks and heartbeats", func(t *testing.T) {
+		latestHeight := uint64(10)
+
+		store, err := pebble.New(t.TempDir(), zerolog.Nop())
+		require.NoError(t, err)
+		ledger := pebble.NewLedger(store)
+
+		blocks := &storageMock.BlockIndexer{}
+		blocks.
+			On("LatestCadenceHeight").
+			Return(func() (uint64, error) {
+				return latestHeight, nil
+			}).
+			Once()
+
+		blockCdc, _, blockEvent, err := newBlock(1, nil)
+		require.NoError(t, err)
+
+		blocks.
+			On("Store", mock.AnythingOfType("uint64"), mock.Anything, mock.AnythingOfType("*models.Block"), mock.Anything).
+			Return(func(h uint64, _ flow.Identifier, _ *models.Block, _ *pebbleDB.Batch) error {
+				assert.Equal(t, latestHeight+2, h)
+				return nil
+			}).
+			Once()
+
+		blocks.
+			On("SetLatestCadenceHeight", mock.AnythingOfType("uint64"), mock.Anything).
+			Return(func(h uint64, _ *pebbleDB.Batch) error {
+				assert.Equal(t, latestHeight+3, h)
+				return nil
+			}).
+			Once()
+
+		eventsChan := make(chan models.BlockEvents)
+		subscriber := &mocks.EventSubscriber{}
+		subscriber.
+			On("Subscribe", mock.Anything, mock.AnythingOfType("uint64")).
+			Return(func(ctx context.Context, latest uint64) <-chan models.BlockEvents {
+				return eventsChan
+			})
+
+		// the events are not received for every height
+		registersChan := make(chan models.RegisterUpdates, 4)
+		registersChan <- models.NewRegisterUpdates(latestHeight, nil)
+		registersChan <- models.NewRegisterUpdates(latestHeight+1, flowGo.RegisterEntries{
+			{Key: registerA, Value: []byte{1}},
+		})
+		registersChan <- models.NewRegisterUpdates(latestHeight+2, flowGo.RegisterEntries{
+			{Key: registerB, Value: []byte{2}},
+		})
+		registersChan <- models.NewRegisterUpdates(latestHeight+3, flowGo.RegisterEntries{
+			{Key: registerA, Value: []byte{3}},
+		})
+		close(registersChan)
+
+		registerSubscriber := &mocks.RegisterSubscriber{}
+		registerSubscriber.
+			On("Subscribe", mock.Anything, mock.AnythingOfType("uint64")).
+			Return(func(ctx context.Context, latest uint64) <-chan models.RegisterUpdates {
+				assert.Equal(t, latestHeight, latest)
+				return registersChan
+			})
+
+		engine := NewEventIngestionEngine(
+			subscriber,
+			registerSubscriber,
+			store,
+			blocks,
+			&storageMock.ReceiptIndexer{},
+			&storageMock.TransactionIndexer{},
+			&storageMock.AccountIndexer{},
+			ledger,
+			models.NewPublisher[*models.Block](),
+			models.NewPublisher[[]*gethTypes.Log](),
+			zerolog.Nop(),
+			metrics.NopCollector,
+		)
+
+		done := make(chan struct{})
+		go func() {
+			err := engine.Run(context.Background())
+			assert.NoError(t, err)
+			close(done)
+		}()
+
+		eventsChan <- models.NewBlockEvents(flow.BlockEvents{
+			Events: []flow.Event{{
+				Type:  string(blockEvent.Etype),
+				Value: blockCdc,
+			}},
+			Height: latestHeight + 2,
+		})
+
+		eventsChan <- models.NewBlockEvents(flow.BlockEvents{
+			Height: latestHeight + 3,
+		})
+
+		close(eventsChan)
+		<-done
+
+		height, err := ledger.LatestCadenceHeight()
+		require.NoError(t, err)
+		assert.Equal(t, latestHeight+3, height)
+
+		value, err := ledger.GetValue([]byte(owner), []byte(registerA.Key))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{3}, value)
+
+		value, err = ledger.GetValue([]byte(owner), []byte(registerB.Key))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, value)
+	})
+
+	t.Run("fail if registers are behind the indexed height", func(t *testing.T) {
+		latestHeight := uint64(10)
+
+		store, err := pebble.New(t.TempDir(), zerolog.Nop())
+		require.NoError(t, err)
+		ledger := pebble.NewLedger(store)
+		require.NoError(t, ledger.Store(nil, latestHeight-1, nil))
+
+		blocks := &storageMock.BlockIndexer{}
+		blocks.
+			On("LatestCadenceHeight").
+			Return(func() (uint64, error) {
+				return latestHeight, nil
+			})
+
+		engine := NewEventIngestionEngine(
+			&mocks.EventSubscriber{},
+			&mocks.RegisterSubscriber{},
+			store,
+			blocks,
+			&storageMock.ReceiptIndexer{},
+			&storageMock.TransactionIndexer{},
+			&storageMock.AccountIndexer{},
+			ledger,
+			models.NewPublisher[*models.Block](),
+			models.NewPublisher[[]*gethTypes.Log](),
+			zerolog.Nop(),
+			metrics.NopCollector,
+		)
+
+		err = engine.Run(context.Background())
+		require.ErrorContains(t, err, "the database must be reindexed")
+	})
+}
+
 func newBlock(height uint64, txHashes []gethCommon.Hash) (cadence.Event, *models.Block, *events.Event, error) {
 	gethBlock := types.NewBlock(
 		gethCommon.HexToHash("0x1"),
@@ -0,0 +1,193 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/fvm/evm"
+	"github.com/onflow/flow-go/ledger"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/services/requester"
+)
+
+type RegisterSubscriber interface {
+	// Subscribe to the EVM storage account register updates from the provided height,
+	// and return a chanel with the updates for each height.
+	//
+	// The RegisterUpdates type will contain an optional error in case
+	// the error happens, the consumer of the chanel should handle it.
+	Subscribe(ctx context.Context, height uint64) <-chan models.RegisterUpdates
+}
+
+var _ RegisterSubscriber = &RPCRegisterSubscriber{}
+
+// RPCRegisterSubscriber streams the register updates from the execution data API,
+// and filters the updates of the EVM storage account.
+type RPCRegisterSubscriber struct {
+	client *requester.CrossSporkClient
+	owner  string
+	logger zerolog.Logger
+}
+
+func NewRPCRegisterSubscriber(
+	client *requester.CrossSporkClient,
+	chainID flowGo.ChainID,
+	logger zerolog.Logger,
+) *RPCRegisterSubscriber {
+	logger = logger.With().Str("component", "register-subscriber").Logger()
+	return &RPCRegisterSubscriber{
+		client: client,
+		owner:  string(evm.StorageAccountAddress(chainID).Bytes()),
+		logger: logger,
+	}
+}
+
+// Subscribe will stream the register updates from the provided height. If the height is from
+// previous sporks, the updates are streamed from each previous spork until the end of the spork,
+// and then from the next spork.
+//
+// If error is encountered the subscription will end and the response chanel will be closed.
+func (r *RPCRegisterSubscriber) Subscribe(ctx context.Context, height uint64) <-chan models.RegisterUpdates {
+	updates := make(chan models.RegisterUpdates)
+
+	go func() {
+		defer func() {
+			close(updates)
+		}()
+
+		for ctx.Err() == nil {
+			// the stream of a past spork is ended at the last height of the spork
+			var lastHeight *uint64
+			if r.client.IsPastSpork(height) {
+				latest, err := r.client.GetLatestHeightForSpork(ctx, height)
+				if err != nil {
+					updates <- models.NewRegisterUpdatesError(err)
+					return
+				}
+				lastHeight = &latest
+			}
+
+			r.logger.Info().
+				Uint64("start-height", height).
+				Msg("subscribing to register updates")
+
+			next, err := r.subscribe(ctx, height, lastHeight, updates)
+			if err != nil {
+				updates <- models.NewRegisterUpdatesError(err)
+				return
+			}
+			height = next
+		}
+	}()
+
+	return updates
+}
+
+// subscribe streams the register updates from the height until the last height, if provided,
+// and returns the next height to stream from.
+func (r *RPCRegisterSubscriber) subscribe(
+	ctx context.Context,
+	height uint64,
+	lastHeight *uint64,
+	updates chan<- models.RegisterUpdates,
+) (uint64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dataStream, errChan, err := r.client.SubscribeExecutionDataByBlockHeight(ctx, height)
+	if err != nil {
+		return 0, fmt.Errorf("failed to subscribe to execution data by block height: %d, with: %w", height, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+
+		case data, ok := <-dataStream:
+			if !ok {
+				if ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				return 0, errs.ErrDisconnected
+			}
+
+			registers, err := r.registers(data.ExecutionData)
+			if err != nil {
+				return 0, fmt.Errorf("failed to decode register updates at height %d: %w", data.Height, err)
+			}
+			updates <- models.NewRegisterUpdates(data.Height, registers)
+
+			if lastHeight != nil && data.Height == *lastHeight {
+				return data.Height + 1, nil
+			}
+
+		case err, ok := <-errChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				return 0, errs.ErrDisconnected
+			}
+			return 0, fmt.Errorf("%w: %w", errs.ErrDisconnected, err)
+		}
+	}
+}
+
+// registers returns the EVM storage account registers updated in the execution data,
+// in the order the chunks were executed.
+func (r *RPCRegisterSubscriber) registers(data *flow.ExecutionData) (flowGo.RegisterEntries, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var registers flowGo.RegisterEntries
+	for _, chunk := range data.ChunkExecutionData {
+		if chunk == nil || chunk.TrieUpdate == nil {
+			continue
+		}
+
+		for _, payload := range chunk.TrieUpdate.Payloads {
+			id, err := registerID(payload)
+			if err != nil {
+				return nil, err
+			}
+			if id.Owner != r.owner {
+				continue
+			}
+
+			registers = append(registers, flowGo.RegisterEntry{
+				Key:   id,
+				Value: payload.Value,
+			})
+		}
+	}
+
+	return registers, nil
+}
+
+// registerID decodes the register ID from the key parts of the payload.
+func registerID(payload *flow.Payload) (flowGo.RegisterID, error) {
+	if payload == nil || len(payload.KeyPart) != 2 {
+		return flowGo.RegisterID{}, fmt.Errorf("invalid payload key")
+	}
+
+	var id flowGo.RegisterID
+	for _, part := range payload.KeyPart {
+		switch part.Type {
+		case ledger.KeyPartOwner:
+			id.Owner = string(part.Value)
+		case ledger.KeyPartKey:
+			id.Key = string(part.Value)
+		default:
+			return flowGo.RegisterID{}, fmt.Errorf("invalid payload key part type: %d", part.Type)
+		}
+	}
+
+	return id, nil
+}
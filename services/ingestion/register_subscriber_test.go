@@ -0,0 +1,98 @@
+package ingestion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access"
+	"github.com/onflow/flow-go/fvm/evm"
+	"github.com/onflow/flow-go/ledger"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/services/requester"
+	"github.com/onflow/flow-evm-gateway/services/testutils"
+)
+
+// this test simulates two previous sporks and current spork, the register
+// updates should be streamed from each spork in sequence, and only the
+// updates of the EVM storage account should be emitted.
+func Test_RegisterSubscribing(t *testing.T) {
+	const endHeight = 30
+	owner := evm.StorageAccountAddress(flowGo.Previewnet).Bytes()
+
+	setupClient := func(startHeight, endHeight uint64) *testutils.MockClient {
+		client := testutils.SetupClientForRange(startHeight, endHeight)
+		client.SubscribeExecutionDataByBlockHeightFunc = func(
+			ctx context.Context,
+			height uint64,
+		) (<-chan flow.ExecutionDataStreamResponse, <-chan error, error) {
+			data := make(chan flow.ExecutionDataStreamResponse)
+			go func() {
+				defer close(data)
+				for i := height; i <= endHeight; i++ {
+					data <- flow.ExecutionDataStreamResponse{
+						Height: i,
+						ExecutionData: &flow.ExecutionData{
+							ChunkExecutionData: []*flow.ChunkExecutionData{{
+								TrieUpdate: &flow.TrieUpdate{
+									Payloads: []*flow.Payload{
+										newPayload(owner, "key", []byte{byte(i)}),
+										newPayload([]byte{1}, "key", []byte{0}),
+									},
+								},
+							}},
+						},
+					}
+				}
+			}()
+			return data, make(chan error), nil
+		}
+		return client
+	}
+
+	client, err := requester.NewCrossSporkClient(
+		setupClient(21, endHeight),
+		[]access.Client{
+			setupClient(1, 10),
+			setupClient(11, 20),
+		},
+		zerolog.Nop(),
+		flowGo.Previewnet,
+	)
+	require.NoError(t, err)
+
+	subscriber := NewRPCRegisterSubscriber(client, flowGo.Previewnet, zerolog.Nop())
+
+	var prevHeight uint64
+	for updates := range subscriber.Subscribe(context.Background(), 1) {
+		if prevHeight == endHeight {
+			require.ErrorIs(t, updates.Err, errs.ErrDisconnected)
+			break
+		}
+
+		require.NoError(t, updates.Err)
+		require.Equal(t, prevHeight+1, updates.CadenceHeight)
+		prevHeight = updates.CadenceHeight
+
+		require.Len(t, updates.Registers, 1)
+		assert.Equal(t, flowGo.RegisterID{Owner: string(owner), Key: "key"}, updates.Registers[0].Key)
+		assert.Equal(t, flowGo.RegisterValue{byte(prevHeight)}, updates.Registers[0].Value)
+	}
+
+	require.Equal(t, uint64(endHeight), prevHeight)
+}
+
+func newPayload(owner []byte, key string, value []byte) *flow.Payload {
+	return &flow.Payload{
+		KeyPart: []*flow.KeyPart{
+			{Type: ledger.KeyPartOwner, Value: owner},
+			{Type: ledger.KeyPartKey, Value: []byte(key)},
+		},
+		Value: value,
+	}
+}
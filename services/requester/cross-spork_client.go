@@ -214,3 +214,14 @@ func (c *CrossSporkClient) SubscribeEventsByBlockHeight(
 	}
 	return client.SubscribeEventsByBlockHeight(ctx, startHeight, filter, opts...)
 }
+
+func (c *CrossSporkClient) SubscribeExecutionDataByBlockHeight(
+	ctx context.Context,
+	startHeight uint64,
+) (<-chan flow.ExecutionDataStreamResponse, <-chan error, error) {
+	client, err := c.getClientForHeight(startHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.SubscribeExecutionDataByBlockHeight(ctx, startHeight)
+}
@@ -0,0 +1,227 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
+	"github.com/onflow/go-ethereum/core"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/params"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+// estimateGasErrorRatio is the allowed overestimation ratio of the gas estimation,
+// which allows the binary search to terminate sooner.
+const estimateGasErrorRatio = 0.015
+
+// dryRunFunc executes the transaction without persisting any state changes
+// and returns the result summary, including any execution errors.
+type dryRunFunc func(tx *types.Transaction) (*evmTypes.ResultSummary, error)
+
+// gasEstimator finds the lowest gas limit the transaction executes successfully with,
+// using a binary search between the intrinsic gas and the gas limit cap.
+//
+// All the executions must use the same state, the results are cached by the gas
+// limit, so the same gas limit is never executed twice.
+type gasEstimator struct {
+	tx      *types.Transaction
+	run     dryRunFunc
+	results map[uint64]*evmTypes.ResultSummary
+}
+
+func newGasEstimator(tx *types.Transaction, run dryRunFunc) *gasEstimator {
+	return &gasEstimator{
+		tx:      tx,
+		run:     run,
+		results: make(map[uint64]*evmTypes.ResultSummary),
+	}
+}
+
+// estimate returns the lowest gas limit, capped by the provided gas cap, the transaction
+// executes successfully with. If the transaction fails with the highest allowed gas limit,
+// the execution error is returned, including the revert data if the transaction reverted.
+func (g *gasEstimator) estimate(ctx context.Context, gasCap uint64) (uint64, error) {
+	intrinsic, err := core.IntrinsicGas(
+		g.tx.Data(),
+		g.tx.AccessList(),
+		g.tx.To() == nil,
+		true,
+		true,
+		true,
+	)
+	if err != nil {
+		return 0, errs.NewInvalidTransactionError(err)
+	}
+
+	hi := g.tx.Gas()
+	if gasCap != 0 && hi > gasCap {
+		hi = gasCap
+	}
+	if hi < intrinsic {
+		return 0, errs.NewInvalidTransactionError(
+			fmt.Errorf("%w: have %d, want %d", core.ErrIntrinsicGas, hi, intrinsic),
+		)
+	}
+
+	// plain value transfers most likely only need the intrinsic gas
+	if len(g.tx.Data()) == 0 && g.tx.To() != nil {
+		failed, _, err := g.execute(params.TxGas)
+		if err != nil {
+			return 0, err
+		}
+		if !failed {
+			return params.TxGas, nil
+		}
+	}
+
+	// first execute with the highest gas limit, if it fails it will always fail
+	failed, result, err := g.execute(hi)
+	if err != nil {
+		return 0, err
+	}
+	if failed {
+		if !isOutOfGas(result) {
+			_, err := checkResult(result)
+			return 0, err
+		}
+		return 0, errs.NewFailedTransactionError(
+			fmt.Sprintf("gas required exceeds allowance (%d)", hi),
+		)
+	}
+
+	lo := usedGas(result)
+	if lo < intrinsic {
+		lo = intrinsic
+	}
+	lo -= 1
+
+	// the gas consumed reported by the dry run already includes the gas for the
+	// 63/64 rule, the SSTORE sentry and the refunds, so it is likely enough
+	if optimistic := result.GasConsumed; optimistic > lo && optimistic < hi {
+		failed, _, err := g.execute(optimistic)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			lo = optimistic
+		} else {
+			hi = optimistic
+		}
+	}
+
+	for lo+1 < hi {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		if float64(hi-lo)/float64(hi) < estimateGasErrorRatio {
+			break
+		}
+
+		mid := (hi + lo) / 2
+		if mid > lo*2 {
+			// most transactions don't need much more gas than they use,
+			// so the search is skewed to favor the low side
+			mid = lo * 2
+		}
+
+		failed, _, err := g.execute(mid)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return hi, nil
+}
+
+// execute runs the transaction with the given gas limit and returns true if the
+// execution failed for a reason that might be related to the gas limit.
+// An error is returned if the execution failed for other reasons.
+func (g *gasEstimator) execute(gasLimit uint64) (bool, *evmTypes.ResultSummary, error) {
+	result, ok := g.results[gasLimit]
+	if !ok {
+		var err error
+		result, err = g.run(withGasLimit(g.tx, gasLimit))
+		if err != nil {
+			return false, nil, err
+		}
+		g.results[gasLimit] = result
+	}
+
+	switch {
+	case result.ErrorCode == evmTypes.ErrCodeNoError:
+		return false, result, nil
+	case result.ErrorCode == evmTypes.ValidationErrCodeIntrinsicGas:
+		return true, result, nil
+	case result.ErrorCode >= evmTypes.ExecutionErrCodeOutOfGas:
+		// any execution error might be caused by the gas limit, such as when
+		// the execution reverts after checking the gas left
+		return true, result, nil
+	default:
+		_, err := checkResult(result)
+		return false, nil, err
+	}
+}
+
+// usedGas returns the lower bound of the gas used by the execution,
+// without the additions made to the gas consumed by the dry run.
+func usedGas(result *evmTypes.ResultSummary) uint64 {
+	consumed := result.GasConsumed
+	additions := result.GasRefund + params.SstoreSentryGasEIP2200
+	if consumed <= additions {
+		return 0
+	}
+	// reverse of adding one 64th of the gas used
+	return (consumed - additions) * 64 / 65
+}
+
+func isOutOfGas(result *evmTypes.ResultSummary) bool {
+	return result.ErrorCode == evmTypes.ExecutionErrCodeOutOfGas ||
+		result.ErrorCode == evmTypes.ExecutionErrCodeCodeStoreOutOfGas ||
+		result.ErrorCode == evmTypes.ValidationErrCodeIntrinsicGas
+}
+
+// withGasLimit returns a copy of the unsigned transaction with the given gas limit.
+func withGasLimit(tx *types.Transaction, gasLimit uint64) *types.Transaction {
+	switch tx.Type() {
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   tx.GasPrice(),
+			Gas:        gasLimit,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	case types.DynamicFeeTxType:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        gasLimit,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	default:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: tx.GasPrice(),
+			Gas:      gasLimit,
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+	}
+}
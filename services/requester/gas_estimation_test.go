@@ -0,0 +1,158 @@
+package requester
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+func Test_GasEstimator(t *testing.T) {
+	to := common.HexToAddress("0x2000")
+
+	newTx := func(gas uint64, data []byte) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{
+			To:       &to,
+			Value:    big.NewInt(0),
+			Gas:      gas,
+			GasPrice: big.NewInt(0),
+			Data:     data,
+		})
+	}
+
+	// requiredGasRun fails with out of gas below the required gas,
+	// and counts the executions for each gas limit.
+	requiredGasRun := func(required uint64, runs map[uint64]int) dryRunFunc {
+		return func(tx *types.Transaction) (*evmTypes.ResultSummary, error) {
+			runs[tx.Gas()]++
+			if tx.Gas() < required {
+				return &evmTypes.ResultSummary{
+					ErrorCode:   evmTypes.ExecutionErrCodeOutOfGas,
+					GasConsumed: tx.Gas(),
+				}, nil
+			}
+			return &evmTypes.ResultSummary{GasConsumed: required - 1000}, nil
+		}
+	}
+
+	t.Run("finds the required gas within the error ratio", func(t *testing.T) {
+		runs := make(map[uint64]int)
+		required := uint64(250_000)
+
+		gas, err := newGasEstimator(newTx(10_000_000, []byte{0x01}), requiredGasRun(required, runs)).
+			estimate(context.Background(), 0)
+		require.NoError(t, err)
+
+		assert.GreaterOrEqual(t, gas, required)
+		assert.Less(t, float64(gas-required)/float64(gas), estimateGasErrorRatio)
+		for gas, count := range runs {
+			assert.Equal(t, 1, count, "gas limit %d executed more than once", gas)
+		}
+	})
+
+	t.Run("gas cap limits the gas", func(t *testing.T) {
+		runs := make(map[uint64]int)
+
+		_, err := newGasEstimator(newTx(10_000_000, []byte{0x01}), requiredGasRun(250_000, runs)).
+			estimate(context.Background(), 100_000)
+		require.ErrorIs(t, err, errs.ErrFailedTransaction)
+		assert.ErrorContains(t, err, "gas required exceeds allowance (100000)")
+	})
+
+	t.Run("plain transfer uses intrinsic gas", func(t *testing.T) {
+		runs := make(map[uint64]int)
+
+		gas, err := newGasEstimator(newTx(10_000_000, nil), requiredGasRun(21_000, runs)).
+			estimate(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(21_000), gas)
+		assert.Len(t, runs, 1)
+	})
+
+	t.Run("revert returns revert data", func(t *testing.T) {
+		revertData := []byte{0xde, 0xad}
+		run := func(tx *types.Transaction) (*evmTypes.ResultSummary, error) {
+			return &evmTypes.ResultSummary{
+				ErrorCode:    evmTypes.ExecutionErrCodeExecutionReverted,
+				ReturnedData: revertData,
+			}, nil
+		}
+
+		_, err := newGasEstimator(newTx(10_000_000, []byte{0x01}), run).
+			estimate(context.Background(), 0)
+
+		var revertErr *errs.RevertError
+		require.ErrorAs(t, err, &revertErr)
+		assert.Equal(t, hexutil.Encode(revertData), revertErr.Reason)
+	})
+
+	t.Run("gas below intrinsic gas is invalid", func(t *testing.T) {
+		runs := make(map[uint64]int)
+
+		_, err := newGasEstimator(newTx(20_000, nil), requiredGasRun(21_000, runs)).
+			estimate(context.Background(), 0)
+		require.ErrorIs(t, err, errs.ErrInvalid)
+		assert.Empty(t, runs)
+	})
+
+	t.Run("local execution", func(t *testing.T) {
+		from := common.HexToAddress("0x1000")
+		other := common.HexToAddress("0x3000")
+		executor := newTestExecutor(t, flowGo.Emulator, from, to, testContractCode(other))
+
+		blockContext, err := executor.blockContext()
+		require.NoError(t, err)
+
+		run := func(tx *types.Transaction) (*evmTypes.ResultSummary, error) {
+			res, err := executor.dryRunWithContext(blockContext, tx, from)
+			if err != nil {
+				return nil, err
+			}
+			return res.ResultSummary(), nil
+		}
+
+		gas, err := newGasEstimator(newTx(1_000_000, nil), run).estimate(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Greater(t, gas, uint64(21_000))
+
+		res, err := run(newTx(gas, nil))
+		require.NoError(t, err)
+		assert.Equal(t, evmTypes.ErrCodeNoError, res.ErrorCode)
+
+		res, err = run(newTx(uint64(float64(gas)*(1-estimateGasErrorRatio))-1, nil))
+		require.NoError(t, err)
+		assert.NotEqual(t, evmTypes.ErrCodeNoError, res.ErrorCode)
+	})
+	t.Run("dynamic fee transaction keeps the fees", func(t *testing.T) {
+		accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{{0x1}}}}
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:    big.NewInt(646),
+			Nonce:      3,
+			GasTipCap:  big.NewInt(2),
+			GasFeeCap:  big.NewInt(10),
+			Gas:        10_000_000,
+			To:         &to,
+			Value:      big.NewInt(1),
+			Data:       []byte{0x01},
+			AccessList: accessList,
+		})
+
+		limited := withGasLimit(tx, 50_000)
+		assert.Equal(t, uint8(types.DynamicFeeTxType), limited.Type())
+		assert.Equal(t, uint64(50_000), limited.Gas())
+		assert.Equal(t, big.NewInt(2), limited.GasTipCap())
+		assert.Equal(t, big.NewInt(10), limited.GasFeeCap())
+		assert.Equal(t, accessList, limited.AccessList())
+		assert.Equal(t, tx.Nonce(), limited.Nonce())
+		assert.Equal(t, tx.Data(), limited.Data())
+	})
+}
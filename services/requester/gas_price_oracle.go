@@ -0,0 +1,245 @@
+package requester
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+const (
+	// FixedGasPriceStrategy quotes and accepts the configured gas price.
+	FixedGasPriceStrategy = "fixed"
+	// PercentileGasPriceStrategy quotes a percentile of the tips paid in the recent blocks.
+	PercentileGasPriceStrategy = "percentile"
+)
+
+// flowFeesWindow is the number of the most recent Flow fees observations,
+// used to compute the gas price covering the operator costs.
+const flowFeesWindow = 100
+
+// GasPriceOracle decides the gas price quoted to the clients, and the minimum
+// gas price of the transactions accepted by the gateway.
+type GasPriceOracle interface {
+	// GasPrice returns the gas price quoted to the clients.
+	GasPrice() (*big.Int, error)
+
+	// MinGasPrice returns the minimum gas price of the accepted transactions.
+	MinGasPrice() (*big.Int, error)
+
+	// ObserveFlowFees records the Flow fees in attoFlow paid by the operator
+	// for submitting an EVM transaction, which used the provided gas.
+	ObserveFlowFees(fees *big.Int, gasUsed uint64)
+}
+
+// NewGasPriceOracle creates the gas price oracle of the configured strategy.
+func NewGasPriceOracle(
+	config *config.Config,
+	blocks storage.BlockIndexer,
+	receipts storage.ReceiptIndexer,
+) (GasPriceOracle, error) {
+	switch config.GasPriceStrategy {
+	case "", FixedGasPriceStrategy:
+		return NewFixedGasPriceOracle(config.GasPrice), nil
+	case PercentileGasPriceStrategy:
+		return NewPercentileGasPriceOracle(config, blocks, receipts)
+	default:
+		return nil, fmt.Errorf("unknown gas price strategy: %s", config.GasPriceStrategy)
+	}
+}
+
+var _ GasPriceOracle = &FixedGasPriceOracle{}
+
+// FixedGasPriceOracle quotes and accepts a fixed gas price.
+type FixedGasPriceOracle struct {
+	price *big.Int
+}
+
+func NewFixedGasPriceOracle(price *big.Int) *FixedGasPriceOracle {
+	return &FixedGasPriceOracle{price: price}
+}
+
+func (f *FixedGasPriceOracle) GasPrice() (*big.Int, error) {
+	return new(big.Int).Set(f.price), nil
+}
+
+func (f *FixedGasPriceOracle) MinGasPrice() (*big.Int, error) {
+	return new(big.Int).Set(f.price), nil
+}
+
+func (f *FixedGasPriceOracle) ObserveFlowFees(*big.Int, uint64) {}
+
+var _ GasPriceOracle = &PercentileGasPriceOracle{}
+
+// PercentileGasPriceOracle quotes the gas price at a percentile of the tips paid in the
+// recent blocks, weighted by the gas used. The minimum accepted gas price covers the
+// Flow fees the operator recently paid per gas. Both prices are kept between the
+// configured floor and ceiling.
+type PercentileGasPriceOracle struct {
+	blocks     storage.BlockIndexer
+	receipts   storage.ReceiptIndexer
+	floor      *big.Int
+	ceiling    *big.Int
+	blockCount uint64
+	percentile float64
+
+	mux sync.Mutex
+	// height is the EVM height the demand price was computed at
+	height uint64
+	demand *big.Int
+	// flowFees contains the most recent Flow fees observations
+	flowFees []flowFees
+}
+
+// flowFees are the Flow fees in attoFlow paid for an EVM transaction, which used the gas.
+type flowFees struct {
+	fees    *big.Int
+	gasUsed uint64
+}
+
+func NewPercentileGasPriceOracle(
+	config *config.Config,
+	blocks storage.BlockIndexer,
+	receipts storage.ReceiptIndexer,
+) (*PercentileGasPriceOracle, error) {
+	floor := config.GasPriceFloor
+	if floor == nil {
+		floor = config.GasPrice
+	}
+	if config.GasPriceCeiling != nil && config.GasPriceCeiling.Sign() > 0 && config.GasPriceCeiling.Cmp(floor) < 0 {
+		return nil, fmt.Errorf(
+			"gas price ceiling %s must not be lower than the floor %s",
+			config.GasPriceCeiling,
+			floor,
+		)
+	}
+	if config.GasPriceBlocks == 0 {
+		return nil, fmt.Errorf("gas price blocks must be greater than 0")
+	}
+	if config.GasPricePercentile < 0 || config.GasPricePercentile > 100 {
+		return nil, fmt.Errorf("gas price percentile must be between 0 and 100, got: %f", config.GasPricePercentile)
+	}
+
+	return &PercentileGasPriceOracle{
+		blocks:     blocks,
+		receipts:   receipts,
+		floor:      floor,
+		ceiling:    config.GasPriceCeiling,
+		blockCount: config.GasPriceBlocks,
+		percentile: config.GasPricePercentile,
+	}, nil
+}
+
+func (p *PercentileGasPriceOracle) GasPrice() (*big.Int, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	demand, err := p.demandPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.clamp(maxBig(p.costPrice(), demand)), nil
+}
+
+func (p *PercentileGasPriceOracle) MinGasPrice() (*big.Int, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.clamp(p.costPrice()), nil
+}
+
+func (p *PercentileGasPriceOracle) ObserveFlowFees(fees *big.Int, gasUsed uint64) {
+	if fees == nil || gasUsed == 0 {
+		return
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.flowFees = append(p.flowFees, flowFees{fees: fees, gasUsed: gasUsed})
+	if len(p.flowFees) > flowFeesWindow {
+		p.flowFees = p.flowFees[len(p.flowFees)-flowFeesWindow:]
+	}
+}
+
+// demandPrice returns the gas price of the tip at the percentile of the transactions
+// in the recent blocks, which is the tip added to the base fee.
+// The price is computed again only once a new block is indexed.
+func (p *PercentileGasPriceOracle) demandPrice() (*big.Int, error) {
+	latest, err := p.blocks.LatestEVMHeight()
+	if err != nil {
+		return nil, err
+	}
+	if p.demand != nil && p.height == latest {
+		return p.demand, nil
+	}
+
+	var fees []*models.TransactionFee
+	for i := uint64(0); i < p.blockCount && i <= latest; i++ {
+		blockFees, err := p.receipts.FeesByBlockHeight(latest - i)
+		if err != nil {
+			// the empty blocks have no receipts
+			if errors.Is(err, errs.ErrEntityNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get fees of block %d: %w", latest-i, err)
+		}
+		fees = append(fees, blockFees...)
+	}
+
+	sort.SliceStable(fees, func(i, j int) bool {
+		return fees[i].Tip.Cmp(fees[j].Tip) < 0
+	})
+
+	demand := big.NewInt(0)
+	if tips := models.TipsAtPercentiles(fees, []float64{p.percentile}); tips != nil {
+		demand.Add(tips[0], models.BaseFeePerGas)
+	}
+
+	p.height = latest
+	p.demand = demand
+	return demand, nil
+}
+
+// costPrice returns the gas price covering the Flow fees recently paid per gas.
+func (p *PercentileGasPriceOracle) costPrice() *big.Int {
+	fees := big.NewInt(0)
+	gasUsed := big.NewInt(0)
+	for _, f := range p.flowFees {
+		fees.Add(fees, f.fees)
+		gasUsed.Add(gasUsed, new(big.Int).SetUint64(f.gasUsed))
+	}
+	if gasUsed.Sign() == 0 {
+		return fees
+	}
+
+	// round up, so the fees are covered
+	price, remainder := new(big.Int).QuoRem(fees, gasUsed, new(big.Int))
+	if remainder.Sign() > 0 {
+		price.Add(price, big.NewInt(1))
+	}
+	return price
+}
+
+// clamp keeps the price between the floor and the ceiling, if the ceiling is set.
+func (p *PercentileGasPriceOracle) clamp(price *big.Int) *big.Int {
+	price = maxBig(price, p.floor)
+	if p.ceiling != nil && p.ceiling.Sign() > 0 && price.Cmp(p.ceiling) > 0 {
+		price = p.ceiling
+	}
+	return new(big.Int).Set(price)
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package requester
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	storageMocks "github.com/onflow/flow-evm-gateway/storage/mocks"
+)
+
+func Test_GasPriceOracle(t *testing.T) {
+	t.Run("fixed strategy", func(t *testing.T) {
+		oracle, err := NewGasPriceOracle(&config.Config{GasPrice: big.NewInt(150)}, nil, nil)
+		require.NoError(t, err)
+
+		oracle.ObserveFlowFees(big.NewInt(1_000_000), 1)
+
+		price, err := oracle.GasPrice()
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(150), price)
+
+		minPrice, err := oracle.MinGasPrice()
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(150), minPrice)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := NewGasPriceOracle(&config.Config{GasPriceStrategy: "auction"}, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("ceiling lower than floor", func(t *testing.T) {
+		_, err := NewGasPriceOracle(&config.Config{
+			GasPriceStrategy: PercentileGasPriceStrategy,
+			GasPrice:         big.NewInt(100),
+			GasPriceCeiling:  big.NewInt(50),
+			GasPriceBlocks:   10,
+		}, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("percentile strategy", func(t *testing.T) {
+		// the blocks 9 and 10 have transactions, and the block 8 is empty
+		blocks := &storageMocks.BlockIndexer{}
+		blocks.On("LatestEVMHeight").Return(uint64(10), nil)
+
+		receipts := &storageMocks.ReceiptIndexer{}
+		receipts.On("FeesByBlockHeight", uint64(10)).Return([]*models.TransactionFee{
+			{GasUsed: 10, Tip: big.NewInt(100)},
+			{GasUsed: 30, Tip: big.NewInt(400)},
+		}, nil)
+		receipts.On("FeesByBlockHeight", uint64(9)).Return([]*models.TransactionFee{
+			{GasUsed: 60, Tip: big.NewInt(200)},
+		}, nil)
+		receipts.On("FeesByBlockHeight", mock.Anything).Return(nil, fmt.Errorf("%w: fees", errs.ErrEntityNotFound))
+
+		newOracle := func(floor, ceiling int64) GasPriceOracle {
+			oracle, err := NewGasPriceOracle(&config.Config{
+				GasPriceStrategy:   PercentileGasPriceStrategy,
+				GasPrice:           big.NewInt(floor),
+				GasPriceCeiling:    big.NewInt(ceiling),
+				GasPriceBlocks:     3,
+				GasPricePercentile: 60,
+			}, blocks, receipts)
+			require.NoError(t, err)
+			return oracle
+		}
+
+		t.Run("quotes the tip at the percentile", func(t *testing.T) {
+			oracle := newOracle(1, 0)
+
+			// the tip is added to the base fee
+			price, err := oracle.GasPrice()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(201), price)
+
+			minPrice, err := oracle.MinGasPrice()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(1), minPrice)
+		})
+
+		t.Run("keeps the price between the floor and ceiling", func(t *testing.T) {
+			price, err := newOracle(250, 0).GasPrice()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(250), price)
+
+			price, err = newOracle(1, 150).GasPrice()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(150), price)
+		})
+
+		t.Run("covers the observed Flow fees", func(t *testing.T) {
+			oracle := newOracle(1, 0)
+			oracle.ObserveFlowFees(big.NewInt(30_000), 100)
+			oracle.ObserveFlowFees(big.NewInt(31_000), 100)
+
+			minPrice, err := oracle.MinGasPrice()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(305), minPrice)
+
+			price, err := oracle.GasPrice()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(305), price)
+		})
+	})
+}
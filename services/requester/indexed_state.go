@@ -0,0 +1,217 @@
+package requester
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/fvm/evm"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	"github.com/onflow/flow-go/model/flow"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+// errStateNotIndexed is returned if the state at the requested height isn't indexed locally.
+var errStateNotIndexed = errors.New("state not indexed")
+
+var _ atree.Ledger = &indexedLedger{}
+
+// indexedLedger is a read-only ledger of the locally indexed registers. The registers
+// that weren't updated since the indexing started are not indexed, so they are read
+// from the remote ledger at the same Cadence height.
+type indexedLedger struct {
+	registers storage.RegisterView
+	remote    atree.Ledger
+}
+
+func (l *indexedLedger) GetValue(owner, key []byte) ([]byte, error) {
+	value, ok, err := l.registers.GetValue(owner, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return l.remote.GetValue(owner, key)
+	}
+	// the removed registers are indexed with an empty value
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+func (l *indexedLedger) ValueExists(owner, key []byte) (bool, error) {
+	val, err := l.GetValue(owner, key)
+	return val != nil, err
+}
+
+func (l *indexedLedger) SetValue(owner, key, value []byte) error {
+	panic("read only")
+}
+
+func (l *indexedLedger) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
+	panic("read only")
+}
+
+var _ atree.Ledger = &archiveLedger{}
+
+// archiveLedger is a read-only ledger of the registers archived at a Cadence height.
+// The registers not updated since the archive started are not archived, and if they
+// weren't updated after the height either, their value is unchanged, so they are read
+// from the remote ledger at the latest height, since the execution node might have
+// already pruned the state at the height. Only the registers updated after the height,
+// for the first time since the archive started, are read from the remote ledger at
+// the height.
+type archiveLedger struct {
+	registers storage.RegisterArchiveView
+	remote    atree.Ledger
+	latest    atree.Ledger
+}
+
+func (l *archiveLedger) GetValue(owner, key []byte) ([]byte, error) {
+	value, ok, err := l.registers.GetValue(owner, key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		// the removed registers are indexed with an empty value
+		if len(value) == 0 {
+			return nil, nil
+		}
+		return value, nil
+	}
+
+	updated, err := l.registers.UpdatedAfter(owner, key)
+	if err != nil {
+		return nil, err
+	}
+	if updated {
+		return l.remote.GetValue(owner, key)
+	}
+
+	return l.latest.GetValue(owner, key)
+}
+
+func (l *archiveLedger) ValueExists(owner, key []byte) (bool, error) {
+	val, err := l.GetValue(owner, key)
+	return val != nil, err
+}
+
+func (l *archiveLedger) SetValue(owner, key, value []byte) error {
+	panic("read only")
+}
+
+func (l *archiveLedger) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
+	panic("read only")
+}
+
+// archiveLedgerAt returns a read-only ledger of the archived registers at the EVM height,
+// together with the Cadence height of the registers. If the registers at the height
+// aren't indexed yet, errStateNotIndexed is returned, and if the height is before the
+// archive started, errors.HeightNotArchived is returned. The registers which aren't
+// archived are read from the remote ledgers, see archiveLedger.
+func (e *EVM) archiveLedgerAt(evmHeight int64) (atree.Ledger, uint64, error) {
+	if e.registers == nil || !e.config.ArchiveMode {
+		return nil, 0, errStateNotIndexed
+	}
+
+	var (
+		cadenceHeight uint64
+		err           error
+	)
+	if evmHeight < 0 {
+		cadenceHeight, err = e.registers.LatestCadenceHeight()
+	} else {
+		cadenceHeight, err = e.blocks.GetCadenceHeight(uint64(evmHeight))
+	}
+	if err != nil {
+		if errors.Is(err, errs.ErrEntityNotFound) || errors.Is(err, errs.ErrStorageNotInitialized) {
+			return nil, 0, errStateNotIndexed
+		}
+		return nil, 0, err
+	}
+
+	view, err := e.registers.ViewAt(cadenceHeight)
+	if err != nil {
+		// the state before the archive start would mix the archived and the remote
+		// registers, since the registers not updated since then aren't archived
+		if errors.Is(err, errs.ErrHeightNotArchived) {
+			return nil, 0, fmt.Errorf("state at EVM height %d is not archived: %w", evmHeight, err)
+		}
+		if errors.Is(err, errs.ErrHeightOutOfRange) || errors.Is(err, errs.ErrStorageNotInitialized) {
+			return nil, 0, errStateNotIndexed
+		}
+		return nil, 0, err
+	}
+
+	latestHeight, err := e.registers.LatestCadenceHeight()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	remote, err := e.remoteLedgerAt(cadenceHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+	latest, err := e.remoteLedgerAt(latestHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &archiveLedger{registers: view, remote: remote, latest: latest}, cadenceHeight, nil
+}
+
+// readIndexedState reads the locally indexed state at the EVM height.
+// If the state at the height isn't indexed, errStateNotIndexed is returned.
+// Only the registers updated since the indexing started are indexed, so the
+// local state is partial, and the rest are still read from the remote ledger.
+func (e *EVM) readIndexedState(evmHeight int64, read func(stateDB *state.StateDB)) error {
+	if e.registers == nil {
+		return errStateNotIndexed
+	}
+
+	ledger, _, err := e.archiveLedgerAt(evmHeight)
+	if err == nil {
+		return readState(ledger, e.config.FlowNetworkID, read)
+	}
+	if !errors.Is(err, errStateNotIndexed) {
+		return err
+	}
+
+	snapshot, err := e.registers.Snapshot()
+	if err != nil {
+		if errors.Is(err, errs.ErrStorageNotInitialized) {
+			return errStateNotIndexed
+		}
+		return err
+	}
+	defer func() {
+		if err := snapshot.Close(); err != nil {
+			e.logger.Error().Err(err).Msg("failed to close register snapshot")
+		}
+	}()
+
+	// only the state at the latest indexed block is available
+	if evmHeight >= 0 && uint64(evmHeight) != snapshot.EVMHeight() {
+		return errStateNotIndexed
+	}
+
+	remote, err := e.remoteLedgerAt(snapshot.CadenceHeight())
+	if err != nil {
+		return err
+	}
+
+	return readState(&indexedLedger{registers: snapshot, remote: remote}, e.config.FlowNetworkID, read)
+}
+
+func readState(ledger atree.Ledger, chainID flow.ChainID, read func(stateDB *state.StateDB)) error {
+	stateDB, err := state.NewStateDB(ledger, evm.StorageAccountAddress(chainID))
+	if err != nil {
+		return fmt.Errorf("failed to create state from indexed registers: %w", err)
+	}
+
+	read(stateDB)
+	return stateDB.Error()
+}
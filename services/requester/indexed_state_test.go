@@ -0,0 +1,189 @@
+package requester
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/fvm/evm"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+func Test_IndexedLedger(t *testing.T) {
+	owner := []byte{1}
+	indexed := testRegisterView{
+		"a": {1},
+		"b": {},
+	}
+	remote := testLedger{
+		"a": {2},
+		"b": {2},
+		"c": {2},
+	}
+
+	ledger := &indexedLedger{registers: indexed, remote: remote}
+
+	value, err := ledger.GetValue(owner, []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, value)
+
+	// the removed registers are not read from the remote ledger
+	value, err = ledger.GetValue(owner, []byte("b"))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	exists, err := ledger.ValueExists(owner, []byte("b"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// the registers which weren't indexed are read from the remote ledger
+	value, err = ledger.GetValue(owner, []byte("c"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2}, value)
+
+	value, err = ledger.GetValue(owner, []byte("d"))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func Test_ArchiveLedger(t *testing.T) {
+	owner := []byte{1}
+	// the registers "a" and "b" are archived at the height, "c" was updated after
+	// the height, and "d" wasn't updated since the archive started
+	archived := testArchiveView{
+		testRegisterView: testRegisterView{
+			"a": {1},
+			"b": {},
+		},
+		updatedAfter: map[string]bool{"a": true, "c": true},
+	}
+	// the execution node pruned the state at the height
+	remote := testPrunedLedger{}
+	latest := testLedger{
+		"a": {3},
+		"b": {3},
+		"c": {3},
+		"d": {3},
+	}
+
+	ledger := &archiveLedger{registers: archived, remote: remote, latest: latest}
+
+	value, err := ledger.GetValue(owner, []byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, value)
+
+	value, err = ledger.GetValue(owner, []byte("b"))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	// the registers unchanged since the archive started are read at the latest height
+	value, err = ledger.GetValue(owner, []byte("d"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3}, value)
+
+	exists, err := ledger.ValueExists(owner, []byte("e"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// the value of the register before its first archived update is only known at the height
+	_, err = ledger.GetValue(owner, []byte("c"))
+	require.ErrorIs(t, err, errs.ErrHeightOutOfRange)
+
+	t.Run("read balance of account unchanged since the archive started", func(t *testing.T) {
+		chainID := flowGo.Emulator
+		root := evm.StorageAccountAddress(chainID)
+		address := common.HexToAddress("0x1000")
+
+		latest := newTestValueStore()
+		stateDB, err := state.NewStateDB(latest, root)
+		require.NoError(t, err)
+		stateDB.CreateAccount(address)
+		stateDB.AddBalance(address, uint256.NewInt(1_000), 0)
+		_, err = stateDB.Commit(true)
+		require.NoError(t, err)
+
+		ledger := &archiveLedger{
+			registers: testArchiveView{testRegisterView: testRegisterView{}},
+			remote:    testPrunedLedger{},
+			latest:    latest,
+		}
+
+		var balance *uint256.Int
+		err = readState(ledger, chainID, func(stateDB *state.StateDB) {
+			balance = stateDB.GetBalance(address)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1_000), balance.Uint64())
+	})
+}
+
+// testRegisterView is an in-memory view of the registers of a single owner.
+type testRegisterView map[string][]byte
+
+var _ storage.RegisterView = testRegisterView{}
+
+func (v testRegisterView) GetValue(_, key []byte) ([]byte, bool, error) {
+	value, ok := v[string(key)]
+	return value, ok, nil
+}
+
+// testLedger is an in-memory read-only ledger of the registers of a single owner.
+type testLedger map[string][]byte
+
+var _ atree.Ledger = testLedger{}
+
+func (l testLedger) GetValue(_, key []byte) ([]byte, error) {
+	return l[string(key)], nil
+}
+
+func (l testLedger) ValueExists(_, key []byte) (bool, error) {
+	return l[string(key)] != nil, nil
+}
+
+func (l testLedger) SetValue(_, _, _ []byte) error {
+	panic("read only")
+}
+
+func (l testLedger) AllocateSlabIndex(_ []byte) (atree.SlabIndex, error) {
+	panic("read only")
+}
+
+// testArchiveView is an in-memory view of the registers archived at a height.
+type testArchiveView struct {
+	testRegisterView
+	updatedAfter map[string]bool
+}
+
+var _ storage.RegisterArchiveView = testArchiveView{}
+
+func (v testArchiveView) UpdatedAfter(_, key []byte) (bool, error) {
+	return v.updatedAfter[string(key)], nil
+}
+
+// testPrunedLedger is a remote ledger at a height the execution node already pruned.
+type testPrunedLedger struct{}
+
+var _ atree.Ledger = testPrunedLedger{}
+
+func (l testPrunedLedger) GetValue(_, _ []byte) ([]byte, error) {
+	return nil, errs.NewHeightOutOfRangeError(1)
+}
+
+func (l testPrunedLedger) ValueExists(_, _ []byte) (bool, error) {
+	return false, errs.NewHeightOutOfRangeError(1)
+}
+
+func (l testPrunedLedger) SetValue(_, _, _ []byte) error {
+	panic("read only")
+}
+
+func (l testPrunedLedger) AllocateSlabIndex(_ []byte) (atree.SlabIndex, error) {
+	panic("read only")
+}
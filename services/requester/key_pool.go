@@ -0,0 +1,615 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/metrics"
+)
+
+// maxKeyFailures is the number of consecutive failed validations of the flow transactions
+// proposed with a key, after which the key is quarantined.
+const maxKeyFailures = 3
+
+// the reasons a key is quarantined
+const (
+	keyRevoked            = "revoked"
+	keyMissing            = "missing"
+	keyInsufficientWeight = "insufficient_weight"
+	keyPublicKeyMismatch  = "public_key_mismatch"
+	keyFailures           = "repeated_failures"
+)
+
+// MultiKeySigner is a signer holding multiple keys, each of which can sign on its own.
+type MultiKeySigner interface {
+	crypto.Signer
+	// Signers returns the signers of each of the keys.
+	Signers() []crypto.Signer
+}
+
+// Key is a proposal key of the COA account leased to a single submission.
+type Key struct {
+	Index          uint32
+	SequenceNumber uint64
+	Signer         crypto.Signer
+}
+
+// KeyStatus is the status of a proposal key of the COA account in the key pool.
+type KeyStatus struct {
+	Index          uint32
+	PublicKey      string
+	SequenceNumber uint64
+	Weight         int
+	Leased         bool
+	// Quarantine is the reason the key is pulled out of rotation, empty if the key is healthy
+	Quarantine string
+	// Failures is the number of consecutive failed validations of the flow transactions proposed with the key
+	Failures  int
+	CheckedAt time.Time
+}
+
+// lockedSigner serializes the signing requests of the signer, which could be leased
+// under multiple key indexes, since the signers hash the message with a stateful hasher.
+type lockedSigner struct {
+	mux    sync.Mutex
+	signer crypto.Signer
+}
+
+func (s *lockedSigner) Sign(message []byte) ([]byte, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.signer.Sign(message)
+}
+
+func (s *lockedSigner) PublicKey() crypto.PublicKey {
+	return s.signer.PublicKey()
+}
+
+// poolKey is a proposal key of the COA account tracked by the key pool.
+type poolKey struct {
+	index  uint32
+	seqNum uint64
+	weight int
+	signer crypto.Signer
+	leased bool
+	// stale is set if the sequence number must be reconciled with the chain before the key is used
+	stale bool
+	// quarantine is the reason the key is pulled out of rotation, empty if the key is healthy
+	quarantine string
+	failures   int
+	checkedAt  time.Time
+}
+
+// KeyPool leases distinct proposal keys of the COA account to concurrent submissions, so the
+// submissions don't conflict on the sequence number. The sequence numbers are tracked locally,
+// and only reconciled with the chain if the outcome of a submission is not known, or the
+// submission had a sequence number mismatch. A key stays leased until the flow transaction
+// proposed with it is sealed, so the number of submissions in flight is the number of keys.
+//
+// The keys are checked against the account at creation and periodically. The keys which are
+// revoked, have insufficient weight, or repeatedly fail the validation are quarantined, so
+// they are not leased until they're healthy again.
+//
+// The gateway instances sharing the COA account must be assigned disjoint keys, either by the key
+// indexes or by partitioning the keys, since the sequence numbers are tracked by each instance.
+// The pool is concurrency-safe.
+type KeyPool struct {
+	mux       sync.Mutex
+	client    *CrossSporkClient
+	address   flow.Address
+	keys      map[uint32]*poolKey
+	free      []*poolKey
+	released  chan struct{}
+	collector metrics.Collector
+	logger    zerolog.Logger
+}
+
+// NewKeyPool creates the key pool of the keys of the COA account matching the signer, which
+// are assigned to the gateway instance. Every key of the account with the public key of the
+// signer is leased separately, and if the signer holds multiple keys, so are the keys matching
+// each of them. The health of the keys is checked at the configured interval, until the
+// context is cancelled.
+func NewKeyPool(
+	ctx context.Context,
+	client *CrossSporkClient,
+	config *config.Config,
+	signer crypto.Signer,
+	collector metrics.Collector,
+	logger zerolog.Logger,
+) (*KeyPool, error) {
+	signers := []crypto.Signer{signer}
+	if multi, ok := signer.(MultiKeySigner); ok {
+		signers = multi.Signers()
+	}
+	for i, s := range signers {
+		// the signer is shared by the account keys with the same public key
+		signers[i] = &lockedSigner{signer: s}
+	}
+
+	pool := &KeyPool{
+		client:    client,
+		address:   config.COAAddress,
+		keys:      make(map[uint32]*poolKey),
+		released:  make(chan struct{}),
+		collector: collector,
+		logger:    logger.With().Str("component", "key-pool").Logger(),
+	}
+
+	account, err := pool.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := 0
+	for _, accountKey := range account.Keys {
+		for _, s := range signers {
+			if !accountKey.PublicKey.Equals(s.PublicKey()) {
+				continue
+			}
+			matching++
+			if isKeyAssigned(config, accountKey.Index) {
+				pool.keys[accountKey.Index] = &poolKey{index: accountKey.Index, signer: s}
+			}
+			break
+		}
+	}
+
+	if matching == 0 {
+		return nil, fmt.Errorf(
+			"provided account address: %s and signer public key: %s, do not match",
+			config.COAAddress,
+			signer.PublicKey().String(),
+		)
+	}
+	if len(pool.keys) == 0 {
+		return nil, fmt.Errorf(
+			"none of the %d keys of account %s matching the signer are assigned to the gateway instance",
+			matching,
+			config.COAAddress,
+		)
+	}
+
+	pool.check(account)
+
+	healthy := len(pool.free)
+	if healthy == 0 {
+		return nil, fmt.Errorf("none of the %d keys of account %s are healthy", len(pool.keys), config.COAAddress)
+	}
+	pool.logger.Info().
+		Int("keys", len(pool.keys)).
+		Int("healthy", healthy).
+		Uints32("indexes", pool.indexes()).
+		Msg("key pool created")
+
+	if config.KeyHealthCheckInterval > 0 {
+		go pool.healthChecker(ctx, config.KeyHealthCheckInterval)
+	}
+
+	return pool, nil
+}
+
+// Lease returns a free proposal key, waiting for a key to be released if all of them are leased.
+// The key must be released once the flow transaction proposed with it is sealed.
+func (p *KeyPool) Lease(ctx context.Context) (*Key, error) {
+	for {
+		p.mux.Lock()
+		if len(p.free) > 0 {
+			key := p.free[0]
+			p.free = p.free[1:]
+			key.leased = true
+			p.mux.Unlock()
+
+			return p.lease(ctx, key)
+		}
+		released := p.released
+		p.mux.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to lease a proposal key: %w", ctx.Err())
+		}
+	}
+}
+
+// Reserve leases the proposal key by the index, if it's free. It's used to lease the keys of
+// the flow transactions submitted before a restart, which are not yet sealed.
+func (p *KeyPool) Reserve(index uint32) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	key, ok := p.keys[index]
+	if !ok || key.leased {
+		return false
+	}
+
+	p.remove(key)
+	key.leased = true
+	// the sequence number could be used by the flow transaction
+	key.stale = true
+
+	return true
+}
+
+// Release returns the leased proposal key to the pool, the sequence number is incremented
+// if it was used by the sealed flow transaction.
+func (p *KeyPool) Release(index uint32, used bool) {
+	p.release(index, func(key *poolKey) {
+		if used {
+			key.seqNum++
+			key.failures = 0
+		}
+	})
+}
+
+// Invalidate returns the leased proposal key to the pool, and the sequence number is reconciled
+// with the chain before the key is leased again. It's used if it's not known whether the flow
+// transaction used the sequence number.
+func (p *KeyPool) Invalidate(index uint32) {
+	p.release(index, func(key *poolKey) {
+		key.stale = true
+	})
+}
+
+// Failed returns the leased proposal key to the pool after the flow transaction proposed with it
+// failed the validation, for example due to a sequence number mismatch. The sequence number is
+// reconciled with the chain before the key is leased again, and the key is quarantined if it
+// keeps failing, until the next health check.
+func (p *KeyPool) Failed(index uint32) {
+	p.release(index, func(key *poolKey) {
+		key.stale = true
+		key.failures++
+		if key.failures >= maxKeyFailures && key.quarantine == "" {
+			p.quarantine(key, keyFailures)
+		}
+	})
+}
+
+// Size returns the number of the proposal keys in the pool.
+func (p *KeyPool) Size() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return len(p.keys)
+}
+
+// Status returns the status of each of the proposal keys, sorted by the key index.
+func (p *KeyPool) Status() []KeyStatus {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	statuses := make([]KeyStatus, 0, len(p.keys))
+	for _, key := range p.keys {
+		statuses = append(statuses, KeyStatus{
+			Index:          key.index,
+			PublicKey:      key.signer.PublicKey().String(),
+			SequenceNumber: key.seqNum,
+			Weight:         key.weight,
+			Leased:         key.leased,
+			Quarantine:     key.quarantine,
+			Failures:       key.failures,
+			CheckedAt:      key.checkedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Index < statuses[j].Index
+	})
+
+	return statuses
+}
+
+// CheckHealth checks the keys against the account, quarantining the unhealthy keys and returning
+// the keys which are healthy again into rotation. The sequence numbers of the free keys are
+// reconciled with the chain.
+func (p *KeyPool) CheckHealth(ctx context.Context) error {
+	account, err := p.account(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.check(account)
+	return nil
+}
+
+// CheckOverlap detects the keys used by another gateway instance sharing the COA account, or any
+// other party, by observing whether the sequence numbers of the unused keys advance on the chain
+// over the period. It returns an error naming the overlapping keys, and should be called before
+// the gateway starts accepting transactions.
+func (p *KeyPool) CheckOverlap(ctx context.Context, period time.Duration) error {
+	p.mux.Lock()
+	seqNums := make(map[uint32]uint64, len(p.free))
+	for _, key := range p.free {
+		if !key.stale {
+			seqNums[key.index] = key.seqNum
+		}
+	}
+	p.mux.Unlock()
+
+	select {
+	case <-time.After(period):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	account, err := p.account(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	overlapping := make([]uint32, 0)
+	for _, accountKey := range account.Keys {
+		seqNum, ok := seqNums[accountKey.Index]
+		key := p.keys[accountKey.Index]
+		// the key could be leased and used by the gateway over the period
+		if !ok || key.leased || key.seqNum != seqNum {
+			continue
+		}
+		if accountKey.SequenceNumber > seqNum {
+			overlapping = append(overlapping, accountKey.Index)
+		}
+	}
+
+	if len(overlapping) > 0 {
+		sort.Slice(overlapping, func(i, j int) bool {
+			return overlapping[i] < overlapping[j]
+		})
+		return fmt.Errorf(
+			"sequence numbers of the unused keys %v of account %s advanced, the keys are used by another gateway instance, assign disjoint keys to each instance",
+			overlapping,
+			p.address,
+		)
+	}
+
+	return nil
+}
+
+// healthChecker checks the health of the keys at the interval, until the context is cancelled.
+func (p *KeyPool) healthChecker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.CheckHealth(ctx); err != nil && ctx.Err() == nil {
+				p.logger.Warn().Err(err).Msg("failed to check the health of the keys")
+			}
+		}
+	}
+}
+
+// check updates the health of the keys from the account.
+func (p *KeyPool) check(account *flow.Account) {
+	accountKeys := make(map[uint32]*flow.AccountKey, len(account.Keys))
+	for _, accountKey := range account.Keys {
+		accountKeys[accountKey.Index] = accountKey
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	now := time.Now()
+	healthy := 0
+	for _, key := range p.keys {
+		// the sequence number of the key is first taken from the chain
+		checked := !key.checkedAt.IsZero()
+		key.checkedAt = now
+
+		accountKey, ok := accountKeys[key.index]
+		reason := ""
+		switch {
+		case !ok:
+			reason = keyMissing
+		case accountKey.Revoked:
+			reason = keyRevoked
+		case accountKey.Weight < flow.AccountKeyWeightThreshold:
+			reason = keyInsufficientWeight
+		case !accountKey.PublicKey.Equals(key.signer.PublicKey()):
+			reason = keyPublicKeyMismatch
+		}
+		// the sequence number of the keys without a flow transaction in flight only advances with
+		// the flow transactions proposed by another party, likely another gateway instance
+		if ok && !key.leased && checked && !key.stale && accountKey.SequenceNumber > key.seqNum {
+			p.logger.Error().
+				Uint32("index", key.index).
+				Uint64("local-sequence-number", key.seqNum).
+				Uint64("sequence-number", accountKey.SequenceNumber).
+				Msg("sequence number of unused proposal key advanced, the key could be used by another gateway instance")
+		}
+		// the sequence number on the chain is final for the keys without a flow transaction in flight,
+		// unless it's behind the local one, incremented by a flow transaction sealed after the check
+		if ok && !key.leased && (key.stale || accountKey.SequenceNumber > key.seqNum) {
+			key.seqNum = accountKey.SequenceNumber
+			key.stale = false
+		}
+		if ok {
+			key.weight = accountKey.Weight
+		}
+
+		switch {
+		case reason != "" && key.quarantine == "":
+			p.quarantine(key, reason)
+		case reason != "":
+			// the key stays quarantined, possibly for another reason
+			key.quarantine = reason
+		case key.quarantine != "":
+			// the key is healthy again, and the failures get another chance
+			key.failures = 0
+			p.unquarantine(key)
+		case !key.leased && !p.isFree(key):
+			// the key is new to the pool
+			p.free = append(p.free, key)
+		}
+
+		if key.quarantine == "" {
+			healthy++
+		}
+	}
+
+	p.collector.HealthyKeys(healthy)
+	p.wake()
+}
+
+// quarantine pulls the key out of rotation, the pool lock must be held.
+func (p *KeyPool) quarantine(key *poolKey, reason string) {
+	key.quarantine = reason
+	p.remove(key)
+	p.collector.KeyQuarantined(reason)
+	p.logger.Warn().
+		Uint32("index", key.index).
+		Str("reason", reason).
+		Msg("proposal key quarantined")
+}
+
+// unquarantine returns the healthy key into rotation, the pool lock must be held.
+func (p *KeyPool) unquarantine(key *poolKey) {
+	p.logger.Info().
+		Uint32("index", key.index).
+		Str("reason", key.quarantine).
+		Msg("proposal key returned into rotation")
+	key.quarantine = ""
+	if !key.leased && !p.isFree(key) {
+		p.free = append(p.free, key)
+	}
+}
+
+func (p *KeyPool) release(index uint32, update func(key *poolKey)) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	key, ok := p.keys[index]
+	if !ok || !key.leased {
+		p.logger.Warn().Uint32("index", index).Msg("released proposal key is not leased")
+		return
+	}
+
+	key.leased = false
+	update(key)
+	if key.quarantine == "" {
+		p.free = append(p.free, key)
+		p.wake()
+	}
+}
+
+// indexes returns the sorted indexes of the keys, the pool lock must be held.
+func (p *KeyPool) indexes() []uint32 {
+	indexes := make([]uint32, 0, len(p.keys))
+	for index := range p.keys {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i] < indexes[j]
+	})
+	return indexes
+}
+
+// wake wakes up the submissions waiting for a key, the pool lock must be held.
+func (p *KeyPool) wake() {
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+// remove removes the key from the free keys, the pool lock must be held.
+func (p *KeyPool) remove(key *poolKey) {
+	for i, free := range p.free {
+		if free == key {
+			p.free = append(p.free[:i], p.free[i+1:]...)
+			return
+		}
+	}
+}
+
+// isFree returns whether the key is one of the free keys, the pool lock must be held.
+func (p *KeyPool) isFree(key *poolKey) bool {
+	for _, free := range p.free {
+		if free == key {
+			return true
+		}
+	}
+	return false
+}
+
+// lease reconciles the sequence number of the leased key with the chain if it's stale,
+// and returns the key. The key is released if the sequence number can't be reconciled.
+func (p *KeyPool) lease(ctx context.Context, key *poolKey) (*Key, error) {
+	p.mux.Lock()
+	stale := key.stale
+	leased := &Key{Index: key.index, SequenceNumber: key.seqNum, Signer: key.signer}
+	p.mux.Unlock()
+
+	if !stale {
+		return leased, nil
+	}
+
+	seqNum, err := p.sequenceNumber(ctx, key.index)
+	if err != nil {
+		p.Invalidate(key.index)
+		return nil, err
+	}
+
+	p.mux.Lock()
+	key.seqNum = seqNum
+	key.stale = false
+	p.mux.Unlock()
+
+	leased.SequenceNumber = seqNum
+	return leased, nil
+}
+
+// sequenceNumber returns the sequence number of the key by the index on the chain.
+func (p *KeyPool) sequenceNumber(ctx context.Context, index uint32) (uint64, error) {
+	account, err := p.account(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, accountKey := range account.Keys {
+		if accountKey.Index == index {
+			return accountKey.SequenceNumber, nil
+		}
+	}
+
+	return 0, fmt.Errorf("proposal key with index %d not found on account %s", index, p.address)
+}
+
+// account returns the COA account from the chain.
+func (p *KeyPool) account(ctx context.Context) (*flow.Account, error) {
+	account, err := p.client.GetAccount(ctx, p.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signer info account for address: %s, with: %w", p.address, err)
+	}
+	p.collector.OperatorBalance(account)
+
+	return account, nil
+}
+
+// isKeyAssigned returns whether the key by the index is assigned to the gateway instance, by the
+// key indexes or the partition of the keys.
+func isKeyAssigned(config *config.Config, index uint32) bool {
+	if len(config.COAKeyIndices) > 0 {
+		for _, i := range config.COAKeyIndices {
+			if i == index {
+				return true
+			}
+		}
+		return false
+	}
+	if config.COAKeyPartitionCount > 0 {
+		return index%config.COAKeyPartitionCount == config.COAKeyPartition
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/mocks"
+	"github.com/onflow/flow-go-sdk/crypto"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/metrics"
+)
+
+func Test_KeyPool(t *testing.T) {
+	keys := testKeys(t)[:2]
+	address := flow.HexToAddress("0x01")
+
+	weight := flow.AccountKeyWeightThreshold
+	accountKeys := func(seqNums ...uint64) []*flow.AccountKey {
+		return []*flow.AccountKey{
+			{Index: 0, PublicKey: keys[0].PublicKey(), SequenceNumber: seqNums[0], Weight: weight},
+			{Index: 1, PublicKey: keys[1].PublicKey(), SequenceNumber: seqNums[1], Weight: weight},
+			// the key is registered under multiple indexes
+			{Index: 2, PublicKey: keys[0].PublicKey(), SequenceNumber: seqNums[2], Weight: weight},
+			{Index: 3, PublicKey: keys[1].PublicKey(), Weight: weight, Revoked: true},
+		}
+	}
+	account := func(seqNums ...uint64) *flow.Account {
+		return &flow.Account{Address: address, Keys: accountKeys(seqNums...)}
+	}
+
+	// the number of the keys in rotation, without the revoked one
+	healthy := 3
+
+	newPoolWithConfig := func(t *testing.T, cfg *config.Config) (*KeyPool, *mocks.Client) {
+		mockClient := &mocks.Client{}
+		client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+		require.NoError(t, err)
+
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(5, 7, 9), nil).Once()
+
+		signer, err := NewKeyRotationSigner(keys, crypto.SHA3_256)
+		require.NoError(t, err)
+
+		pool, err := NewKeyPool(context.Background(), client, cfg, signer, metrics.NopCollector, zerolog.Nop())
+		require.NoError(t, err)
+		return pool, mockClient
+	}
+
+	newPool := func(t *testing.T) (*KeyPool, *mocks.Client) {
+		return newPoolWithConfig(t, &config.Config{COAAddress: address})
+	}
+
+	t.Run("concurrent submissions lease distinct keys", func(t *testing.T) {
+		pool, _ := newPool(t)
+		// the revoked key is tracked, but quarantined
+		require.Equal(t, 4, pool.Size())
+
+		leased := make(map[uint32]uint64)
+		for i := 0; i < healthy; i++ {
+			key, err := pool.Lease(context.Background())
+			require.NoError(t, err)
+			leased[key.Index] = key.SequenceNumber
+		}
+		require.Equal(t, map[uint32]uint64{0: 5, 1: 7, 2: 9}, leased)
+
+		// all the keys are leased, so the lease waits for a key to be released
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		_, err := pool.Lease(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		go pool.Release(1, true)
+		key, err := pool.Lease(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), key.Index)
+		// the sequence number is tracked locally
+		require.Equal(t, uint64(8), key.SequenceNumber)
+	})
+
+	t.Run("unused key keeps the sequence number", func(t *testing.T) {
+		pool, _ := newPool(t)
+		for i := 0; i < healthy; i++ {
+			key, err := pool.Lease(context.Background())
+			require.NoError(t, err)
+			if key.Index == 2 {
+				pool.Release(key.Index, false)
+			}
+		}
+
+		key, err := pool.Lease(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint32(2), key.Index)
+		require.Equal(t, uint64(9), key.SequenceNumber)
+	})
+
+	t.Run("invalidated key is reconciled with the chain", func(t *testing.T) {
+		pool, mockClient := newPool(t)
+		require.True(t, pool.Reserve(0))
+		require.False(t, pool.Reserve(0))
+
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(12, 7, 9), nil).Once()
+		pool.Invalidate(0)
+
+		for i := 0; i < healthy; i++ {
+			key, err := pool.Lease(context.Background())
+			require.NoError(t, err)
+			if key.Index == 0 {
+				require.Equal(t, uint64(12), key.SequenceNumber)
+			}
+		}
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("signer not matching the account keys", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+		require.NoError(t, err)
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(5, 7, 9), nil).Once()
+
+		signer, err := crypto.NewInMemorySigner(testKeys(t)[2], crypto.SHA3_256)
+		require.NoError(t, err)
+
+		_, err = NewKeyPool(context.Background(), client, &config.Config{COAAddress: address}, signer, metrics.NopCollector, zerolog.Nop())
+		require.ErrorContains(t, err, "do not match")
+	})
+
+	t.Run("keys assigned to the instance", func(t *testing.T) {
+		pool, _ := newPoolWithConfig(t, &config.Config{COAAddress: address, COAKeyIndices: []uint32{0, 2}})
+		require.Equal(t, 2, pool.Size())
+
+		pool, _ = newPoolWithConfig(t, &config.Config{
+			COAAddress:           address,
+			COAKeyPartition:      1,
+			COAKeyPartitionCount: 2,
+		})
+		indexes := make([]uint32, 0)
+		for _, status := range pool.Status() {
+			indexes = append(indexes, status.Index)
+		}
+		require.Equal(t, []uint32{1, 3}, indexes)
+
+		mockClient := &mocks.Client{}
+		client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+		require.NoError(t, err)
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(5, 7, 9), nil).Once()
+
+		signer, err := NewKeyRotationSigner(keys, crypto.SHA3_256)
+		require.NoError(t, err)
+
+		cfg := &config.Config{COAAddress: address, COAKeyIndices: []uint32{4}}
+		_, err = NewKeyPool(context.Background(), client, cfg, signer, metrics.NopCollector, zerolog.Nop())
+		require.ErrorContains(t, err, "assigned to the gateway instance")
+	})
+
+	t.Run("keys used by another instance", func(t *testing.T) {
+		pool, mockClient := newPool(t)
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(5, 7, 9), nil).Once()
+		require.NoError(t, pool.CheckOverlap(context.Background(), time.Millisecond))
+
+		// the leased key is used by the instance itself
+		require.True(t, pool.Reserve(0))
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(6, 7, 11), nil).Once()
+		err := pool.CheckOverlap(context.Background(), time.Millisecond)
+		require.ErrorContains(t, err, "keys [2] of account")
+	})
+
+	t.Run("unhealthy keys are quarantined until healthy again", func(t *testing.T) {
+		pool, mockClient := newPool(t)
+
+		// the weight of key 1 was lowered, and key 2 was removed from the account
+		unhealthy := accountKeys(5, 7, 9)
+		unhealthy[1].Weight = weight - 1
+		unhealthy = append(unhealthy[:2], unhealthy[3])
+		mockClient.
+			On("GetAccount", mock.Anything, address).
+			Return(&flow.Account{Address: address, Keys: unhealthy}, nil).
+			Once()
+		require.NoError(t, pool.CheckHealth(context.Background()))
+
+		quarantined := make(map[uint32]string)
+		for _, status := range pool.Status() {
+			quarantined[status.Index] = status.Quarantine
+		}
+		require.Equal(t, map[uint32]string{
+			0: "",
+			1: keyInsufficientWeight,
+			2: keyMissing,
+			3: keyRevoked,
+		}, quarantined)
+
+		key, err := pool.Lease(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint32(0), key.Index)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		_, err = pool.Lease(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// the keys are healthy again
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(5, 7, 9), nil).Once()
+		require.NoError(t, pool.CheckHealth(context.Background()))
+
+		leased := make(map[uint32]bool)
+		for i := 0; i < healthy-1; i++ {
+			key, err := pool.Lease(context.Background())
+			require.NoError(t, err)
+			leased[key.Index] = true
+		}
+		require.Equal(t, map[uint32]bool{1: true, 2: true}, leased)
+	})
+
+	t.Run("repeatedly failing key is quarantined", func(t *testing.T) {
+		pool, mockClient := newPool(t)
+
+		for i := 0; i < maxKeyFailures; i++ {
+			require.True(t, pool.Reserve(1))
+			pool.Failed(1)
+		}
+
+		status := pool.Status()[1]
+		require.Equal(t, keyFailures, status.Quarantine)
+		require.Equal(t, maxKeyFailures, status.Failures)
+
+		leased := make(map[uint32]bool)
+		for i := 0; i < healthy-1; i++ {
+			key, err := pool.Lease(context.Background())
+			require.NoError(t, err)
+			leased[key.Index] = true
+		}
+		require.Equal(t, map[uint32]bool{0: true, 2: true}, leased)
+
+		// the health check returns the key into rotation
+		mockClient.On("GetAccount", mock.Anything, address).Return(account(5, 8, 9), nil).Once()
+		require.NoError(t, pool.CheckHealth(context.Background()))
+		status = pool.Status()[1]
+		require.Empty(t, status.Quarantine)
+		require.Zero(t, status.Failures)
+
+		key, err := pool.Lease(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), key.Index)
+		require.Equal(t, uint64(8), key.SequenceNumber)
+	})
+}
@@ -0,0 +1,142 @@
+package requester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/go-ethereum/accounts/keystore"
+)
+
+// keystoreVersion is the version of the keystore format, following the Ethereum V3 keystore.
+const keystoreVersion = 3
+
+// keystoreJSON is the encrypted keystore file of a COA key. It follows the Ethereum V3 keystore,
+// with the private key encrypted with AES-128-CTR using a key derived from the passphrase with
+// scrypt. Instead of the Ethereum address, it holds the signature algorithm and the public key,
+// since the COA keys are Flow account keys.
+type keystoreJSON struct {
+	Version            int                 `json:"version"`
+	ID                 string              `json:"id"`
+	SignatureAlgorithm string              `json:"signatureAlgorithm"`
+	PublicKey          string              `json:"publicKey"`
+	Crypto             keystore.CryptoJSON `json:"crypto"`
+}
+
+// EncryptKeystore encrypts the private key into a keystore file with the passphrase, using the
+// scrypt parameters, for example keystore.StandardScryptN and keystore.StandardScryptP.
+func EncryptKeystore(key crypto.PrivateKey, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	cryptoJSON, err := keystore.EncryptDataV3(key.Encode(), []byte(passphrase), scryptN, scryptP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt the key: %w", err)
+	}
+
+	return json.MarshalIndent(keystoreJSON{
+		Version:            keystoreVersion,
+		ID:                 uuid.NewString(),
+		SignatureAlgorithm: key.Algorithm().String(),
+		PublicKey:          key.PublicKey().String(),
+		Crypto:             cryptoJSON,
+	}, "", "  ")
+}
+
+// DecryptKeystore decrypts the private key from the keystore file with the passphrase.
+func DecryptKeystore(keyJSON []byte, passphrase string) (crypto.PrivateKey, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(keyJSON, &ks); err != nil {
+		return nil, fmt.Errorf("failed to parse the keystore: %w", err)
+	}
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("keystore version %d not supported, expected version %d", ks.Version, keystoreVersion)
+	}
+
+	sigAlgo := crypto.StringToSignatureAlgorithm(ks.SignatureAlgorithm)
+	if sigAlgo == crypto.UnknownSignatureAlgorithm {
+		return nil, fmt.Errorf("invalid keystore signature algorithm: %s", ks.SignatureAlgorithm)
+	}
+
+	encoded, err := keystore.DecryptDataV3(ks.Crypto, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the keystore: %w", err)
+	}
+
+	key, err := crypto.DecodePrivateKey(sigAlgo, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore private key: %w", err)
+	}
+	if ks.PublicKey != "" && key.PublicKey().String() != ks.PublicKey {
+		return nil, fmt.Errorf("keystore private key doesn't match the public key: %s", ks.PublicKey)
+	}
+
+	return key, nil
+}
+
+// NewKeystoreSigner creates the signer of the COA keys decrypted from the keystore files with
+// the passphrase. A single key is signed with in memory, and multiple keys with a key-rotation
+// signer, so each key is leased separately from the key pool.
+func NewKeystoreSigner(
+	files []string,
+	passphrase string,
+	hashAlgo crypto.HashAlgorithm,
+) (crypto.Signer, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no keystore files provided")
+	}
+
+	keys := make([]crypto.PrivateKey, len(files))
+	for i, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the keystore file %s: %w", file, err)
+		}
+
+		key, err := DecryptKeystore(raw, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the keystore file %s: %w", file, err)
+		}
+		keys[i] = key
+	}
+
+	if len(keys) == 1 {
+		return crypto.NewInMemorySigner(keys[0], hashAlgo)
+	}
+
+	return NewKeyRotationSigner(keys, hashAlgo)
+}
+
+// ReadKeystorePassphrase reads the passphrase of the keystore files either from the environment
+// variable or the file descriptor, so the passphrase isn't exposed in the process arguments.
+// The environment variable is unset once read, so it's not inherited by the child processes.
+func ReadKeystorePassphrase(env string, fd int) (string, error) {
+	switch {
+	case env != "" && fd >= 0:
+		return "", fmt.Errorf("passphrase can't be read both from environment variable and file descriptor")
+	case env != "":
+		passphrase, ok := os.LookupEnv(env)
+		if !ok {
+			return "", fmt.Errorf("passphrase environment variable %s is not set", env)
+		}
+		if err := os.Unsetenv(env); err != nil {
+			return "", fmt.Errorf("failed to unset passphrase environment variable %s: %w", env, err)
+		}
+		return passphrase, nil
+	case fd >= 0:
+		file := os.NewFile(uintptr(fd), "passphrase")
+		if file == nil {
+			return "", fmt.Errorf("invalid passphrase file descriptor %d", fd)
+		}
+		defer file.Close()
+
+		raw, err := io.ReadAll(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase from file descriptor %d: %w", fd, err)
+		}
+		return strings.TrimRight(string(raw), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("passphrase environment variable or file descriptor must be provided")
+	}
+}
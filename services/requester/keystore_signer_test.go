@@ -0,0 +1,86 @@
+package requester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/go-ethereum/accounts/keystore"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeystoreSigner(t *testing.T) {
+	keys := testKeys(t)[:2]
+
+	writeKeystore := func(t *testing.T, key crypto.PrivateKey, passphrase string) string {
+		keyJSON, err := EncryptKeystore(key, passphrase, keystore.LightScryptN, keystore.LightScryptP)
+		require.NoError(t, err)
+
+		file := filepath.Join(t.TempDir(), "key.json")
+		require.NoError(t, os.WriteFile(file, keyJSON, 0600))
+		return file
+	}
+
+	t.Run("single key", func(t *testing.T) {
+		file := writeKeystore(t, keys[0], "secret")
+
+		signer, err := NewKeystoreSigner([]string{file}, "secret", crypto.SHA3_256)
+		require.NoError(t, err)
+		require.True(t, signer.PublicKey().Equals(keys[0].PublicKey()))
+
+		hasher, err := crypto.NewHasher(crypto.SHA3_256)
+		require.NoError(t, err)
+		sig, err := signer.Sign([]byte("foo"))
+		require.NoError(t, err)
+		valid, err := keys[0].PublicKey().Verify(sig, []byte("foo"), hasher)
+		require.NoError(t, err)
+		require.True(t, valid)
+	})
+
+	t.Run("multiple keys are rotated", func(t *testing.T) {
+		files := []string{writeKeystore(t, keys[0], "secret"), writeKeystore(t, keys[1], "secret")}
+
+		signer, err := NewKeystoreSigner(files, "secret", crypto.SHA3_256)
+		require.NoError(t, err)
+
+		multi, ok := signer.(MultiKeySigner)
+		require.True(t, ok)
+		signers := multi.Signers()
+		require.Len(t, signers, 2)
+		for i, s := range signers {
+			require.True(t, s.PublicKey().Equals(keys[i].PublicKey()))
+		}
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		file := writeKeystore(t, keys[0], "secret")
+
+		_, err := NewKeystoreSigner([]string{file}, "wrong", crypto.SHA3_256)
+		require.ErrorIs(t, err, keystore.ErrDecrypt)
+	})
+
+	t.Run("passphrase from environment variable", func(t *testing.T) {
+		t.Setenv("COA_KEYSTORE_PASSWORD", "secret")
+
+		passphrase, err := ReadKeystorePassphrase("COA_KEYSTORE_PASSWORD", -1)
+		require.NoError(t, err)
+		require.Equal(t, "secret", passphrase)
+
+		// the variable is unset once read
+		_, ok := os.LookupEnv("COA_KEYSTORE_PASSWORD")
+		require.False(t, ok)
+	})
+
+	t.Run("passphrase from file descriptor", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		_, err = w.WriteString("secret\n")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		passphrase, err := ReadKeystorePassphrase("", int(r.Fd()))
+		require.NoError(t, err)
+		require.Equal(t, "secret", passphrase)
+	})
+}
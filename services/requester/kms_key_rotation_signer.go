@@ -11,7 +11,7 @@ import (
 	"github.com/rs/zerolog"
 )
 
-var _ crypto.Signer = &KMSKeyRotationSigner{}
+var _ MultiKeySigner = &KMSKeyRotationSigner{}
 
 // KMSKeyRotationSigner is a crypto signer that contains a pool of
 // `crypto.Signer`[1] objects, each of which is tied to a Cloud KMS
@@ -120,3 +120,12 @@ func (s *KMSKeyRotationSigner) PublicKey() crypto.PublicKey {
 	// that already used it is not executed and thus the key would be incremented.
 	return s.kmsSigners[s.index].PublicKey()
 }
+
+// Signers returns the signers of each of the Cloud KMS keys.
+func (s *KMSKeyRotationSigner) Signers() []crypto.Signer {
+	signers := make([]crypto.Signer, len(s.kmsSigners))
+	for i, signer := range s.kmsSigners {
+		signers[i] = signer
+	}
+	return signers
+}
@@ -0,0 +1,339 @@
+package requester
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/fvm/evm"
+	"github.com/onflow/flow-go/fvm/evm/emulator"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	"github.com/onflow/flow-go/fvm/evm/handler"
+	offchain "github.com/onflow/flow-go/fvm/evm/offchain/storage"
+	"github.com/onflow/flow-go/fvm/evm/precompiles"
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/core/vm"
+	gethCrypto "github.com/onflow/go-ethereum/crypto"
+	"github.com/onflow/go-ethereum/eth/tracers"
+	"github.com/onflow/go-ethereum/eth/tracers/logger"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+// errLocalArchCall is returned by the Cadence Arch functions which
+// require the Cadence runtime and can't be executed locally.
+var errLocalArchCall = errors.New("cadence arch function is not supported in local execution")
+
+// localExecutor executes EVM transactions in-process using the emulator, on
+// top of the EVM storage account registers at a given Cadence height.
+//
+// All the state changes are kept in memory and never leave the executor.
+type localExecutor struct {
+	storage       *offchain.EphemeralStorage
+	chainID       flow.ChainID
+	rootAddress   flow.Address
+	cadenceHeight uint64
+	// unsupportedArchCall is set if a Cadence Arch function which
+	// can't be executed locally was called during the execution.
+	unsupportedArchCall bool
+}
+
+func newLocalExecutor(
+	ledger atree.Ledger,
+	chainID flow.ChainID,
+	cadenceHeight uint64,
+) *localExecutor {
+	return &localExecutor{
+		storage:       offchain.NewEphemeralStorage(offchain.NewReadOnlyStorage(ledger)),
+		chainID:       chainID,
+		rootAddress:   evm.StorageAccountAddress(chainID),
+		cadenceHeight: cadenceHeight,
+	}
+}
+
+// executorAt returns a local executor on top of the state at the given EVM height.
+func (e *EVM) executorAt(evmHeight int64) (*localExecutor, error) {
+	ledger, cadenceHeight, err := e.ledgerAt(evmHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLocalExecutor(ledger, e.config.FlowNetworkID, cadenceHeight), nil
+}
+
+// blockContext builds the block context the same way the EVM contract does
+// when executing transactions, which is from the current block proposal.
+func (l *localExecutor) blockContext() (evmTypes.BlockContext, error) {
+	proposal, err := l.blockProposal()
+	if err != nil {
+		return evmTypes.BlockContext{}, err
+	}
+
+	blockHashes, err := handler.NewBlockHashList(
+		l.storage,
+		l.rootAddress,
+		handler.BlockHashListCapacity,
+	)
+	if err != nil {
+		return evmTypes.BlockContext{}, fmt.Errorf("failed to load block hash list: %w", err)
+	}
+
+	return evmTypes.BlockContext{
+		ChainID:                evmTypes.EVMChainIDFromFlowChainID(l.chainID),
+		BlockNumber:            proposal.Height,
+		BlockTimestamp:         proposal.Timestamp,
+		DirectCallBaseGasUsage: evmTypes.DefaultDirectCallBaseGasUsage,
+		DirectCallGasPrice:     evmTypes.DefaultDirectCallGasPrice,
+		GetHashFunc: func(n uint64) common.Hash {
+			_, hash, err := blockHashes.BlockHashByHeight(n)
+			if err != nil {
+				return common.Hash{}
+			}
+			return hash
+		},
+		ExtraPrecompiledContracts: []evmTypes.PrecompiledContract{l.archContract()},
+		Random:                    proposal.PrevRandao,
+		TxCountSoFar:              uint(len(proposal.TxHashes)),
+		TotalGasUsedSoFar:         proposal.TotalGasUsed,
+		GasFeeCollector:           evmTypes.CoinbaseAddress,
+	}, nil
+}
+
+// blockProposal loads the current block proposal, if no proposal is stored
+// it is constructed from the latest executed block.
+func (l *localExecutor) blockProposal() (*evmTypes.BlockProposal, error) {
+	data, err := l.storage.GetValue(l.rootAddress[:], []byte(handler.BlockStoreLatestBlockProposalKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block proposal: %w", err)
+	}
+	if len(data) != 0 {
+		return evmTypes.NewBlockProposalFromBytes(data)
+	}
+
+	latest := evmTypes.GenesisBlock(l.chainID)
+	data, err = l.storage.GetValue(l.rootAddress[:], []byte(handler.BlockStoreLatestBlockKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest block: %w", err)
+	}
+	if len(data) != 0 {
+		latest, err = evmTypes.NewBlockFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parentHash, err := latest.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	return evmTypes.NewBlockProposal(
+		parentHash,
+		latest.Height+1,
+		uint64(time.Now().Unix()),
+		latest.TotalSupply,
+		common.Hash{},
+	), nil
+}
+
+// archContract returns the Cadence Arch precompiled contract, only the functions
+// that don't require the Cadence runtime are supported.
+func (l *localExecutor) archContract() evmTypes.PrecompiledContract {
+	return precompiles.ArchContract(
+		handler.NewAddressAllocator().AllocatePrecompileAddress(1),
+		func() (uint64, error) {
+			return l.cadenceHeight, nil
+		},
+		func(*evmTypes.COAOwnershipProofInContext) (bool, error) {
+			l.unsupportedArchCall = true
+			return false, errLocalArchCall
+		},
+		func(uint64) ([]byte, error) {
+			l.unsupportedArchCall = true
+			return nil, errLocalArchCall
+		},
+		func() (uint64, error) {
+			b := make([]byte, 8)
+			if _, err := rand.Read(b); err != nil {
+				return 0, err
+			}
+			return binary.BigEndian.Uint64(b), nil
+		},
+	)
+}
+
+// precompiles returns the addresses of all the precompiled
+// contracts active in the given block context.
+func (l *localExecutor) precompiles(ctx evmTypes.BlockContext) []common.Address {
+	cfg := emulator.NewConfig(
+		emulator.WithChainID(ctx.ChainID),
+		emulator.WithBlockNumber(new(big.Int).SetUint64(ctx.BlockNumber)),
+		emulator.WithBlockTime(ctx.BlockTimestamp),
+	)
+	rules := cfg.ChainConfig.Rules(cfg.BlockContext.BlockNumber, true, ctx.BlockTimestamp)
+
+	addresses := vm.ActivePrecompiles(rules)
+	for _, pc := range ctx.ExtraPrecompiledContracts {
+		addresses = append(addresses, pc.Address().ToCommon())
+	}
+	return addresses
+}
+
+// createAccessList executes the unsigned transaction with an access list tracer,
+// using the access list produced by the previous execution, until the produced
+// access list doesn't change anymore.
+func (l *localExecutor) createAccessList(
+	ctx context.Context,
+	tx *types.Transaction,
+	from common.Address,
+) (types.AccessList, *evmTypes.Result, error) {
+	blockContext, err := l.blockContext()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the created contract address is excluded from the access list the same as the recipient
+	var to common.Address
+	if tx.To() != nil {
+		to = *tx.To()
+	} else {
+		stateDB, err := state.NewStateDB(l.storage, l.rootAddress)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = gethCrypto.CreateAddress(from, stateDB.GetNonce(from))
+		if err := stateDB.Error(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	precompiles := l.precompiles(blockContext)
+	prevTracer := logger.NewAccessListTracer(tx.AccessList(), from, to, precompiles)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		accessList := prevTracer.AccessList()
+		tracer := logger.NewAccessListTracer(accessList, from, to, precompiles)
+		blockContext.Tracer = &tracers.Tracer{Hooks: tracer.Hooks()}
+
+		accessListTx := types.NewTx(&types.AccessListTx{
+			ChainID:    blockContext.ChainID,
+			Nonce:      tx.Nonce(),
+			GasPrice:   tx.GasPrice(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: accessList,
+		})
+
+		res, err := l.dryRunWithContext(blockContext, accessListTx, from)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.ValidationError != nil {
+			return nil, nil, errs.NewFailedTransactionError(res.ValidationError.Error())
+		}
+
+		if tracer.Equal(prevTracer) {
+			return accessList, res, nil
+		}
+		prevTracer = tracer
+	}
+}
+
+// dryRunWithContext executes the unsigned transaction as the provided sender without
+// persisting any state changes, the same way as the EVM contract dry run does.
+func (l *localExecutor) dryRunWithContext(
+	ctx evmTypes.BlockContext,
+	tx *types.Transaction,
+	from common.Address,
+) (*evmTypes.Result, error) {
+	blockView, err := emulator.NewEmulator(l.storage, l.rootAddress).NewBlockView(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := blockView.DryRunTransaction(tx, from)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, evmTypes.ErrUnexpectedEmptyResult
+	}
+	// the result doesn't reflect the execution on Flow, so it can't be used
+	if l.unsupportedArchCall {
+		return nil, fmt.Errorf("%w: %w", errs.ErrFailedTransaction, errLocalArchCall)
+	}
+
+	return res, nil
+}
+
+// fallbackToScript returns true if the local execution failed for a reason the
+// script execution doesn't fail for, such as failing to read the remote registers
+// or calling Cadence Arch functions that require the Cadence runtime.
+func fallbackToScript(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, errLocalArchCall) {
+		return true
+	}
+	return !errors.Is(err, errs.ErrHeightOutOfRange) && !isResultError(err)
+}
+
+// localBalance returns the balance of the address read from the EVM state registers.
+func (e *EVM) localBalance(address common.Address, evmHeight int64) (*big.Int, error) {
+	stateDB, err := e.stateAt(evmHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := stateDB.GetBalance(address)
+	if err := stateDB.Error(); err != nil {
+		return nil, err
+	}
+
+	return balance.ToBig(), nil
+}
+
+// localNonce returns the nonce of the address read from the EVM state registers.
+func (e *EVM) localNonce(address common.Address, evmHeight int64) (uint64, error) {
+	stateDB, err := e.stateAt(evmHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	nonce := stateDB.GetNonce(address)
+	if err := stateDB.Error(); err != nil {
+		return 0, err
+	}
+
+	return nonce, nil
+}
+
+// localCode returns the code of the address read from the EVM state registers.
+func (e *EVM) localCode(address common.Address, evmHeight int64) ([]byte, error) {
+	stateDB, err := e.stateAt(evmHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	code := stateDB.GetCode(address)
+	if err := stateDB.Error(); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
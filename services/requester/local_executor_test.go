@@ -0,0 +1,265 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/evm"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	"github.com/onflow/flow-go/fvm/evm/handler"
+	"github.com/onflow/flow-go/fvm/evm/precompiles"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+func Test_LocalExecutorAccessList(t *testing.T) {
+	chainID := flowGo.Emulator
+	from := common.HexToAddress("0x1000")
+	contract := common.HexToAddress("0x2000")
+	other := common.HexToAddress("0x3000")
+
+	code := testContractCode(other)
+
+	executor := newTestExecutor(t, chainID, from, contract, code)
+
+	tx := types.NewTx(&types.LegacyTx{
+		To:       &contract,
+		Value:    big.NewInt(0),
+		Gas:      1_000_000,
+		GasPrice: big.NewInt(0),
+	})
+
+	accessList, res, err := executor.createAccessList(context.Background(), tx, from)
+	require.NoError(t, err)
+	require.NoError(t, res.VMError)
+
+	assert.Equal(t, common.HexToHash("0x2a").Bytes(), res.ReturnedData)
+	assert.ElementsMatch(t, types.AccessList{
+		{Address: contract, StorageKeys: []common.Hash{common.HexToHash("0x01")}},
+		{Address: other, StorageKeys: []common.Hash{}},
+	}, accessList)
+	assert.Greater(t, res.GasConsumed, uint64(21_000))
+}
+
+func Test_LocalExecutorOverrides(t *testing.T) {
+	chainID := flowGo.Emulator
+	from := common.HexToAddress("0x1000")
+	contract := common.HexToAddress("0x2000")
+	other := common.HexToAddress("0x3000")
+	slot := common.HexToHash("0x01")
+
+	tx := types.NewTx(&types.LegacyTx{
+		To:       &contract,
+		Value:    big.NewInt(0),
+		Gas:      1_000_000,
+		GasPrice: big.NewInt(0),
+	})
+
+	t.Run("state diff override", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		err := executor.applyStateOverrides(models.StateOverride{
+			contract: {StateDiff: &map[common.Hash]common.Hash{slot: common.HexToHash("0x07")}},
+		})
+		require.NoError(t, err)
+
+		ctx, err := executor.blockContext()
+		require.NoError(t, err)
+		res, err := executor.dryRunWithContext(ctx, tx, from)
+		require.NoError(t, err)
+		assert.Equal(t, common.HexToHash("0x07").Bytes(), res.ReturnedData)
+	})
+
+	t.Run("code override of non-existing account", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		// returns the balance of the caller
+		code := []byte{0x33, 0x31, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3}
+		balance := (*hexutil.Big)(big.NewInt(12345))
+		target := common.HexToAddress("0x4000")
+		err := executor.applyStateOverrides(models.StateOverride{
+			target: {Code: (*hexutil.Bytes)(&code)},
+			from:   {Balance: &balance},
+		})
+		require.NoError(t, err)
+
+		ctx, err := executor.blockContext()
+		require.NoError(t, err)
+		call := types.NewTx(&types.LegacyTx{
+			To:       &target,
+			Value:    big.NewInt(0),
+			Gas:      1_000_000,
+			GasPrice: big.NewInt(0),
+		})
+		res, err := executor.dryRunWithContext(ctx, call, from)
+		require.NoError(t, err)
+		assert.Equal(t, common.BigToHash(big.NewInt(12345)).Bytes(), res.ReturnedData)
+	})
+
+	t.Run("state and state diff are exclusive", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		slots := map[common.Hash]common.Hash{slot: {}}
+		err := executor.applyStateOverrides(models.StateOverride{
+			contract: {State: &slots, StateDiff: &slots},
+		})
+		require.ErrorIs(t, err, errs.ErrInvalid)
+	})
+
+	t.Run("block overrides", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		ctx, err := executor.blockContext()
+		require.NoError(t, err)
+
+		number := (*hexutil.Big)(big.NewInt(500))
+		timestamp := hexutil.Uint64(1000)
+		err = applyBlockOverrides(&ctx, &models.BlockOverrides{Number: number, Time: &timestamp})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(500), ctx.BlockNumber)
+		assert.Equal(t, uint64(1000), ctx.BlockTimestamp)
+
+		gasLimit := hexutil.Uint64(1)
+		err = applyBlockOverrides(&ctx, &models.BlockOverrides{GasLimit: &gasLimit})
+		require.ErrorIs(t, err, errs.ErrInvalid)
+	})
+}
+
+func Test_LocalExecutorArchCalls(t *testing.T) {
+	chainID := flowGo.Emulator
+	from := common.HexToAddress("0x1000")
+	contract := common.HexToAddress("0x2000")
+	arch := handler.NewAddressAllocator().AllocatePrecompileAddress(1).ToCommon()
+
+	call := func(data []byte) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{
+			To:       &arch,
+			Value:    big.NewInt(0),
+			Gas:      1_000_000,
+			GasPrice: big.NewInt(0),
+			Data:     data,
+		})
+	}
+
+	t.Run("flow block height", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(arch))
+		ctx, err := executor.blockContext()
+		require.NoError(t, err)
+
+		selector := precompiles.FlowBlockHeightFuncSig
+		res, err := executor.dryRunWithContext(ctx, call(selector[:]), from)
+		require.NoError(t, err)
+		require.NoError(t, res.VMError)
+		assert.Equal(t, common.BigToHash(big.NewInt(100)).Bytes(), res.ReturnedData)
+	})
+
+	t.Run("random source is not supported", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(arch))
+		ctx, err := executor.blockContext()
+		require.NoError(t, err)
+
+		selector := precompiles.RandomSourceFuncSig
+		data := append(selector[:], common.BigToHash(big.NewInt(1)).Bytes()...)
+		_, err = executor.dryRunWithContext(ctx, call(data), from)
+		require.ErrorIs(t, err, errLocalArchCall)
+		require.ErrorIs(t, err, errs.ErrFailedTransaction)
+		assert.True(t, fallbackToScript(err))
+	})
+}
+
+func Test_FallbackToScript(t *testing.T) {
+	assert.False(t, fallbackToScript(nil))
+	assert.False(t, fallbackToScript(errs.NewHeightOutOfRangeError(1)))
+	assert.False(t, fallbackToScript(errs.NewFailedTransactionError("out of gas")))
+	assert.False(t, fallbackToScript(errs.NewRevertError(nil)))
+	assert.False(t, fallbackToScript(errs.NewInvalidOverrideError("invalid")))
+	assert.True(t, fallbackToScript(fmt.Errorf("%w: %w", errs.ErrFailedTransaction, errLocalArchCall)))
+	assert.True(t, fallbackToScript(errors.New("failed to get register")))
+}
+
+// testContractCode returns the code which reads the balance of the provided
+// address, loads the storage slot 1 and returns the loaded value.
+func testContractCode(address common.Address) []byte {
+	code := append([]byte{0x73}, address.Bytes()...)
+	return append(code,
+		0x31, 0x50, // BALANCE POP
+		0x60, 0x01, 0x54, // PUSH1 1 SLOAD
+		0x60, 0x00, 0x52, // PUSH1 0 MSTORE
+		0x60, 0x20, 0x60, 0x00, 0xf3, // PUSH1 32 PUSH1 0 RETURN
+	)
+}
+
+// newTestExecutor creates a local executor on top of an in-memory state,
+// which contains a funded account and a contract with the slot 1 set.
+func newTestExecutor(
+	t *testing.T,
+	chainID flowGo.ChainID,
+	from common.Address,
+	contract common.Address,
+	code []byte,
+) *localExecutor {
+	root := evm.StorageAccountAddress(chainID)
+	ledger := newTestValueStore()
+	err := ledger.SetValue(root[:], []byte(flowGo.AccountStatusKey), environment.NewAccountStatus().ToBytes())
+	require.NoError(t, err)
+
+	stateDB, err := state.NewStateDB(ledger, root)
+	require.NoError(t, err)
+	stateDB.CreateAccount(from)
+	stateDB.AddBalance(from, uint256.NewInt(1_000_000), 0)
+	stateDB.CreateAccount(contract)
+	stateDB.SetCode(contract, code)
+	stateDB.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x2a"))
+	_, err = stateDB.Commit(true)
+	require.NoError(t, err)
+
+	return newLocalExecutor(ledger, chainID, 100)
+}
+
+// testValueStore is an in-memory ledger of the registers.
+type testValueStore struct {
+	values      map[string][]byte
+	slabIndexes map[string]atree.SlabIndex
+}
+
+var _ atree.Ledger = &testValueStore{}
+
+func newTestValueStore() *testValueStore {
+	return &testValueStore{
+		values:      make(map[string][]byte),
+		slabIndexes: make(map[string]atree.SlabIndex),
+	}
+}
+
+func (s *testValueStore) GetValue(owner, key []byte) ([]byte, error) {
+	return s.values[string(owner)+"~"+string(key)], nil
+}
+
+func (s *testValueStore) SetValue(owner, key, value []byte) error {
+	s.values[string(owner)+"~"+string(key)] = value
+	return nil
+}
+
+func (s *testValueStore) ValueExists(owner, key []byte) (bool, error) {
+	return len(s.values[string(owner)+"~"+string(key)]) > 0, nil
+}
+
+func (s *testValueStore) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
+	index := s.slabIndexes[string(owner)]
+	index = index.Next()
+	s.slabIndexes[string(owner)] = index
+	return index, nil
+}
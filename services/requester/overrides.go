@@ -0,0 +1,149 @@
+package requester
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+	"github.com/onflow/flow-go/fvm/evm/emulator/state"
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
+	"github.com/onflow/go-ethereum/common"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/crypto"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+// applyStateOverrides applies the provided account overrides to the executor
+// state, the changes are only kept in the executor memory.
+func (l *localExecutor) applyStateOverrides(overrides models.StateOverride) error {
+	for address, account := range overrides {
+		if account.State != nil && account.StateDiff != nil {
+			return errs.NewInvalidOverrideError(
+				fmt.Sprintf("account %s has both 'state' and 'stateDiff'", address.Hex()),
+			)
+		}
+
+		view, err := state.NewBaseView(l.storage, l.rootAddress)
+		if err != nil {
+			return err
+		}
+
+		balance, err := view.GetBalance(address)
+		if err != nil {
+			return err
+		}
+		nonce, err := view.GetNonce(address)
+		if err != nil {
+			return err
+		}
+		code, err := view.GetCode(address)
+		if err != nil {
+			return err
+		}
+		codeHash, err := view.GetCodeHash(address)
+		if err != nil {
+			return err
+		}
+
+		// a non-existing account has an empty code hash
+		exists := codeHash != (common.Hash{})
+		if !exists {
+			codeHash = gethTypes.EmptyCodeHash
+		}
+
+		if account.Balance != nil {
+			override := (*big.Int)(*account.Balance)
+			if override == nil || override.Sign() < 0 || override.BitLen() > 256 {
+				return errs.NewInvalidOverrideError(
+					fmt.Sprintf("account %s has an invalid balance", address.Hex()),
+				)
+			}
+			balance = uint256.MustFromBig(override)
+		}
+		if account.Nonce != nil {
+			nonce = uint64(*account.Nonce)
+		}
+		if account.Code != nil {
+			code = *account.Code
+			codeHash = gethTypes.EmptyCodeHash
+			if len(code) > 0 {
+				codeHash = crypto.Keccak256Hash(code)
+			}
+		}
+
+		if !exists || account.Balance != nil || account.Nonce != nil || account.Code != nil {
+			if err := view.UpdateAccount(address, balance, nonce, code, codeHash); err != nil {
+				return err
+			}
+		}
+
+		slots := account.StateDiff
+		if account.State != nil {
+			// state replaces the whole account storage
+			if err := view.PurgeAllSlotsOfAnAccount(address); err != nil {
+				return err
+			}
+			slots = account.State
+		}
+		if slots != nil {
+			for key, value := range *slots {
+				slot := evmTypes.SlotAddress{Address: address, Key: key}
+				if err := view.UpdateSlot(slot, value); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := view.Commit(); err != nil {
+			return fmt.Errorf("failed to apply overrides of account %s: %w", address.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+// applyBlockOverrides applies the provided block overrides to the block context.
+// Overrides of the block fields that Flow EVM doesn't use are rejected.
+func applyBlockOverrides(ctx *evmTypes.BlockContext, overrides *models.BlockOverrides) error {
+	if overrides == nil {
+		return nil
+	}
+
+	unsupported := []struct {
+		field string
+		set   bool
+	}{
+		{"difficulty", overrides.Difficulty != nil},
+		{"gasLimit", overrides.GasLimit != nil},
+		{"baseFee", overrides.BaseFee != nil},
+		{"blobBaseFee", overrides.BlobBaseFee != nil},
+	}
+	for _, u := range unsupported {
+		if u.set {
+			return errs.NewInvalidOverrideError(
+				fmt.Sprintf("block override of '%s' is not supported", u.field),
+			)
+		}
+	}
+
+	if overrides.Number != nil {
+		number := overrides.Number.ToInt()
+		if !number.IsUint64() {
+			return errs.NewInvalidOverrideError("block number override is out of range")
+		}
+		ctx.BlockNumber = number.Uint64()
+	}
+	if overrides.Time != nil {
+		ctx.BlockTimestamp = uint64(*overrides.Time)
+	}
+	if overrides.Coinbase != nil {
+		ctx.GasFeeCollector = evmTypes.NewAddress(*overrides.Coinbase)
+	}
+	if overrides.Random != nil {
+		ctx.Random = *overrides.Random
+	}
+
+	return nil
+}
@@ -2,97 +2,860 @@ package requester
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/evm/events"
+	"github.com/onflow/go-ethereum/common"
 	gethTypes "github.com/onflow/go-ethereum/core/types"
 	"github.com/rs/zerolog"
 	"github.com/sethvargo/go-retry"
 
+	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/metrics"
 	"github.com/onflow/flow-evm-gateway/models"
 	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/storage"
 )
 
 const (
 	evmErrorRegex = `evm_error=(.*)\n`
+	// the batch_run.cdc script prefixes the error of each invalid transaction with its index
+	evmBatchErrorRegex = `evm_tx_index=(\d+) (evm_error=.*\n)`
+
+	feesDeductedQualifiedIdentifier = "FlowFees.FeesDeducted"
+
+	// the reasons a flow transaction is rebuilt and resubmitted
+	resubmitExpired          = "expired"
+	resubmitSequenceMismatch = "sequence_mismatch"
+	resubmitInvalidBatch     = "invalid_batch_transaction"
 )
 
-// todo this is a simple implementation of the transaction pool that is mostly used
-// to track the status of submitted transaction, but transactions will always be submitted
-// right away, future improvements can make it so the transactions are collected in the pool
-// and after submitted based on different strategies.
+// ufix64ToAttoFlow converts the UFix64 Flow amount with 8 decimals to attoFlow with 18 decimals.
+var ufix64ToAttoFlow = big.NewInt(10_000_000_000)
 
+// sealTimeout is the duration the Flow transaction is awaited to be sealed.
+const sealTimeout = time.Minute * 3
+
+// TxPool tracks the status of the submitted transactions. The transactions are either
+// submitted right away, each in its own flow transaction, or collected in batches which
+// are submitted in a single flow transaction. The submissions are persisted, so the
+// submissions which are not yet sealed can be tracked again after a restart.
 type TxPool struct {
 	logger      zerolog.Logger
 	client      *CrossSporkClient
+	config      *config.Config
 	pool        *sync.Map
+	submissions storage.SubmissionIndexer
 	txPublisher *models.Publisher[*gethTypes.Transaction]
-	// todo add methods to inspect transaction pool state
+	gasPrice    GasPriceOracle
+	collector   metrics.Collector
+	// keys are the proposal keys released once the flow transactions are sealed,
+	// it's nil if the keys are not leased
+	keys *KeyPool
+	// queue holds the transactions with a nonce ahead of the next nonce of the sender,
+	// it's nil if the queueing is disabled
+	queue *txQueue
+
+	batchMux sync.Mutex
+	batch    []*batchedTx
+	// batchID identifies the batch being collected
+	batchID uint64
+}
+
+// BatchBuilder builds the flow transaction executing the batch of EVM transactions.
+type BatchBuilder func(ctx context.Context, evmTxs []*gethTypes.Transaction) (*flow.Transaction, error)
+
+// Rebuilder rebuilds the flow transaction executing the EVM transactions, with a fresh reference
+// block and proposal key, after the previous flow transaction expired, had a sequence number
+// mismatch, or was reverted by an invalid EVM transaction of its batch. The EVM transactions with a nonce used by an executed transaction in the meantime are
+// left out of the flow transaction and returned as executed, and no flow transaction is built
+// if all of them were executed.
+type Rebuilder func(
+	ctx context.Context,
+	evmTxs []*gethTypes.Transaction,
+) (*flow.Transaction, map[common.Hash]bool, error)
+
+// batchedTx is an EVM transaction collected into a batch, which awaits its result.
+type batchedTx struct {
+	tx     *gethTypes.Transaction
+	result chan error
 }
 
 func NewTxPool(
 	client *CrossSporkClient,
 	transactionsPublisher *models.Publisher[*gethTypes.Transaction],
+	gasPrice GasPriceOracle,
+	submissions storage.SubmissionIndexer,
+	keys *KeyPool,
+	config *config.Config,
+	collector metrics.Collector,
 	logger zerolog.Logger,
 ) *TxPool {
+	var queue *txQueue
+	if config.TxQueueLimit > 0 {
+		queue = newTxQueue(config.TxQueueLimit, config.TxQueueTTL)
+	}
+
 	return &TxPool{
 		logger:      logger.With().Str("component", "tx-pool").Logger(),
 		client:      client,
+		config:      config,
 		txPublisher: transactionsPublisher,
+		gasPrice:    gasPrice,
+		collector:   collector,
 		pool:        &sync.Map{},
+		submissions: submissions,
+		keys:        keys,
+		queue:       queue,
 	}
 }
 
 // Send flow transaction that executes EVM run function which takes in the encoded EVM transaction.
 // The flow transaction status is awaited and an error is returned in case of a failure in submission,
-// or an EVM validation error.
+// or an EVM validation error. If the asynchronous submission is enabled, the function returns as soon
+// as the flow transaction is accepted by the Access Node, and the status is awaited in the background.
 // Until the flow transaction is sealed the transaction will stay in the transaction pool marked as pending.
+// If the flow transaction expires or has a sequence number mismatch, it's rebuilt by the rebuilder
+// and resubmitted at most the configured number of times.
 func (t *TxPool) Send(
 	ctx context.Context,
 	flowTx *flow.Transaction,
 	evmTx *gethTypes.Transaction,
+	rebuild Rebuilder,
 ) error {
 	t.txPublisher.Publish(evmTx) // publish pending transaction event
 
-	if err := t.client.SendTransaction(ctx, *flowTx); err != nil {
+	evmTxs := []*gethTypes.Transaction{evmTx}
+	if err := t.send(ctx, flowTx, evmTxs); err != nil {
 		return err
 	}
 
 	// add to pool and delete after transaction is sealed or errored out
 	t.pool.Store(evmTx.Hash(), evmTx)
-	defer t.pool.Delete(evmTx.Hash())
 
-	backoff := retry.WithMaxDuration(time.Minute*3, retry.NewFibonacci(time.Millisecond*100))
+	// the request context is canceled once the response is sent or the client disconnects,
+	// while the flow transaction can still be sealed, so it's tracked in the background
+	result := make(chan error, 1)
+	go func() {
+		result <- t.track(context.Background(), flowTx.ID(), flowTx.ProposalKey.KeyIndex, evmTxs, rebuild)[0]
+	}()
+
+	if t.config.AsyncTxSubmission {
+		return nil
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SendCadence sends the flow transaction which doesn't execute any EVM transactions, such as the
+// COA resource creation. The flow transaction status is awaited in the background, so the proposal
+// key leased to it is returned to the key pool once it's sealed.
+func (t *TxPool) SendCadence(ctx context.Context, flowTx *flow.Transaction) error {
+	if err := t.send(ctx, flowTx, nil); err != nil {
+		return err
+	}
+
+	go func() {
+		flowID := flowTx.ID()
+		res, err := t.awaitSealed(context.Background(), flowID)
+		t.releaseProposalKey(flowTx.ProposalKey.KeyIndex, res, err)
+
+		switch {
+		case err != nil:
+			t.logger.Error().Err(err).Str("flow-id", flowID.String()).Msg("failed to await flow transaction")
+		case res.Error != nil:
+			t.logger.Error().Err(res.Error).Str("flow-id", flowID.String()).Msg("flow transaction error")
+		}
+	}()
+
+	return nil
+}
+
+// SendBatched collects the EVM transaction into a batch, which is submitted in a single flow
+// transaction built by the builder, once the batch is full or the batch interval elapsed since
+// the first transaction was collected. The result of the EVM transaction is awaited the same
+// way as by Send, unless the asynchronous submission is enabled. The batch is tracked in the
+// background, so the request context only bounds the wait for the result.
+func (t *TxPool) SendBatched(
+	ctx context.Context,
+	evmTx *gethTypes.Transaction,
+	build BatchBuilder,
+	rebuild Rebuilder,
+) error {
+	t.txPublisher.Publish(evmTx) // publish pending transaction event
+
+	// add to pool and delete after transaction is sealed or errored out
+	t.pool.Store(evmTx.Hash(), evmTx)
+
+	batched := &batchedTx{tx: evmTx, result: make(chan error, 1)}
+
+	t.batchMux.Lock()
+	t.batch = append(t.batch, batched)
+	if uint64(len(t.batch)) >= t.config.TxBatchSize {
+		go t.submitBatch(t.takeBatch(), build, rebuild)
+	} else if len(t.batch) == 1 {
+		batchID := t.batchID
+		time.AfterFunc(t.config.TxBatchInterval, func() {
+			t.batchMux.Lock()
+			// the batch could be already submitted once full
+			if t.batchID != batchID {
+				t.batchMux.Unlock()
+				return
+			}
+			batch := t.takeBatch()
+			t.batchMux.Unlock()
+
+			t.submitBatch(batch, build, rebuild)
+		})
+	}
+	t.batchMux.Unlock()
+
+	if t.config.AsyncTxSubmission {
+		return nil
+	}
+
+	select {
+	case err := <-batched.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Queue holds the EVM transaction of the sender, which has a nonce ahead of the next nonce
+// of the sender, until the nonce gap is filled.
+func (t *TxPool) Queue(from common.Address, evmTx *gethTypes.Transaction) error {
+	if t.queue == nil {
+		return fmt.Errorf("transaction queueing is disabled")
+	}
+
+	return t.queue.add(from, evmTx)
+}
+
+// TakeQueued removes and returns the queued EVM transaction of the sender with the nonce, if any.
+func (t *TxPool) TakeQueued(from common.Address, nonce uint64) (*gethTypes.Transaction, bool) {
+	if t.queue == nil {
+		return nil, false
+	}
+
+	return t.queue.take(from, nonce)
+}
+
+// Queued returns the queued transactions, which wait for the nonce gap to be filled,
+// grouped by the sender and sorted by nonce.
+func (t *TxPool) Queued() map[common.Address][]*gethTypes.Transaction {
+	if t.queue == nil {
+		return map[common.Address][]*gethTypes.Transaction{}
+	}
+
+	return t.queue.queued()
+}
+
+// ExpireQueued removes the transactions queued for longer than the TTL,
+// and records their submission as failed.
+func (t *TxPool) ExpireQueued() []*gethTypes.Transaction {
+	if t.queue == nil {
+		return nil
+	}
+
+	expired := t.queue.expire(time.Now())
+	for _, evmTx := range expired {
+		t.record(evmTx, flow.EmptyID, fmt.Errorf(
+			"transaction with nonce %d was queued for longer than %s, without the nonce gap being filled",
+			evmTx.Nonce(),
+			t.config.TxQueueTTL,
+		))
+	}
+
+	return expired
+}
+
+// Submission returns the submission of the EVM transaction.
+func (t *TxPool) Submission(hash common.Hash) (*models.Submission, error) {
+	return t.submissions.Get(hash)
+}
+
+// Received returns whether the EVM transaction is held by the pool, either queued,
+// collected into a batch or awaiting its flow transaction to be sealed.
+func (t *TxPool) Received(hash common.Hash) bool {
+	if _, ok := t.pool.Load(hash); ok {
+		return true
+	}
+
+	for _, txs := range t.Queued() {
+		for _, tx := range txs {
+			if tx.Hash() == hash {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Resume tracks again the submissions, which were not yet sealed when the gateway stopped.
+// The EVM transactions are added back to the pool until their flow transaction is sealed.
+func (t *TxPool) Resume() error {
+	pending, err := t.submissions.Pending()
+	if err != nil {
+		return fmt.Errorf("failed to load pending submissions: %w", err)
+	}
+
+	// EVM transactions submitted in a batch share the flow transaction
+	flowTxs := make(map[flow.Identifier][]*gethTypes.Transaction)
+	keyIndexes := make(map[flow.Identifier]uint32)
+	for _, submission := range pending {
+		evmTx, err := submission.Transaction()
+		if err != nil {
+			return err
+		}
+		t.pool.Store(evmTx.Hash(), evmTx)
+		if _, ok := flowTxs[submission.FlowID]; !ok {
+			keyIndexes[submission.FlowID] = submission.KeyIndex
+		}
+		flowTxs[submission.FlowID] = append(flowTxs[submission.FlowID], evmTx)
+	}
+
+	for flowID, evmTxs := range flowTxs {
+		keyIndex := keyIndexes[flowID]
+		if t.keys != nil && !t.keys.Reserve(keyIndex) {
+			t.logger.Warn().
+				Str("flow-id", flowID.String()).
+				Uint32("key-index", keyIndex).
+				Msg("proposal key of pending submission is not in the key pool")
+		}
+
+		t.logger.Info().
+			Str("flow-id", flowID.String()).
+			Int("size", len(evmTxs)).
+			Msg("resuming tracking of pending submission")
+
+		// the builder of the flow transaction is not known after a restart, so it's not resubmitted
+		go t.track(context.Background(), flowID, keyIndex, evmTxs, nil)
+	}
+
+	return nil
+}
+
+// send persists the submissions of the EVM transactions and sends the flow transaction
+// executing them. The submissions are recorded as failed if the flow transaction is not accepted.
+func (t *TxPool) send(
+	ctx context.Context,
+	flowTx *flow.Transaction,
+	evmTxs []*gethTypes.Transaction,
+) error {
+	submittedAt := uint64(time.Now().Unix())
+	for _, evmTx := range evmTxs {
+		rawTx, err := evmTx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		err = t.submissions.Store(&models.Submission{
+			Hash:        evmTx.Hash(),
+			RawTx:       rawTx,
+			FlowID:      flowTx.ID(),
+			KeyIndex:    flowTx.ProposalKey.KeyIndex,
+			Status:      models.SubmissionSubmitted,
+			SubmittedAt: submittedAt,
+		})
+		if err != nil {
+			t.releaseKey(flowTx.ProposalKey.KeyIndex, false)
+			return fmt.Errorf("failed to store submission of transaction %s: %w", evmTx.Hash(), err)
+		}
+	}
+
+	if err := t.client.SendTransaction(ctx, *flowTx); err != nil {
+		// the flow transaction could be accepted despite the error
+		t.invalidateKey(flowTx.ProposalKey.KeyIndex)
+		for _, evmTx := range evmTxs {
+			t.record(evmTx, flowTx.ID(), err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// record stores the final status of the submission of the EVM transaction, which is
+// failed with the error if not nil, otherwise sealed. The raw transaction and the signer
+// key index are kept from the stored submission, if any.
+func (t *TxPool) record(
+	evmTx *gethTypes.Transaction,
+	flowID flow.Identifier,
+	txErr error,
+) {
+	submission := &models.Submission{
+		Hash:   evmTx.Hash(),
+		FlowID: flowID,
+		Status: models.SubmissionSealed,
+	}
+	if prev, err := t.submissions.Get(evmTx.Hash()); err == nil {
+		submission.RawTx = prev.RawTx
+		submission.SubmittedAt = prev.SubmittedAt
+		submission.KeyIndex = prev.KeyIndex
+	} else if rawTx, err := evmTx.MarshalBinary(); err == nil {
+		submission.RawTx = rawTx
+	}
+	if txErr != nil {
+		submission.Status = models.SubmissionFailed
+		submission.Error = txErr.Error()
+	}
+
+	if err := t.submissions.Store(submission); err != nil {
+		t.logger.Error().Err(err).
+			Str("evm-id", evmTx.Hash().Hex()).
+			Msg("failed to store transaction submission")
+	}
+}
+
+// RecordFailure records the submission of the EVM transaction as failed with the error,
+// unless the submission was already stored, in which case its status is kept by the pool.
+func (t *TxPool) RecordFailure(evmTx *gethTypes.Transaction, txErr error) {
+	_, err := t.submissions.Get(evmTx.Hash())
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, errs.ErrEntityNotFound) {
+		t.logger.Error().Err(err).
+			Str("evm-id", evmTx.Hash().Hex()).
+			Msg("failed to get transaction submission")
+		return
+	}
+
+	t.record(evmTx, flow.EmptyID, txErr)
+}
+
+// takeBatch returns the collected batch and starts a new one, the batch lock must be held.
+func (t *TxPool) takeBatch() []*batchedTx {
+	batch := t.batch
+	t.batch = nil
+	t.batchID++
+	return batch
+}
+
+// submitBatch submits the batch of EVM transactions in a single flow transaction,
+// awaits it to be sealed and sends the result of each EVM transaction to its caller.
+func (t *TxPool) submitBatch(batch []*batchedTx, build BatchBuilder, rebuild Rebuilder) {
+	ctx := context.Background()
+
+	evmTxs := make([]*gethTypes.Transaction, len(batch))
+	for i, batched := range batch {
+		evmTxs[i] = batched.tx
+	}
+
+	flowTx, err := build(ctx, evmTxs)
+	if err != nil {
+		for _, evmTx := range evmTxs {
+			t.record(evmTx, flow.EmptyID, err)
+		}
+	} else {
+		err = t.send(ctx, flowTx, evmTxs)
+	}
+	if err != nil {
+		t.logger.Error().Err(err).Int("size", len(batch)).Msg("failed to submit transaction batch")
+
+		for _, batched := range batch {
+			t.pool.Delete(batched.tx.Hash())
+			batched.result <- err
+		}
+		return
+	}
+
+	for i, err := range t.track(ctx, flowTx.ID(), flowTx.ProposalKey.KeyIndex, evmTxs, rebuild) {
+		batch[i].result <- err
+	}
+}
+
+// track awaits the flow transaction executing the EVM transactions to be sealed, and records
+// the status of the submissions. The errors of the EVM transactions are returned in order.
+// If any of the batched EVM transactions is invalid, the flow transaction is reverted
+// with the error of each invalid transaction, and the valid ones are resubmitted without
+// them. If the flow transaction expired or had a sequence number mismatch, it's rebuilt
+// and resubmitted, unless the rebuilder is nil or the resubmission limit is reached.
+func (t *TxPool) track(
+	ctx context.Context,
+	flowID flow.Identifier,
+	keyIndex uint32,
+	evmTxs []*gethTypes.Transaction,
+	rebuild Rebuilder,
+) []error {
+	start := time.Now()
+	results := make([]error, len(evmTxs))
+
+	// the indexes of the EVM transactions executed by the current flow transaction
+	pending := make([]int, len(evmTxs))
+	for i := range evmTxs {
+		pending[i] = i
+	}
+
+	for resubmissions := uint64(0); ; resubmissions++ {
+		res, err := t.awaitSealed(ctx, flowID)
+		t.releaseProposalKey(keyIndex, res, err)
+
+		reason, expired := resubmitReason(res, err)
+		if !expired || rebuild == nil || resubmissions >= t.config.TxResubmitLimit {
+			t.complete(flowID, evmTxs, pending, res, err, results, start)
+			return results
+		}
+
+		if reason == resubmitInvalidBatch {
+			pending = t.dropInvalid(flowID, evmTxs, pending, parseBatchInvalidErrors(res.Error), results, start)
+			if len(pending) == 0 {
+				return results
+			}
+		}
+
+		t.collector.TransactionResubmitted(reason)
+		t.logger.Warn().
+			Str("flow-id", flowID.String()).
+			Str("reason", reason).
+			Uint64("resubmission", resubmissions+1).
+			Msg("resubmitting flow transaction")
+
+		txs := make([]*gethTypes.Transaction, len(pending))
+		for i, index := range pending {
+			txs[i] = evmTxs[index]
+		}
 
-	return retry.Do(ctx, backoff, func(ctx context.Context) error {
-		res, err := t.client.GetTransactionResult(ctx, flowTx.ID())
+		flowTx, executed, err := rebuild(ctx, txs)
 		if err != nil {
-			return fmt.Errorf("failed to retrieve flow transaction result %s: %w", flowTx.ID(), err)
+			err = fmt.Errorf("failed to rebuild flow transaction %s: %w", flowID, err)
+		}
+
+		remaining := pending[:0]
+		for _, index := range pending {
+			evmTx := evmTxs[index]
+			switch {
+			case err != nil:
+				results[index] = err
+			case executed[evmTx.Hash()]:
+				results[index] = errs.NewFailedTransactionError(fmt.Sprintf(
+					"transaction was not resubmitted, since its nonce %d was used by an executed transaction",
+					evmTx.Nonce(),
+				))
+			default:
+				remaining = append(remaining, index)
+				continue
+			}
+			t.finish(evmTx, flowID, results[index], start)
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			return results
+		}
+
+		txs = txs[:0]
+		for _, index := range pending {
+			txs = append(txs, evmTxs[index])
+		}
+		if err := t.send(ctx, flowTx, txs); err != nil {
+			for _, index := range pending {
+				results[index] = err
+				t.finish(evmTxs[index], flowTx.ID(), err, start)
+			}
+			return results
+		}
+		flowID = flowTx.ID()
+		keyIndex = flowTx.ProposalKey.KeyIndex
+	}
+}
+
+// awaitSealed awaits the flow transaction to be sealed or expired, and returns its result.
+func (t *TxPool) awaitSealed(ctx context.Context, flowID flow.Identifier) (*flow.TransactionResult, error) {
+	backoff := retry.WithMaxDuration(sealTimeout, retry.NewFibonacci(time.Millisecond*100))
+
+	var res *flow.TransactionResult
+	err := retry.Do(ctx, backoff, func(ctx context.Context) error {
+		var err error
+		res, err = t.client.GetTransactionResult(ctx, flowID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve flow transaction result %s: %w", flowID, err)
 		}
 		// retry until transaction is sealed
 		if res.Status < flow.TransactionStatusSealed {
-			return retry.RetryableError(fmt.Errorf("transaction %s not sealed", flowTx.ID()))
+			return retry.RetryableError(fmt.Errorf("transaction %s not sealed", flowID))
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() != nil {
+		// the client errors of the canceled requests don't wrap the context error
+		return nil, fmt.Errorf("failed to await flow transaction %s: %w", flowID, ctx.Err())
+	}
+
+	return res, err
+}
+
+// complete records the results of the pending EVM transactions executed by the sealed flow transaction.
+func (t *TxPool) complete(
+	flowID flow.Identifier,
+	evmTxs []*gethTypes.Transaction,
+	pending []int,
+	res *flow.TransactionResult,
+	err error,
+	results []error,
+	start time.Time,
+) {
+	var (
+		executed map[common.Hash]bool
+		invalid  map[int]error
+	)
+	if err == nil && res.Error != nil {
+		invalid = parseBatchInvalidErrors(res.Error)
+		t.logger.Error().Err(res.Error).
+			Str("flow-id", flowID.String()).
+			Int("size", len(pending)).
+			Msg("flow transaction error")
+	} else if err == nil {
+		executed = executedTransactions(res.Events)
+		if fees, gasUsed, ok := flowFeesPerGas(res.Events); ok && t.gasPrice != nil {
+			t.gasPrice.ObserveFlowFees(fees, gasUsed)
 		}
+	}
 
-		if res.Error != nil {
-			if err, ok := parseInvalidError(res.Error); ok {
-				return err
+	for i, index := range pending {
+		evmTx := evmTxs[index]
+		txErr := err
+		switch {
+		case txErr != nil:
+		case len(invalid) > 0:
+			if invalidErr, ok := invalid[i]; ok {
+				txErr = invalidErr
+			} else {
+				txErr = errs.NewFailedTransactionError("transaction was not executed, since another transaction of its batch was invalid")
 			}
+		case res.Error != nil:
+			if invalidErr, ok := parseInvalidError(res.Error); ok {
+				txErr = invalidErr
+			} else {
+				// hide specific cause since it's an implementation issue
+				txErr = fmt.Errorf("failed to submit flow evm transaction %s", evmTx.Hash())
+			}
+		case res.Status == flow.TransactionStatusExpired:
+			txErr = fmt.Errorf("flow transaction %s expired", flowID)
+		case !executed[evmTx.Hash()]:
+			txErr = errs.NewFailedTransactionError("transaction is invalid for the current state and was not executed")
+		}
+		results[index] = txErr
+		t.finish(evmTx, flowID, txErr, start)
+	}
+}
+
+// dropInvalid records the errors of the invalid EVM transactions of the reverted batch,
+// which are indexed by their position in the pending transactions, and returns the
+// pending transactions which were valid.
+func (t *TxPool) dropInvalid(
+	flowID flow.Identifier,
+	evmTxs []*gethTypes.Transaction,
+	pending []int,
+	invalid map[int]error,
+	results []error,
+	start time.Time,
+) []int {
+	valid := make([]int, 0, len(pending))
+	for i, index := range pending {
+		invalidErr, ok := invalid[i]
+		if !ok {
+			valid = append(valid, index)
+			continue
+		}
+		results[index] = invalidErr
+		t.finish(evmTxs[index], flowID, invalidErr, start)
+	}
+	return valid
+}
 
-			t.logger.Error().Err(res.Error).
-				Str("flow-id", flowTx.ID().String()).
-				Str("evm-id", evmTx.Hash().Hex()).
-				Msg("flow transaction error")
+// finish records the final status of the submission of the EVM transaction and removes it from the pool.
+// If the tracking was canceled, the flow transaction can still be sealed, so the submission is kept as
+// submitted to be tracked again by Resume.
+func (t *TxPool) finish(evmTx *gethTypes.Transaction, flowID flow.Identifier, txErr error, start time.Time) {
+	if isCanceled(txErr) {
+		t.logger.Warn().Err(txErr).
+			Str("flow-id", flowID.String()).
+			Str("evm-id", evmTx.Hash().Hex()).
+			Msg("transaction submission tracking canceled")
+		t.pool.Delete(evmTx.Hash())
+		return
+	}
 
-			// hide specific cause since it's an implementation issue
-			return fmt.Errorf("failed to submit flow evm transaction %s", evmTx.Hash())
+	if txErr != nil && t.config.AsyncTxSubmission {
+		t.logger.Warn().Err(txErr).
+			Str("flow-id", flowID.String()).
+			Str("evm-id", evmTx.Hash().Hex()).
+			Msg("asynchronous transaction submission failed")
+	}
+	t.record(evmTx, flowID, txErr)
+	t.pool.Delete(evmTx.Hash())
+	t.collector.TransactionSubmissionCompleted(txErr == nil, start)
+}
+
+// releaseProposalKey releases the proposal key of the flow transaction with the result.
+// The sequence number is used by any sealed flow transaction, unless it failed the
+// validation of the proposal key or of the signatures.
+func (t *TxPool) releaseProposalKey(keyIndex uint32, res *flow.TransactionResult, err error) {
+	switch {
+	case err != nil:
+		t.invalidateKey(keyIndex)
+	case res.Status == flow.TransactionStatusExpired:
+		t.releaseKey(keyIndex, false)
+	case res.Error != nil && hasErrorCode(
+		res.Error,
+		fvmErrors.ErrCodeInvalidProposalSignatureError,
+		fvmErrors.ErrCodeInvalidProposalSeqNumberError,
+		fvmErrors.ErrCodeInvalidPayloadSignatureError,
+		fvmErrors.ErrCodeInvalidEnvelopeSignatureError,
+	):
+		if t.keys != nil {
+			t.keys.Failed(keyIndex)
 		}
+	default:
+		t.releaseKey(keyIndex, true)
+	}
+}
 
-		return nil
+func (t *TxPool) releaseKey(keyIndex uint32, used bool) {
+	if t.keys != nil {
+		t.keys.Release(keyIndex, used)
+	}
+}
+
+func (t *TxPool) invalidateKey(keyIndex uint32) {
+	if t.keys != nil {
+		t.keys.Invalidate(keyIndex)
+	}
+}
+
+// isCanceled returns whether the error is caused by the cancellation of the context.
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// hasErrorCode returns whether the flow transaction error has any of the error codes.
+func hasErrorCode(err error, codes ...fvmErrors.ErrorCode) bool {
+	for _, code := range codes {
+		if strings.Contains(err.Error(), code.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// resubmitReason returns the reason the flow transaction should be rebuilt and resubmitted,
+// which is either its expiry, a sequence number mismatch of its proposal key, or an invalid
+// EVM transaction reverting its batch.
+func resubmitReason(res *flow.TransactionResult, err error) (string, bool) {
+	switch {
+	case err != nil:
+		return "", false
+	case res.Status == flow.TransactionStatusExpired:
+		return resubmitExpired, true
+	case res.Error != nil && hasErrorCode(res.Error, fvmErrors.ErrCodeInvalidProposalSeqNumberError):
+		return resubmitSequenceMismatch, true
+	case res.Error != nil && len(parseBatchInvalidErrors(res.Error)) > 0:
+		return resubmitInvalidBatch, true
+	default:
+		return "", false
+	}
+}
+
+// executedTransactions returns the hashes of the EVM transactions executed by the flow transaction.
+func executedTransactions(flowEvents []flow.Event) map[common.Hash]bool {
+	executed := make(map[common.Hash]bool)
+	for _, event := range flowEvents {
+		if event.Value.EventType == nil ||
+			event.Value.EventType.QualifiedIdentifier != models.TransactionExecutedQualifiedIdentifier {
+			continue
+		}
+		payload, err := events.DecodeTransactionEventPayload(event.Value)
+		if err != nil {
+			continue
+		}
+		executed[payload.Hash] = true
+	}
+	return executed
+}
+
+// flowFeesPerGas returns the Flow fees in attoFlow deducted for the Flow transaction,
+// and the gas used by the EVM transactions it executed.
+func flowFeesPerGas(flowEvents []flow.Event) (*big.Int, uint64, bool) {
+	var (
+		fees    *big.Int
+		gasUsed uint64
+	)
+	for _, event := range flowEvents {
+		if event.Value.EventType == nil {
+			continue
+		}
+
+		switch event.Value.EventType.QualifiedIdentifier {
+		case feesDeductedQualifiedIdentifier:
+			amount, ok := cadence.SearchFieldByName(event.Value, "amount").(cadence.UFix64)
+			if !ok {
+				continue
+			}
+			fees = new(big.Int).Mul(new(big.Int).SetUint64(uint64(amount)), ufix64ToAttoFlow)
+		case models.TransactionExecutedQualifiedIdentifier:
+			payload, err := events.DecodeTransactionEventPayload(event.Value)
+			if err != nil {
+				continue
+			}
+			gasUsed += payload.GasConsumed
+		}
+	}
+
+	return fees, gasUsed, fees != nil && gasUsed > 0
+}
+
+// PendingNonce returns the nonce following the transactions of the address in the pool,
+// if it is higher than the provided nonce, otherwise the provided nonce is returned.
+func (t *TxPool) PendingNonce(address common.Address, nonce uint64) uint64 {
+	for _, tx := range t.Pending()[address] {
+		if tx.Nonce() >= nonce {
+			nonce = tx.Nonce() + 1
+		}
+	}
+
+	return nonce
+}
+
+// Pending returns the transactions in the pool, which are submitted but not yet sealed,
+// grouped by the sender and sorted by nonce.
+func (t *TxPool) Pending() map[common.Address][]*gethTypes.Transaction {
+	pending := make(map[common.Address][]*gethTypes.Transaction)
+	t.pool.Range(func(_, value any) bool {
+		tx := value.(*gethTypes.Transaction)
+		from, err := gethTypes.Sender(gethTypes.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			t.logger.Warn().Err(err).Str("evm-id", tx.Hash().Hex()).Msg("failed to derive the sender")
+			return true
+		}
+		pending[from] = append(pending[from], tx)
+		return true
 	})
+
+	for _, txs := range pending {
+		sort.Slice(txs, func(i, j int) bool {
+			return txs[i].Nonce() < txs[j].Nonce()
+		})
+	}
+
+	return pending
 }
 
 // this will extract the evm specific error from the Flow transaction error message
@@ -108,3 +871,22 @@ func parseInvalidError(err error) (error, bool) {
 
 	return errs.NewFailedTransactionError(matches[1]), true
 }
+
+// parseBatchInvalidErrors extracts the errors of the invalid EVM transactions from the
+// Flow transaction error message, by their index in the batch. The batch_run.cdc script
+// reverts the batch with the error of each invalid transaction, prefixed by its index,
+// which is parsed the same as the error of a single transaction.
+func parseBatchInvalidErrors(err error) map[int]error {
+	r := regexp.MustCompile(evmBatchErrorRegex)
+	invalid := make(map[int]error)
+	for _, matches := range r.FindAllStringSubmatch(err.Error(), -1) {
+		index, convErr := strconv.Atoi(matches[1])
+		if convErr != nil {
+			continue
+		}
+		if invalidErr, ok := parseInvalidError(errors.New(matches[2])); ok {
+			invalid[index] = invalidErr
+		}
+	}
+	return invalid
+}
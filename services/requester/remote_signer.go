@@ -0,0 +1,307 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/rs/zerolog"
+	"github.com/sethvargo/go-retry"
+)
+
+// The remote signer protocol is JSON over HTTP, with the routes under the signer URL:
+//
+//	GET  /v1/keys/{keyID}       returns the RemoteKey, used to discover the public key
+//	POST /v1/keys/{keyID}/sign  signs the message of the RemoteSignRequest with the key,
+//	                            returning the RemoteSignResponse
+//
+// The remote signer hashes the message with the hash algorithm of the key before signing it,
+// so the signer can inspect the signed messages. The failed requests return a non-2xx status
+// with the RemoteSignerError, and the requests failing with a 5xx or 429 status, or without
+// a response, are retried.
+
+const (
+	remoteKeyPath  = "/v1/keys/%s"
+	remoteSignPath = "/v1/keys/%s/sign"
+)
+
+var _ MultiKeySigner = &RemoteKeyRotationSigner{}
+
+// RemoteKey is the key of the remote signer.
+type RemoteKey struct {
+	KeyID string `json:"keyId"`
+	// PublicKey is the encoded public key, without the algorithm prefix
+	PublicKey          hexutil.Bytes `json:"publicKey"`
+	SignatureAlgorithm string        `json:"signatureAlgorithm"`
+	HashAlgorithm      string        `json:"hashAlgorithm"`
+}
+
+// RemoteSignRequest is the request to sign the message with the key of the remote signer.
+type RemoteSignRequest struct {
+	Message hexutil.Bytes `json:"message"`
+}
+
+// RemoteSignResponse is the signature of the message by the key of the remote signer.
+type RemoteSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// RemoteSignerError is the reason the request to the remote signer failed.
+type RemoteSignerError struct {
+	Message string `json:"error"`
+}
+
+// RemoteSignerOptions are the options of the remote signer client.
+type RemoteSignerOptions struct {
+	// URL is the base URL of the remote signer
+	URL string
+	// KeyIDs are the IDs of the keys of the remote signer, used in key-rotation mechanism
+	KeyIDs []string
+	// TLS is the TLS config of the client, holding the client certificate for mTLS
+	TLS *tls.Config
+	// Timeout is the timeout of each request to the remote signer
+	Timeout time.Duration
+	// Retries is the number of times a failed request is retried
+	Retries uint64
+}
+
+// NewRemoteSignerTLSConfig creates the TLS config of the remote signer client, with the client
+// certificate and key for mTLS, and the CA certificate to verify the remote signer with. All of
+// the files are optional, but the certificate and key must be provided together.
+func NewRemoteSignerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the remote signer client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the remote signer CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificates found in the remote signer CA file %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// remoteSignerClient sends the requests of the remote signer protocol, retrying the failed ones.
+type remoteSignerClient struct {
+	url     string
+	http    *http.Client
+	retries uint64
+}
+
+// do sends the request to the path, decoding the response into res.
+func (c *remoteSignerClient) do(ctx context.Context, method, path string, req, res any) error {
+	var body []byte
+	if req != nil {
+		var err error
+		if body, err = json.Marshal(req); err != nil {
+			return err
+		}
+	}
+
+	backoff := retry.WithMaxRetries(c.retries, retry.NewFibonacci(time.Millisecond*100))
+	return retry.Do(ctx, backoff, func(ctx context.Context) error {
+		request, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := c.http.Do(request)
+		if err != nil {
+			// the signer could be temporarily unavailable
+			return retry.RetryableError(err)
+		}
+		defer response.Body.Close()
+
+		raw, err := io.ReadAll(response.Body)
+		if err != nil {
+			return retry.RetryableError(err)
+		}
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			var signerErr RemoteSignerError
+			_ = json.Unmarshal(raw, &signerErr)
+			err := fmt.Errorf("remote signer request %s %s failed with status %d: %s", method, path, response.StatusCode, signerErr.Message)
+			if response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests {
+				return retry.RetryableError(err)
+			}
+			return err
+		}
+
+		return json.Unmarshal(raw, res)
+	})
+}
+
+// RemoteSigner is a crypto signer signing with a key of the remote signer.
+type RemoteSigner struct {
+	client    *remoteSignerClient
+	keyID     string
+	publicKey crypto.PublicKey
+}
+
+// Sign signs the message with the key of the remote signer.
+func (s *RemoteSigner) Sign(message []byte) ([]byte, error) {
+	var res RemoteSignResponse
+	path := fmt.Sprintf(remoteSignPath, url.PathEscape(s.keyID))
+	err := s.client.do(context.Background(), http.MethodPost, path, RemoteSignRequest{Message: message}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Signature, nil
+}
+
+// PublicKey returns the public key of the key of the remote signer, discovered at creation.
+func (s *RemoteSigner) PublicKey() crypto.PublicKey {
+	return s.publicKey
+}
+
+// RemoteKeyRotationSigner is a crypto signer that contains a pool of signers, each of which is
+// tied to a key of the remote signer, and rotates the key used for each signing request.
+// The signer is concurrency-safe.
+type RemoteKeyRotationSigner struct {
+	mux     sync.RWMutex
+	signers []*RemoteSigner
+	index   int
+	logger  zerolog.Logger
+}
+
+// NewRemoteKeyRotationSigner returns a new RemoteKeyRotationSigner for the keys of the remote
+// signer, discovering the public key of each of the keys.
+func NewRemoteKeyRotationSigner(
+	ctx context.Context,
+	options RemoteSignerOptions,
+	logger zerolog.Logger,
+) (*RemoteKeyRotationSigner, error) {
+	logger = logger.With().Str("component", "remote_signer").Logger()
+
+	if len(options.KeyIDs) == 0 {
+		return nil, fmt.Errorf("could not create remote key rotation signer, no key IDs provided")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = options.TLS
+	client := &remoteSignerClient{
+		url:     strings.TrimSuffix(options.URL, "/"),
+		http:    &http.Client{Timeout: options.Timeout, Transport: transport},
+		retries: options.Retries,
+	}
+
+	signers := make([]*RemoteSigner, len(options.KeyIDs))
+	for i, keyID := range options.KeyIDs {
+		signer, err := discoverRemoteKey(ctx, client, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("could not create remote signer for the key with ID: %s: %w", keyID, err)
+		}
+		logger.Info().
+			Str("key-id", keyID).
+			Str("public-key", signer.publicKey.String()).
+			Msg("remote signer added")
+
+		signers[i] = signer
+	}
+
+	return &RemoteKeyRotationSigner{
+		signers: signers,
+		logger:  logger,
+	}, nil
+}
+
+// discoverRemoteKey returns the signer of the key, with the public key of the remote signer.
+func discoverRemoteKey(ctx context.Context, client *remoteSignerClient, keyID string) (*RemoteSigner, error) {
+	var key RemoteKey
+	path := fmt.Sprintf(remoteKeyPath, url.PathEscape(keyID))
+	if err := client.do(ctx, http.MethodGet, path, nil, &key); err != nil {
+		return nil, err
+	}
+
+	sigAlgo := crypto.StringToSignatureAlgorithm(key.SignatureAlgorithm)
+	if sigAlgo == crypto.UnknownSignatureAlgorithm {
+		return nil, fmt.Errorf("invalid signature algorithm: %s", key.SignatureAlgorithm)
+	}
+	hashAlgo := crypto.StringToHashAlgorithm(key.HashAlgorithm)
+	if !crypto.CompatibleAlgorithms(sigAlgo, hashAlgo) {
+		return nil, fmt.Errorf(
+			"signature algorithm %s and hashing algorithm are incompatible %s",
+			key.SignatureAlgorithm,
+			key.HashAlgorithm,
+		)
+	}
+
+	publicKey, err := crypto.DecodePublicKey(sigAlgo, key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return &RemoteSigner{
+		client:    client,
+		keyID:     keyID,
+		publicKey: publicKey,
+	}, nil
+}
+
+// Sign requests the signature of the message from the remote signer with the current
+// key, and rotates to the next key before the request is made, so the concurrent signing
+// requests use different keys. Unavailable remote signers are retried the configured times.
+func (s *RemoteKeyRotationSigner) Sign(message []byte) ([]byte, error) {
+	s.mux.Lock()
+	signer := s.signers[s.index]
+	s.index = (s.index + 1) % len(s.signers)
+	s.mux.Unlock()
+
+	defer func(start time.Time) {
+		s.logger.Debug().
+			Str("key-id", signer.keyID).
+			Int64("duration", time.Since(start).Milliseconds()).
+			Msg("message signed by the remote signer")
+	}(time.Now())
+
+	signature, err := signer.Sign(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message with key %s: %w", signer.keyID, err)
+	}
+
+	return signature, nil
+}
+
+// PublicKey returns the current public key which is available for signing.
+func (s *RemoteKeyRotationSigner) PublicKey() crypto.PublicKey {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	return s.signers[s.index].PublicKey()
+}
+
+// Signers returns the signers of each of the keys of the remote signer.
+func (s *RemoteKeyRotationSigner) Signers() []crypto.Signer {
+	signers := make([]crypto.Signer, len(s.signers))
+	for i, signer := range s.signers {
+		signers[i] = signer
+	}
+	return signers
+}
@@ -0,0 +1,123 @@
+package requester
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/rs/zerolog"
+)
+
+// RemoteSignerServer is the reference implementation of the remote signer protocol, signing
+// with in-memory keys. It's meant for tests and local development, the production signers
+// should keep the keys in a secure storage and authenticate the clients with mTLS.
+type RemoteSignerServer struct {
+	keys     map[string]crypto.PrivateKey
+	hashAlgo crypto.HashAlgorithm
+	handler  *http.ServeMux
+	logger   zerolog.Logger
+}
+
+var _ http.Handler = &RemoteSignerServer{}
+
+// NewRemoteSignerServer creates the remote signer server of the keys by the key IDs, hashing
+// the messages with the hash algorithm before signing them.
+func NewRemoteSignerServer(
+	keys map[string]crypto.PrivateKey,
+	hashAlgo crypto.HashAlgorithm,
+	logger zerolog.Logger,
+) (*RemoteSignerServer, error) {
+	for keyID, key := range keys {
+		if !crypto.CompatibleAlgorithms(key.Algorithm(), hashAlgo) {
+			return nil, fmt.Errorf(
+				"signature algorithm %s of key %s and hashing algorithm are incompatible %s",
+				key.Algorithm(),
+				keyID,
+				hashAlgo,
+			)
+		}
+	}
+
+	s := &RemoteSignerServer{
+		keys:     keys,
+		hashAlgo: hashAlgo,
+		handler:  http.NewServeMux(),
+		logger:   logger.With().Str("component", "remote_signer_server").Logger(),
+	}
+	s.handler.HandleFunc("GET /v1/keys/{keyID}", s.getKey)
+	s.handler.HandleFunc("POST /v1/keys/{keyID}/sign", s.sign)
+
+	return s, nil
+}
+
+func (s *RemoteSignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *RemoteSignerServer) getKey(w http.ResponseWriter, r *http.Request) {
+	keyID := r.PathValue("keyID")
+	key, ok := s.keys[keyID]
+	if !ok {
+		s.error(w, http.StatusNotFound, fmt.Errorf("key %s not found", keyID))
+		return
+	}
+
+	s.respond(w, RemoteKey{
+		KeyID:              keyID,
+		PublicKey:          key.PublicKey().Encode(),
+		SignatureAlgorithm: key.Algorithm().String(),
+		HashAlgorithm:      s.hashAlgo.String(),
+	})
+}
+
+func (s *RemoteSignerServer) sign(w http.ResponseWriter, r *http.Request) {
+	keyID := r.PathValue("keyID")
+	key, ok := s.keys[keyID]
+	if !ok {
+		s.error(w, http.StatusNotFound, fmt.Errorf("key %s not found", keyID))
+		return
+	}
+
+	var req RemoteSignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.error(w, http.StatusBadRequest, fmt.Errorf("invalid sign request: %w", err))
+		return
+	}
+
+	signature, err := s.signMessage(key, req.Message)
+	if err != nil {
+		s.error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.logger.Debug().Str("key-id", keyID).Msg("message signed")
+	s.respond(w, RemoteSignResponse{Signature: signature})
+}
+
+// signMessage signs the message with the key, with a hasher per request since the hasher is stateful.
+func (s *RemoteSignerServer) signMessage(key crypto.PrivateKey, message []byte) ([]byte, error) {
+	hasher, err := crypto.NewHasher(s.hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.Sign(message, hasher)
+}
+
+func (s *RemoteSignerServer) respond(w http.ResponseWriter, res any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.Error().Err(err).Msg("failed to write the response")
+	}
+}
+
+func (s *RemoteSignerServer) error(w http.ResponseWriter, status int, err error) {
+	s.logger.Warn().Err(err).Int("status", status).Msg("remote signer request failed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(RemoteSignerError{Message: err.Error()}); err != nil {
+		s.logger.Error().Err(err).Msg("failed to write the response")
+	}
+}
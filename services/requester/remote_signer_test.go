@@ -0,0 +1,160 @@
+package requester
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RemoteSigner(t *testing.T) {
+	keys := testKeys(t)[:2]
+	server, err := NewRemoteSignerServer(
+		map[string]crypto.PrivateKey{"key-0": keys[0], "key-1": keys[1]},
+		crypto.SHA3_256,
+		zerolog.Nop(),
+	)
+	require.NoError(t, err)
+
+	hasher, err := crypto.NewHasher(crypto.SHA3_256)
+	require.NoError(t, err)
+
+	t.Run("keys rotated over mTLS", func(t *testing.T) {
+		clientCert := testClientCertificate(t)
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(clientCert.Leaf)
+
+		remote := httptest.NewUnstartedServer(server)
+		remote.TLS = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.RequireAndVerifyClientCert}
+		remote.StartTLS()
+		defer remote.Close()
+
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(remote.Certificate())
+		options := RemoteSignerOptions{
+			URL:     remote.URL,
+			KeyIDs:  []string{"key-0", "key-1"},
+			TLS:     &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{clientCert}},
+			Timeout: time.Second,
+		}
+
+		signer, err := NewRemoteKeyRotationSigner(context.Background(), options, zerolog.Nop())
+		require.NoError(t, err)
+
+		signers := signer.Signers()
+		require.Len(t, signers, 2)
+		for i, s := range signers {
+			require.True(t, s.PublicKey().Equals(keys[i].PublicKey()))
+		}
+
+		message := []byte("foo")
+		for i := 0; i < 4; i++ {
+			key := keys[i%len(keys)]
+			require.True(t, signer.PublicKey().Equals(key.PublicKey()))
+
+			sig, err := signer.Sign(message)
+			require.NoError(t, err)
+			valid, err := key.PublicKey().Verify(sig, message, hasher)
+			require.NoError(t, err)
+			require.True(t, valid)
+		}
+
+		// the client without a certificate is rejected
+		options.TLS = &tls.Config{RootCAs: rootCAs}
+		_, err = NewRemoteKeyRotationSigner(context.Background(), options, zerolog.Nop())
+		require.Error(t, err)
+	})
+
+	t.Run("unavailable signer is retried", func(t *testing.T) {
+		var requests atomic.Int32
+		remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1)%2 == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			server.ServeHTTP(w, r)
+		}))
+		defer remote.Close()
+
+		signer, err := NewRemoteKeyRotationSigner(
+			context.Background(),
+			RemoteSignerOptions{URL: remote.URL, KeyIDs: []string{"key-0"}, Timeout: time.Second, Retries: 1},
+			zerolog.Nop(),
+		)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("bar"))
+		require.NoError(t, err)
+		valid, err := keys[0].PublicKey().Verify(sig, []byte("bar"), hasher)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Equal(t, int32(4), requests.Load())
+	})
+
+	t.Run("unknown key is not retried", func(t *testing.T) {
+		var requests atomic.Int32
+		remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			server.ServeHTTP(w, r)
+		}))
+		defer remote.Close()
+
+		_, err := NewRemoteKeyRotationSigner(
+			context.Background(),
+			RemoteSignerOptions{URL: remote.URL, KeyIDs: []string{"key-2"}, Timeout: time.Second, Retries: 3},
+			zerolog.Nop(),
+		)
+		require.ErrorContains(t, err, "key key-2 not found")
+		require.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("slow signer times out", func(t *testing.T) {
+		remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond * 200)
+			server.ServeHTTP(w, r)
+		}))
+		defer remote.Close()
+
+		_, err := NewRemoteKeyRotationSigner(
+			context.Background(),
+			RemoteSignerOptions{URL: remote.URL, KeyIDs: []string{"key-0"}, Timeout: time.Millisecond * 50},
+			zerolog.Nop(),
+		)
+		require.ErrorContains(t, err, "Client.Timeout exceeded")
+	})
+}
+
+// testClientCertificate creates a self-signed client certificate.
+func testClientCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
@@ -2,36 +2,76 @@ package requester
 
 import (
 	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/onflow/atree"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/fvm/environment"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow/protobuf/go/flow/entities"
 	"github.com/onflow/flow/protobuf/go/flow/executiondata"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-evm-gateway/metrics"
 )
 
+// registerPrefetchCount is the maximum number of the following slab registers
+// of the same owner, fetched together with a requested slab register. Slabs of
+// the same storage are often allocated one after another, so they are likely
+// to be requested next.
+const registerPrefetchCount = 8
+
 var _ atree.Ledger = &remoteLedger{}
 
+// registerCacheKey identifies a register at a Cadence height.
+type registerCacheKey struct {
+	height uint64
+	id     flow.RegisterID
+}
+
+// registerCache caches the register values by Cadence height. The register values
+// at a sealed height never change, so the cache can be shared across requests.
+type registerCache = lru.Cache[registerCacheKey, []byte]
+
+func newRegisterCache(size int) (*registerCache, error) {
+	return lru.New[registerCacheKey, []byte](size)
+}
+
 func newRemoteLedger(
 	client executiondata.ExecutionDataAPIClient,
 	cadenceHeight uint64,
+	cache *registerCache,
+	collector metrics.Collector,
 ) (*remoteLedger, error) {
 	return &remoteLedger{
-		execution: client,
-		height:    cadenceHeight,
+		execution:   client,
+		height:      cadenceHeight,
+		cache:       cache,
+		collector:   collector,
+		slabIndexes: make(map[string]uint64),
 	}, nil
 }
 
 // remoteLedger is a ledger that uses execution data APIs to fetch register values,
 // thus simulating execution against the host network.
 //
+// The fetched register values are cached, if the cache is provided, and slab registers
+// are fetched in batches together with the following slab registers of the same owner.
+//
 // The ledger implements atree.Ledger interface which is used by the type.stateDB
 // to inspect the state.
 type remoteLedger struct {
 	execution executiondata.ExecutionDataAPIClient
 	height    uint64
+	cache     *registerCache
+	collector metrics.Collector
+	// slabIndexes contains the next slab index of each owner,
+	// all the slab registers below it are known to exist.
+	slabIndexes map[string]uint64
 }
 
 func (l *remoteLedger) GetValue(owner, key []byte) ([]byte, error) {
@@ -39,26 +79,23 @@ func (l *remoteLedger) GetValue(owner, key []byte) ([]byte, error) {
 		Key:   string(key),
 		Owner: string(owner),
 	}
-	registerID := convert.RegisterIDToMessage(id)
 
-	response, err := l.execution.GetRegisterValues(
-		context.Background(),
-		&executiondata.GetRegisterValuesRequest{
-			BlockHeight: l.height,
-			RegisterIds: []*entities.RegisterID{registerID},
-		},
-	)
-	errorCode := status.Code(err)
-	if err != nil && errorCode != codes.NotFound && errorCode != codes.OutOfRange {
-		return nil, err
+	if value, ok := l.cached(id); ok {
+		return value, nil
 	}
 
-	if response != nil && len(response.Values) > 0 {
-		// we only request one register so 0 index
-		return response.Values[0], nil
+	ids := append([]flow.RegisterID{id}, l.siblings(id)...)
+	values, err := l.fetch(ids)
+	if err != nil && len(ids) > 1 {
+		// the prefetched registers should exist, but if any doesn't the whole
+		// batch fails, so only the requested register is fetched again
+		values, err = l.fetch(ids[:1])
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, nil
+	return values[0], nil
 }
 
 func (l *remoteLedger) ValueExists(owner, key []byte) (exists bool, err error) {
@@ -73,3 +110,125 @@ func (l *remoteLedger) SetValue(owner, key, value []byte) (err error) {
 func (l *remoteLedger) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
 	panic("read only")
 }
+
+// fetch fetches the register values in a single request and caches them.
+// A register that doesn't exist has a nil value, if only that register is requested.
+func (l *remoteLedger) fetch(ids []flow.RegisterID) ([][]byte, error) {
+	registerIDs := make([]*entities.RegisterID, len(ids))
+	for i, id := range ids {
+		registerIDs[i] = convert.RegisterIDToMessage(id)
+	}
+
+	start := time.Now()
+	response, err := l.execution.GetRegisterValues(
+		context.Background(),
+		&executiondata.GetRegisterValuesRequest{
+			BlockHeight: l.height,
+			RegisterIds: registerIDs,
+		},
+	)
+	l.collector.RegistersFetched(len(ids), start)
+
+	if err != nil {
+		errorCode := status.Code(err)
+		if len(ids) > 1 || (errorCode != codes.NotFound && errorCode != codes.OutOfRange) {
+			return nil, err
+		}
+		// the height not being indexed yet is not cached, since it changes
+		if errorCode == codes.NotFound {
+			l.add(ids[0], nil)
+		}
+		return [][]byte{nil}, nil
+	}
+
+	if len(response.Values) != len(ids) {
+		return nil, fmt.Errorf(
+			"received %d register values for %d requested registers",
+			len(response.Values),
+			len(ids),
+		)
+	}
+
+	for i, id := range ids {
+		l.add(id, response.Values[i])
+	}
+
+	return response.Values, nil
+}
+
+// siblings returns the slab registers following the slab register of the same
+// owner, which are known to exist and aren't cached yet.
+func (l *remoteLedger) siblings(id flow.RegisterID) []flow.RegisterID {
+	// the prefetched registers are only useful if they are cached
+	if l.cache == nil || !id.IsSlabIndex() {
+		return nil
+	}
+
+	next, err := l.nextSlabIndex(id.Owner)
+	if err != nil {
+		// prefetching is only an optimization
+		return nil
+	}
+
+	index := binary.BigEndian.Uint64([]byte(id.Key[1:]))
+	siblings := make([]flow.RegisterID, 0, registerPrefetchCount)
+	for i := index + 1; i < next && len(siblings) < registerPrefetchCount; i++ {
+		var slabIndex atree.SlabIndex
+		binary.BigEndian.PutUint64(slabIndex[:], i)
+		sibling := flow.RegisterID{
+			Owner: id.Owner,
+			Key:   string(atree.SlabIndexToLedgerKey(slabIndex)),
+		}
+		if !l.cache.Contains(registerCacheKey{height: l.height, id: sibling}) {
+			siblings = append(siblings, sibling)
+		}
+	}
+
+	return siblings
+}
+
+// nextSlabIndex returns the next slab index of the owner, read from the account status.
+func (l *remoteLedger) nextSlabIndex(owner string) (uint64, error) {
+	if index, ok := l.slabIndexes[owner]; ok {
+		return index, nil
+	}
+
+	data, err := l.GetValue([]byte(owner), []byte(flow.AccountStatusKey))
+	if err != nil {
+		return 0, err
+	}
+
+	var index uint64
+	if len(data) != 0 {
+		accountStatus, err := environment.AccountStatusFromBytes(data)
+		if err != nil {
+			return 0, err
+		}
+		slabIndex := accountStatus.SlabIndex()
+		index = binary.BigEndian.Uint64(slabIndex[:])
+	}
+
+	l.slabIndexes[owner] = index
+	return index, nil
+}
+
+func (l *remoteLedger) cached(id flow.RegisterID) ([]byte, bool) {
+	if l.cache == nil {
+		return nil, false
+	}
+
+	value, ok := l.cache.Get(registerCacheKey{height: l.height, id: id})
+	if ok {
+		l.collector.RegisterCacheHit()
+	} else {
+		l.collector.RegisterCacheMiss()
+	}
+	return value, ok
+}
+
+func (l *remoteLedger) add(id flow.RegisterID, value []byte) {
+	if l.cache == nil {
+		return
+	}
+	l.cache.Add(registerCacheKey{height: l.height, id: id}, value)
+}
@@ -2,19 +2,28 @@ package requester
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
+	"maps"
 	"os"
 	"testing"
 
+	"github.com/onflow/atree"
 	grpcClient "github.com/onflow/flow-go-sdk/access/grpc"
+	"github.com/onflow/flow-go/fvm/environment"
 	"github.com/onflow/flow-go/fvm/evm"
 	"github.com/onflow/flow-go/fvm/evm/emulator/state"
 	"github.com/onflow/flow-go/fvm/evm/types"
 	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow/protobuf/go/flow/executiondata"
 	gethCommon "github.com/onflow/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-evm-gateway/metrics"
 )
 
 var previewnetStorageAddress = evm.StorageAccountAddress(flowGo.Previewnet)
@@ -67,7 +76,7 @@ func Benchmark_RemoteLedger_GetBalance(b *testing.B) {
 	// will be done only once per height, all the subsequent requests for
 	// getting the balance will work on already loaded state and thus be fast
 	for i := 0; i < b.N; i++ {
-		ledger, err := newRemoteLedger(execClient, latest.Height)
+		ledger, err := newRemoteLedger(execClient, latest.Height, nil, metrics.NopCollector)
 		require.NoError(b, err)
 
 		stateDB, err := state.NewStateDB(ledger, previewnetStorageAddress)
@@ -95,5 +104,144 @@ func newPreviewnetLedger(host string) (*remoteLedger, error) {
 		return nil, err
 	}
 
-	return newRemoteLedger(execClient, latest.Height)
+	return newRemoteLedger(execClient, latest.Height, nil, metrics.NopCollector)
+}
+
+func Test_RemoteLedgerCache(t *testing.T) {
+	owner := string(previewnetStorageAddress.Bytes())
+	slab := func(i uint64) flowGo.RegisterID {
+		var index atree.SlabIndex
+		binary.BigEndian.PutUint64(index[:], i)
+		return flowGo.RegisterID{Owner: owner, Key: string(atree.SlabIndexToLedgerKey(index))}
+	}
+
+	// the account has the slab registers 1 to 3 allocated
+	status := environment.NewAccountStatus()
+	status.SetStorageIndex(atree.SlabIndex{0, 0, 0, 0, 0, 0, 0, 4})
+	registers := map[flowGo.RegisterID][]byte{
+		flowGo.AccountStatusRegisterID(flowGo.BytesToAddress([]byte(owner))): status.ToBytes(),
+		slab(1): {1},
+		slab(2): {2},
+		slab(3): {3},
+	}
+
+	t.Run("cached values are not fetched again", func(t *testing.T) {
+		client := newTestExecutionDataClient(registers)
+		cache, err := newRegisterCache(100)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			ledger, err := newRemoteLedger(client, 10, cache, metrics.NopCollector)
+			require.NoError(t, err)
+
+			value, err := ledger.GetValue([]byte(owner), []byte("code"))
+			require.NoError(t, err)
+			assert.Nil(t, value)
+		}
+		assert.Len(t, client.requests, 1)
+
+		// the values aren't shared across heights
+		ledger, err := newRemoteLedger(client, 11, cache, metrics.NopCollector)
+		require.NoError(t, err)
+		_, err = ledger.GetValue([]byte(owner), []byte("code"))
+		require.NoError(t, err)
+		assert.Len(t, client.requests, 2)
+	})
+
+	t.Run("slab registers are prefetched", func(t *testing.T) {
+		client := newTestExecutionDataClient(registers)
+		cache, err := newRegisterCache(100)
+		require.NoError(t, err)
+
+		ledger, err := newRemoteLedger(client, 10, cache, metrics.NopCollector)
+		require.NoError(t, err)
+
+		id := slab(1)
+		value, err := ledger.GetValue([]byte(id.Owner), []byte(id.Key))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, value)
+
+		// the account status and then the slab with the allocated siblings
+		require.Len(t, client.requests, 2)
+		assert.Equal(t, []flowGo.RegisterID{slab(1), slab(2), slab(3)}, client.requests[1])
+
+		for i := uint64(2); i <= 3; i++ {
+			id := slab(i)
+			value, err := ledger.GetValue([]byte(id.Owner), []byte(id.Key))
+			require.NoError(t, err)
+			assert.Equal(t, []byte{byte(i)}, value)
+		}
+		assert.Len(t, client.requests, 2)
+	})
+
+	t.Run("missing prefetched register falls back to a single fetch", func(t *testing.T) {
+		incomplete := maps.Clone(registers)
+		delete(incomplete, slab(3))
+		client := newTestExecutionDataClient(incomplete)
+		cache, err := newRegisterCache(100)
+		require.NoError(t, err)
+
+		ledger, err := newRemoteLedger(client, 10, cache, metrics.NopCollector)
+		require.NoError(t, err)
+
+		id := slab(2)
+		value, err := ledger.GetValue([]byte(id.Owner), []byte(id.Key))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, value)
+
+		require.Len(t, client.requests, 3)
+		assert.Equal(t, []flowGo.RegisterID{slab(2), slab(3)}, client.requests[1])
+		assert.Equal(t, []flowGo.RegisterID{slab(2)}, client.requests[2])
+	})
+
+	t.Run("no prefetching without cache", func(t *testing.T) {
+		client := newTestExecutionDataClient(registers)
+
+		ledger, err := newRemoteLedger(client, 10, nil, metrics.NopCollector)
+		require.NoError(t, err)
+
+		id := slab(1)
+		for i := 0; i < 2; i++ {
+			value, err := ledger.GetValue([]byte(id.Owner), []byte(id.Key))
+			require.NoError(t, err)
+			assert.Equal(t, []byte{1}, value)
+		}
+		assert.Equal(t, [][]flowGo.RegisterID{{slab(1)}, {slab(1)}}, client.requests)
+	})
+}
+
+// testExecutionDataClient serves the register values from memory, and records
+// the requested registers. Like the access node, it fails the whole request if
+// any of the requested registers doesn't exist.
+type testExecutionDataClient struct {
+	executiondata.ExecutionDataAPIClient
+	registers map[flowGo.RegisterID][]byte
+	requests  [][]flowGo.RegisterID
+}
+
+func newTestExecutionDataClient(registers map[flowGo.RegisterID][]byte) *testExecutionDataClient {
+	return &testExecutionDataClient{registers: registers}
+}
+
+func (c *testExecutionDataClient) GetRegisterValues(
+	_ context.Context,
+	req *executiondata.GetRegisterValuesRequest,
+	_ ...grpc.CallOption,
+) (*executiondata.GetRegisterValuesResponse, error) {
+	ids := make([]flowGo.RegisterID, len(req.RegisterIds))
+	values := make([][]byte, len(req.RegisterIds))
+	for i, id := range req.RegisterIds {
+		ids[i] = flowGo.RegisterID{Owner: string(id.Owner), Key: string(id.Key)}
+	}
+	c.requests = append(c.requests, ids)
+
+	for i, id := range ids {
+		value, ok := c.registers[id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "register %s not found", id)
+		}
+		values[i] = value
+	}
+
+	return &executiondata.GetRegisterValuesResponse{Values: values}, nil
 }
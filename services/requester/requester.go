@@ -8,15 +8,16 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/onflow/atree"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/grpc"
-	"github.com/onflow/flow-go-sdk/crypto"
 	"github.com/onflow/flow-go/fvm/evm"
 	"github.com/onflow/flow-go/fvm/evm/emulator"
 	"github.com/onflow/flow-go/fvm/evm/emulator/state"
@@ -26,6 +27,7 @@ import (
 	"github.com/onflow/go-ethereum/common"
 	"github.com/onflow/go-ethereum/core/txpool"
 	"github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/rpc"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
 
@@ -43,6 +45,9 @@ var (
 	//go:embed cadence/run.cdc
 	runTxScript []byte
 
+	//go:embed cadence/batch_run.cdc
+	batchRunTxScript []byte
+
 	//go:embed cadence/get_balance.cdc
 	getBalanceScript []byte
 
@@ -82,6 +87,9 @@ const coaFundingBalance = minFlowBalance - 1
 
 const LatestBlockHeight uint64 = math.MaxUint64 - 1
 
+// queueCheckInterval is the interval the queued transactions are expired and released at.
+const queueCheckInterval = time.Second
+
 type Requester interface {
 	// SendRawTransaction will submit signed transaction data to the network.
 	// The submitted EVM transaction hash is returned.
@@ -94,12 +102,28 @@ type Requester interface {
 	// Call executes the given signed transaction data on the state for the given EVM block height.
 	// Note, this function doesn't make and changes in the state/blockchain and is
 	// useful to execute and retrieve values.
-	Call(ctx context.Context, data []byte, from common.Address, evmHeight int64) ([]byte, error)
+	// If any state or block overrides are provided, they are applied before the execution.
+	Call(
+		ctx context.Context,
+		data []byte,
+		from common.Address,
+		evmHeight int64,
+		stateOverrides models.StateOverride,
+		blockOverrides *models.BlockOverrides,
+	) ([]byte, error)
 
 	// EstimateGas executes the given signed transaction data on the state for the given EVM block height.
 	// Note, this function doesn't make any changes in the state/blockchain and is
 	// useful to executed and retrieve the gas consumption and possible failures.
-	EstimateGas(ctx context.Context, data []byte, from common.Address, evmHeight int64) (uint64, error)
+	// If any state or block overrides are provided, they are applied before the execution.
+	EstimateGas(
+		ctx context.Context,
+		data []byte,
+		from common.Address,
+		evmHeight int64,
+		stateOverrides models.StateOverride,
+		blockOverrides *models.BlockOverrides,
+	) (uint64, error)
 
 	// GetNonce gets nonce from the network at the given EVM block height.
 	GetNonce(ctx context.Context, address common.Address, evmHeight int64) (uint64, error)
@@ -111,8 +135,36 @@ type Requester interface {
 	// GetLatestEVMHeight returns the latest EVM height of the network.
 	GetLatestEVMHeight(ctx context.Context) (uint64, error)
 
+	// GetSealedEVMHeight returns the height of the latest indexed EVM block,
+	// which is included in a sealed Cadence block.
+	GetSealedEVMHeight(ctx context.Context) (uint64, error)
+
+	// GetPendingNonce returns the nonce of the address, given its nonce at the latest
+	// EVM block height, including the submitted transactions that are not yet sealed.
+	GetPendingNonce(address common.Address, nonce uint64) uint64
+
 	// GetStorageAt returns the storage from the state at the given address, key and block number.
 	GetStorageAt(ctx context.Context, address common.Address, hash common.Hash, evmHeight int64) (common.Hash, error)
+
+	// CreateAccessList executes the given transaction data on the state for the given EVM
+	// block height and returns the access list of all the accounts and storage slots touched
+	// by the transaction, together with the execution result using that access list.
+	CreateAccessList(
+		ctx context.Context,
+		data []byte,
+		from common.Address,
+		evmHeight int64,
+	) (types.AccessList, *evmTypes.Result, error)
+
+	// Simulate executes the blocks of calls on top of the state for the given EVM block height,
+	// where each call observes the state changes of all the previous calls, and returns the
+	// simulated blocks together with the results of their calls.
+	Simulate(
+		ctx context.Context,
+		blocks []SimulateBlock,
+		evmHeight int64,
+		traceTransfers bool,
+	) ([]*SimulatedBlock, error)
 }
 
 var _ Requester = &EVM{}
@@ -120,12 +172,17 @@ var _ Requester = &EVM{}
 type EVM struct {
 	client      *CrossSporkClient
 	config      *config.Config
-	signer      crypto.Signer
+	keys        *KeyPool
 	txPool      *TxPool
+	gasPrice    GasPriceOracle
 	logger      zerolog.Logger
 	blocks      storage.BlockIndexer
-	mux         sync.Mutex
+	registers   storage.RegisterIndexer
 	scriptCache *expirable.LRU[string, cadence.Value]
+	// registerCache is shared by the remote ledgers used for local execution
+	registerCache *registerCache
+	// releasing contains the senders of which queued transactions are being released
+	releasing sync.Map
 
 	head              *types.Header
 	evmSigner         types.Signer
@@ -135,12 +192,15 @@ type EVM struct {
 }
 
 func NewEVM(
+	ctx context.Context,
 	client *CrossSporkClient,
 	config *config.Config,
-	signer crypto.Signer,
+	keys *KeyPool,
 	logger zerolog.Logger,
 	blocks storage.BlockIndexer,
+	registers storage.RegisterIndexer,
 	txPool *TxPool,
+	gasPrice GasPriceOracle,
 	collector metrics.Collector,
 ) (*EVM, error) {
 	logger = logger.With().Str("component", "requester").Logger()
@@ -195,10 +255,12 @@ func NewEVM(
 	evm := &EVM{
 		client:            client,
 		config:            config,
-		signer:            signer,
+		keys:              keys,
 		logger:            logger,
 		blocks:            blocks,
+		registers:         registers,
 		txPool:            txPool,
+		gasPrice:          gasPrice,
 		head:              head,
 		evmSigner:         evmSigner,
 		validationOptions: validationOptions,
@@ -206,6 +268,13 @@ func NewEVM(
 		scriptCache:       cache,
 	}
 
+	if config.RegisterCacheSize != 0 {
+		evm.registerCache, err = newRegisterCache(int(config.RegisterCacheSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create register cache: %w", err)
+		}
+	}
+
 	// create COA on the account
 	if config.CreateCOAResource {
 		tx, err := evm.buildTransaction(
@@ -217,12 +286,17 @@ func NewEVM(
 			logger.Warn().Err(err).Msg("COA resource auto-creation failure")
 			return nil, fmt.Errorf("COA resource auto-creation failure: %w", err)
 		}
-		if err := evm.client.SendTransaction(context.Background(), *tx); err != nil {
+		// the pool releases the proposal key once the flow transaction is sealed
+		if err := evm.txPool.SendCadence(context.Background(), tx); err != nil {
 			logger.Warn().Err(err).Msg("failed to send COA resource auto-creation transaction")
 			return nil, fmt.Errorf("failed to send COA resource auto-creation transaction: %w", err)
 		}
 	}
 
+	if config.TxQueueLimit > 0 {
+		go evm.queueChecker(ctx)
+	}
+
 	return evm, nil
 }
 
@@ -241,29 +315,36 @@ func (e *EVM) SendRawTransaction(ctx context.Context, data []byte) (common.Hash,
 		return common.Hash{}, fmt.Errorf("failed to derive the sender: %w", err)
 	}
 
-	if tx.GasPrice().Cmp(e.config.GasPrice) < 0 {
-		return common.Hash{}, errs.NewTxGasPriceTooLowError(e.config.GasPrice)
-	}
-
-	txData := hex.EncodeToString(data)
-	hexEncodedTx, err := cadence.NewString(txData)
+	minGasPrice, err := e.gasPrice.MinGasPrice()
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, fmt.Errorf("failed to get the minimum gas price: %w", err)
 	}
-	coinbaseAddress, err := cadence.NewString(e.config.Coinbase.Hex())
-	if err != nil {
-		return common.Hash{}, err
+	if tx.GasPrice().Cmp(minGasPrice) < 0 {
+		return common.Hash{}, errs.NewTxGasPriceTooLowError(minGasPrice)
 	}
 
-	script := e.replaceAddresses(runTxScript)
-	flowTx, err := e.buildTransaction(ctx, script, hexEncodedTx, coinbaseAddress)
+	if e.config.TxQueueLimit > 0 {
+		queued, err := e.queueFutureNonce(ctx, from, tx)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if queued {
+			e.logger.Info().
+				Str("evm-id", tx.Hash().Hex()).
+				Str("from", from.Hex()).
+				Uint64("nonce", tx.Nonce()).
+				Msg("raw transaction queued")
+			return tx.Hash(), nil
+		}
+	}
+
+	flowID, err := e.submit(ctx, tx)
 	if err != nil {
-		e.logger.Error().Err(err).Str("data", txData).Msg("failed to build transaction")
 		return common.Hash{}, err
 	}
 
-	if err := e.txPool.Send(ctx, flowTx, tx); err != nil {
-		return common.Hash{}, err
+	if e.config.TxQueueLimit > 0 {
+		go e.releaseQueued(from)
 	}
 
 	var to string
@@ -272,63 +353,285 @@ func (e *EVM) SendRawTransaction(ctx context.Context, data []byte) (common.Hash,
 		e.collector.EVMAccountInteraction(to)
 	}
 
-	e.logger.Info().
+	event := e.logger.Info().
 		Str("evm-id", tx.Hash().Hex()).
-		Str("flow-id", flowTx.ID().Hex()).
 		Str("to", to).
 		Str("from", from.Hex()).
-		Str("value", tx.Value().String()).
-		Msg("raw transaction sent")
+		Str("value", tx.Value().String())
+	// the batched transactions are submitted later, in the flow transaction of the batch
+	if flowID != flow.EmptyID {
+		event = event.Str("flow-id", flowID.Hex())
+	}
+	event.Msg("raw transaction sent")
 
 	return tx.Hash(), nil
 }
 
+// submit submits the validated EVM transaction, either in its own flow transaction or
+// in a batch, and returns the ID of its own flow transaction. The ID is empty if the
+// transaction is batched, since the flow transaction of the batch is built later.
+func (e *EVM) submit(ctx context.Context, tx *types.Transaction) (flow.Identifier, error) {
+	if e.config.TxBatchSize > 1 {
+		return flow.EmptyID, e.txPool.SendBatched(
+			ctx,
+			tx,
+			e.buildBatchTransaction,
+			e.rebuilder(e.buildBatchTransaction),
+		)
+	}
+
+	flowTx, err := e.buildRunTransaction(ctx, []*types.Transaction{tx})
+	if err != nil {
+		e.logger.Error().Err(err).Str("evm-id", tx.Hash().Hex()).Msg("failed to build transaction")
+		return flow.EmptyID, err
+	}
+
+	if err := e.txPool.Send(ctx, flowTx, tx, e.rebuilder(e.buildRunTransaction)); err != nil {
+		return flow.EmptyID, err
+	}
+
+	return flowTx.ID(), nil
+}
+
+// buildRunTransaction builds the flow transaction executing the single EVM transaction.
+func (e *EVM) buildRunTransaction(ctx context.Context, txs []*types.Transaction) (*flow.Transaction, error) {
+	data, err := txs[0].MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	hexEncodedTx, err := cadence.NewString(hex.EncodeToString(data))
+	if err != nil {
+		return nil, err
+	}
+	coinbaseAddress, err := cadence.NewString(e.config.Coinbase.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	script := e.replaceAddresses(runTxScript)
+	return e.buildTransaction(ctx, script, hexEncodedTx, coinbaseAddress)
+}
+
+// rebuilder returns the rebuilder of the flow transactions built by the builder, which leaves
+// out the EVM transactions with a nonce already used by an executed transaction.
+func (e *EVM) rebuilder(build BatchBuilder) Rebuilder {
+	return func(ctx context.Context, txs []*types.Transaction) (*flow.Transaction, map[common.Hash]bool, error) {
+		executed := make(map[common.Hash]bool)
+		remaining := make([]*types.Transaction, 0, len(txs))
+		for _, tx := range txs {
+			from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to derive the sender: %w", err)
+			}
+			nonce, err := e.GetNonce(ctx, from, int64(rpc.LatestBlockNumber))
+			if err != nil {
+				return nil, nil, err
+			}
+			if tx.Nonce() < nonce {
+				executed[tx.Hash()] = true
+				continue
+			}
+			remaining = append(remaining, tx)
+		}
+
+		if len(remaining) == 0 {
+			return nil, executed, nil
+		}
+
+		flowTx, err := build(ctx, remaining)
+		return flowTx, executed, err
+	}
+}
+
+// nextNonce returns the nonce of the next transaction of the sender, which follows
+// the executed transactions and the transactions being submitted.
+func (e *EVM) nextNonce(ctx context.Context, from common.Address) (uint64, error) {
+	nonce, err := e.GetNonce(ctx, from, int64(rpc.LatestBlockNumber))
+	if err != nil {
+		return 0, err
+	}
+
+	return e.txPool.PendingNonce(from, nonce), nil
+}
+
+// queueFutureNonce queues the transaction if its nonce is ahead of the next nonce of the sender,
+// since the transaction would be rejected until the transactions filling the nonce gap are executed.
+func (e *EVM) queueFutureNonce(ctx context.Context, from common.Address, tx *types.Transaction) (bool, error) {
+	next, err := e.nextNonce(ctx, from)
+	if err != nil {
+		return false, err
+	}
+	if tx.Nonce() <= next {
+		return false, nil
+	}
+
+	return true, e.txPool.Queue(from, tx)
+}
+
+// releaseQueued submits the queued transactions of the sender in the nonce order,
+// as long as the queued transaction follows the next nonce of the sender.
+func (e *EVM) releaseQueued(from common.Address) {
+	// only one release of the sender is running at a time
+	if _, running := e.releasing.LoadOrStore(from, struct{}{}); running {
+		return
+	}
+	defer e.releasing.Delete(from)
+
+	ctx := context.Background()
+	// the next nonce is only looked up once, and then follows the submitted transactions
+	next, err := e.nextNonce(ctx, from)
+	if err != nil {
+		e.logger.Warn().Err(err).Str("from", from.Hex()).Msg("failed to get the next nonce of queued transactions")
+		return
+	}
+
+	for {
+		tx, ok := e.txPool.TakeQueued(from, next)
+		if !ok {
+			return
+		}
+
+		flowID, err := e.submit(ctx, tx)
+		if err != nil {
+			e.logger.Warn().Err(err).
+				Str("evm-id", tx.Hash().Hex()).
+				Str("from", from.Hex()).
+				Msg("failed to submit queued transaction")
+			// the transaction is no longer queued, so the failure is reported by its status
+			e.txPool.RecordFailure(tx, err)
+			return
+		}
+
+		event := e.logger.Info().
+			Str("evm-id", tx.Hash().Hex()).
+			Str("from", from.Hex()).
+			Uint64("nonce", tx.Nonce())
+		if flowID != flow.EmptyID {
+			event = event.Str("flow-id", flowID.Hex())
+		}
+		event.Msg("queued transaction sent")
+
+		next = tx.Nonce() + 1
+	}
+}
+
+// queueChecker periodically expires the queued transactions, and releases the
+// queued transactions of which nonce gap was filled, for example by the transactions
+// submitted through another gateway, until the context is cancelled. The nonces of
+// the senders only change with the indexed blocks, so the queued transactions are
+// only released if a new block was indexed since the last check.
+func (e *EVM) queueChecker(ctx context.Context) {
+	ticker := time.NewTicker(queueCheckInterval)
+	defer ticker.Stop()
+
+	var checkedHeight uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, tx := range e.txPool.ExpireQueued() {
+			e.logger.Info().
+				Str("evm-id", tx.Hash().Hex()).
+				Uint64("nonce", tx.Nonce()).
+				Msg("queued transaction expired")
+		}
+
+		if e.blocks != nil {
+			height, err := e.blocks.LatestEVMHeight()
+			if err != nil {
+				e.logger.Warn().Err(err).Msg("failed to get the latest height to release queued transactions")
+			} else if height == checkedHeight {
+				continue
+			}
+			checkedHeight = height
+		}
+
+		for from := range e.txPool.Queued() {
+			go e.releaseQueued(from)
+		}
+	}
+}
+
+// buildBatchTransaction creates a flow transaction executing the batch of EVM transactions
+// and signs it with the configured COA account.
+func (e *EVM) buildBatchTransaction(ctx context.Context, txs []*types.Transaction) (*flow.Transaction, error) {
+	hexEncodedTxs := make([]cadence.Value, len(txs))
+	for i, tx := range txs {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transaction: %s, with: %w", tx.Hash(), err)
+		}
+		hexEncodedTxs[i], err = cadence.NewString(hex.EncodeToString(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	coinbaseAddress, err := cadence.NewString(e.config.Coinbase.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	script := e.replaceAddresses(batchRunTxScript)
+	return e.buildTransaction(
+		ctx,
+		script,
+		cadence.NewArray(hexEncodedTxs).WithType(cadence.NewVariableSizedArrayType(cadence.StringType)),
+		coinbaseAddress,
+	)
+}
+
 // buildTransaction creates a flow transaction from the provided script with the arguments
 // and signs it with the configured COA account.
 func (e *EVM) buildTransaction(ctx context.Context, script []byte, args ...cadence.Value) (*flow.Transaction, error) {
-	// building and signing transactions should be blocking, so we don't have keys conflict
-	e.mux.Lock()
-	defer e.mux.Unlock()
-
 	var (
 		g           = errgroup.Group{}
-		err1, err2  error
 		latestBlock *flow.Block
-		index       uint32
-		seqNum      uint64
+		key         *Key
 	)
 	// execute concurrently so we can speed up all the information we need for tx
 	g.Go(func() error {
-		latestBlock, err1 = e.client.GetLatestBlock(ctx, true)
-		return err1
+		var err error
+		latestBlock, err = e.client.GetLatestBlock(ctx, true)
+		return err
 	})
 	g.Go(func() error {
-		index, seqNum, err2 = e.getSignerNetworkInfo(ctx)
-		return err2
+		// the key is leased to the submission until the flow transaction is sealed,
+		// so the concurrent submissions don't conflict on the sequence number
+		var err error
+		key, err = e.keys.Lease(ctx)
+		return err
 	})
 	if err := g.Wait(); err != nil {
+		if key != nil {
+			e.keys.Release(key.Index, false)
+		}
 		return nil, err
 	}
 
 	address := e.config.COAAddress
 	flowTx := flow.NewTransaction().
 		SetScript(script).
-		SetProposalKey(address, index, seqNum).
+		SetProposalKey(address, key.Index, key.SequenceNumber).
 		SetReferenceBlockID(latestBlock.ID).
 		SetPayer(address).
 		AddAuthorizer(address)
 
 	for _, arg := range args {
 		if err := flowTx.AddArgument(arg); err != nil {
+			e.keys.Release(key.Index, false)
 			return nil, fmt.Errorf("failed to add argument: %s, with %w", arg, err)
 		}
 	}
 
-	if err := flowTx.SignEnvelope(address, index, e.signer); err != nil {
+	if err := flowTx.SignEnvelope(address, key.Index, key.Signer); err != nil {
+		e.keys.Release(key.Index, false)
 		return nil, fmt.Errorf(
 			"failed to sign transaction envelope for address: %s and index: %d, with: %w",
 			address,
-			index,
+			key.Index,
 			err)
 	}
 
@@ -340,6 +643,28 @@ func (e *EVM) GetBalance(
 	address common.Address,
 	evmHeight int64,
 ) (*big.Int, error) {
+	var indexedBalance *big.Int
+	err := e.readIndexedState(evmHeight, func(stateDB *state.StateDB) {
+		indexedBalance = stateDB.GetBalance(address).ToBig()
+	})
+	if err == nil {
+		return indexedBalance, nil
+	}
+	if errors.Is(err, errs.ErrHeightNotArchived) {
+		return nil, err
+	}
+	if !errors.Is(err, errStateNotIndexed) {
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("indexed get balance failed, falling back")
+	}
+
+	if e.config.LocalExecution {
+		balance, err := e.localBalance(address, evmHeight)
+		if !fallbackToScript(err) {
+			return balance, err
+		}
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("local get balance failed, falling back to script")
+	}
+
 	hexEncodedAddress, err := addressToCadenceString(address)
 	if err != nil {
 		return nil, err
@@ -386,6 +711,28 @@ func (e *EVM) GetNonce(
 	address common.Address,
 	evmHeight int64,
 ) (uint64, error) {
+	var indexedNonce uint64
+	err := e.readIndexedState(evmHeight, func(stateDB *state.StateDB) {
+		indexedNonce = stateDB.GetNonce(address)
+	})
+	if err == nil {
+		return indexedNonce, nil
+	}
+	if errors.Is(err, errs.ErrHeightNotArchived) {
+		return 0, err
+	}
+	if !errors.Is(err, errStateNotIndexed) {
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("indexed get nonce failed, falling back")
+	}
+
+	if e.config.LocalExecution {
+		nonce, err := e.localNonce(address, evmHeight)
+		if !fallbackToScript(err) {
+			return nonce, err
+		}
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("local get nonce failed, falling back to script")
+	}
+
 	hexEncodedAddress, err := addressToCadenceString(address)
 	if err != nil {
 		return 0, err
@@ -435,30 +782,55 @@ func (e *EVM) GetNonce(
 }
 
 func (e *EVM) stateAt(evmHeight int64) (*state.StateDB, error) {
-	cadenceHeight, err := e.evmToCadenceHeight(evmHeight)
+	ledger, _, err := e.ledgerAt(evmHeight)
 	if err != nil {
 		return nil, err
 	}
 
-	if cadenceHeight == LatestBlockHeight {
-		h, err := e.client.GetLatestBlockHeader(context.Background(), true)
-		if err != nil {
-			return nil, err
-		}
-		cadenceHeight = h.Height
+	storageAddress := evm.StorageAccountAddress(e.config.FlowNetworkID)
+	return state.NewStateDB(ledger, storageAddress)
+}
+
+// ledgerAt returns a read-only ledger of the EVM storage account registers
+// at the Cadence height mapped from the provided EVM height, together with
+// the resolved Cadence height.
+func (e *EVM) ledgerAt(evmHeight int64) (atree.Ledger, uint64, error) {
+	ledger, cadenceHeight, err := e.archiveLedgerAt(evmHeight)
+	if !errors.Is(err, errStateNotIndexed) {
+		return ledger, cadenceHeight, err
+	}
+
+	cadenceHeight, err = e.resolveCadenceHeight(context.Background(), evmHeight)
+	if err != nil {
+		return nil, 0, err
 	}
 
+	remote, err := e.remoteLedgerAt(cadenceHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return remote, cadenceHeight, nil
+}
+
+// remoteLedgerAt returns a read-only ledger of the registers fetched
+// from the execution data API at the Cadence height.
+func (e *EVM) remoteLedgerAt(cadenceHeight uint64) (*remoteLedger, error) {
 	exeClient, ok := e.client.Client.(*grpc.Client)
 	if !ok {
 		return nil, fmt.Errorf("could not convert to execution client")
 	}
-	ledger, err := newRemoteLedger(exeClient.ExecutionDataRPCClient(), cadenceHeight)
+	ledger, err := newRemoteLedger(
+		exeClient.ExecutionDataRPCClient(),
+		cadenceHeight,
+		e.registerCache,
+		e.collector,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("could not create remote ledger for height: %d, with: %w", cadenceHeight, err)
 	}
 
-	storageAddress := evm.StorageAccountAddress(e.config.FlowNetworkID)
-	return state.NewStateDB(ledger, storageAddress)
+	return ledger, nil
 }
 
 func (e *EVM) GetStorageAt(
@@ -467,6 +839,20 @@ func (e *EVM) GetStorageAt(
 	hash common.Hash,
 	evmHeight int64,
 ) (common.Hash, error) {
+	var indexedValue common.Hash
+	err := e.readIndexedState(evmHeight, func(stateDB *state.StateDB) {
+		indexedValue = stateDB.GetState(address, hash)
+	})
+	if err == nil {
+		return indexedValue, nil
+	}
+	if errors.Is(err, errs.ErrHeightNotArchived) {
+		return common.Hash{}, err
+	}
+	if !errors.Is(err, errStateNotIndexed) {
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("indexed get storage failed, falling back")
+	}
+
 	stateDB, err := e.stateAt(evmHeight)
 	if err != nil {
 		return common.Hash{}, err
@@ -481,76 +867,205 @@ func (e *EVM) Call(
 	data []byte,
 	from common.Address,
 	evmHeight int64,
+	stateOverrides models.StateOverride,
+	blockOverrides *models.BlockOverrides,
 ) ([]byte, error) {
-	hexEncodedTx, err := cadence.NewString(hex.EncodeToString(data))
+	var (
+		evmResult *evmTypes.ResultSummary
+		height    uint64
+		err       error
+	)
+	switch {
+	case stateOverrides != nil || blockOverrides != nil:
+		evmResult, height, err = e.executeLocalDryRun(data, from, evmHeight, stateOverrides, blockOverrides)
+	case e.config.LocalExecution || e.config.ArchiveMode:
+		evmResult, height, err = e.executeLocalDryRun(data, from, evmHeight, nil, nil)
+		if fallbackToScript(err) {
+			e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("local call failed, falling back to script")
+			evmResult, height, err = e.executeDryRun(ctx, data, from, evmHeight)
+		}
+	default:
+		evmResult, height, err = e.executeDryRun(ctx, data, from, evmHeight)
+	}
 	if err != nil {
+		if !errors.Is(err, errs.ErrHeightOutOfRange) && !isResultError(err) {
+			e.logger.Error().
+				Err(err).
+				Uint64("cadence-height", height).
+				Int64("evm-height", evmHeight).
+				Str("from", from.String()).
+				Str("data", hex.EncodeToString(data)).
+				Msg("failed to execute call")
+		}
 		return nil, err
 	}
 
-	hexEncodedAddress, err := addressToCadenceString(from)
-	if err != nil {
-		return nil, err
+	result := evmResult.ReturnedData
+
+	e.logger.Debug().
+		Str("result", hex.EncodeToString(result)).
+		Int64("evm-height", evmHeight).
+		Uint64("cadence-height", height).
+		Msg("call executed")
+
+	return result, nil
+}
+
+func (e *EVM) EstimateGas(
+	ctx context.Context,
+	data []byte,
+	from common.Address,
+	evmHeight int64,
+	stateOverrides models.StateOverride,
+	blockOverrides *models.BlockOverrides,
+) (uint64, error) {
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return 0, err
 	}
 
-	height, err := e.evmToCadenceHeight(evmHeight)
-	if err != nil {
-		return nil, err
+	// all the executions of the estimation must run on top of the same state
+	estimate := func(run dryRunFunc, height uint64, err error) (uint64, uint64, error) {
+		if err != nil {
+			return 0, height, err
+		}
+		gasLimit, err := newGasEstimator(tx, run).estimate(ctx, e.config.RPCGasCap)
+		return gasLimit, height, err
 	}
 
-	scriptResult, err := e.executeScriptAtHeight(
-		ctx,
-		dryRun,
-		height,
-		[]cadence.Value{hexEncodedTx, hexEncodedAddress},
+	var (
+		gasLimit uint64
+		height   uint64
+		err      error
 	)
+	switch {
+	case stateOverrides != nil || blockOverrides != nil:
+		gasLimit, height, err = estimate(e.localDryRunner(from, evmHeight, stateOverrides, blockOverrides))
+	case e.config.LocalExecution || e.config.ArchiveMode:
+		gasLimit, height, err = estimate(e.localDryRunner(from, evmHeight, nil, nil))
+		if fallbackToScript(err) {
+			e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("local estimate gas failed, falling back to script")
+			gasLimit, height, err = estimate(e.scriptDryRunner(ctx, from, evmHeight))
+		}
+	default:
+		gasLimit, height, err = estimate(e.scriptDryRunner(ctx, from, evmHeight))
+	}
 	if err != nil {
-		if !errors.Is(err, errs.ErrHeightOutOfRange) {
+		if !errors.Is(err, errs.ErrHeightOutOfRange) && !isResultError(err) {
 			e.logger.Error().
 				Err(err).
 				Uint64("cadence-height", height).
 				Int64("evm-height", evmHeight).
 				Str("from", from.String()).
 				Str("data", hex.EncodeToString(data)).
-				Msg("failed to execute call")
+				Msg("failed to execute estimateGas")
 		}
-		return nil, fmt.Errorf("failed to execute script at height: %d, with: %w", height, err)
+		return 0, err
 	}
 
-	evmResult, err := parseResult(scriptResult)
+	e.logger.Debug().
+		Uint64("gas", gasLimit).
+		Int64("evm-height", evmHeight).
+		Uint64("cadence-height", height).
+		Msg("estimateGas executed")
+
+	return gasLimit, nil
+}
+
+func (e *EVM) CreateAccessList(
+	ctx context.Context,
+	data []byte,
+	from common.Address,
+	evmHeight int64,
+) (types.AccessList, *evmTypes.Result, error) {
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, nil, err
+	}
+
+	executor, err := e.executorAt(evmHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accessList, res, err := executor.createAccessList(ctx, tx, from)
+	if err != nil {
+		if !errors.Is(err, errs.ErrFailedTransaction) {
+			e.logger.Error().
+				Err(err).
+				Uint64("cadence-height", executor.cadenceHeight).
+				Int64("evm-height", evmHeight).
+				Str("from", from.String()).
+				Str("data", hex.EncodeToString(data)).
+				Msg("failed to execute create access list")
+		}
+		return nil, nil, fmt.Errorf("failed to create access list: %w", err)
+	}
+
+	e.logger.Debug().
+		Int("access-list-size", len(accessList)).
+		Uint64("gas", res.GasConsumed).
+		Int64("evm-height", evmHeight).
+		Uint64("cadence-height", executor.cadenceHeight).
+		Msg("create access list executed")
+
+	return accessList, res, nil
+}
+
+func (e *EVM) Simulate(
+	ctx context.Context,
+	blocks []SimulateBlock,
+	evmHeight int64,
+	traceTransfers bool,
+) ([]*SimulatedBlock, error) {
+	executor, err := e.executorAt(evmHeight)
 	if err != nil {
 		return nil, err
 	}
 
-	result := evmResult.ReturnedData
+	simulated, err := executor.simulate(ctx, blocks, traceTransfers)
+	if err != nil {
+		if !isResultError(err) {
+			e.logger.Error().
+				Err(err).
+				Uint64("cadence-height", executor.cadenceHeight).
+				Int64("evm-height", evmHeight).
+				Int("blocks", len(blocks)).
+				Msg("failed to execute simulate")
+		}
+		return nil, err
+	}
 
 	e.logger.Debug().
-		Str("result", hex.EncodeToString(result)).
+		Int("blocks", len(simulated)).
 		Int64("evm-height", evmHeight).
-		Uint64("cadence-height", height).
-		Msg("call executed")
+		Uint64("cadence-height", executor.cadenceHeight).
+		Msg("simulate executed")
 
-	return result, nil
+	return simulated, nil
 }
 
-func (e *EVM) EstimateGas(
+// executeDryRun executes the transaction data using the dry run script at the Cadence height
+// mapped from the given EVM height, and returns the result with the Cadence height used.
+func (e *EVM) executeDryRun(
 	ctx context.Context,
 	data []byte,
 	from common.Address,
 	evmHeight int64,
-) (uint64, error) {
+) (*evmTypes.ResultSummary, uint64, error) {
 	hexEncodedTx, err := cadence.NewString(hex.EncodeToString(data))
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 
 	hexEncodedAddress, err := addressToCadenceString(from)
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 
 	height, err := e.evmToCadenceHeight(evmHeight)
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 
 	scriptResult, err := e.executeScriptAtHeight(
@@ -560,32 +1075,140 @@ func (e *EVM) EstimateGas(
 		[]cadence.Value{hexEncodedTx, hexEncodedAddress},
 	)
 	if err != nil {
-		if !errors.Is(err, errs.ErrHeightOutOfRange) {
-			e.logger.Error().
-				Err(err).
-				Uint64("cadence-height", height).
-				Int64("evm-height", evmHeight).
-				Str("from", from.String()).
-				Str("data", hex.EncodeToString(data)).
-				Msg("failed to execute estimateGas")
-		}
-		return 0, fmt.Errorf("failed to execute script at height: %d, with: %w", height, err)
+		return nil, height, fmt.Errorf("failed to execute script at height: %d, with: %w", height, err)
 	}
 
 	evmResult, err := parseResult(scriptResult)
+	return evmResult, height, err
+}
+
+// executeLocalDryRun executes the transaction data locally, on top of the state at the
+// given EVM height with the state and block overrides applied, and returns the result
+// with the Cadence height used.
+func (e *EVM) executeLocalDryRun(
+	data []byte,
+	from common.Address,
+	evmHeight int64,
+	stateOverrides models.StateOverride,
+	blockOverrides *models.BlockOverrides,
+) (*evmTypes.ResultSummary, uint64, error) {
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, 0, err
+	}
+
+	executor, err := e.executorAt(evmHeight)
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 
-	gasConsumed := evmResult.GasConsumed
+	if err := executor.applyStateOverrides(stateOverrides); err != nil {
+		return nil, executor.cadenceHeight, err
+	}
 
-	e.logger.Debug().
-		Uint64("gas", gasConsumed).
-		Int64("evm-height", evmHeight).
-		Uint64("cadence-height", height).
-		Msg("estimateGas executed")
+	blockContext, err := executor.blockContext()
+	if err != nil {
+		return nil, executor.cadenceHeight, err
+	}
+	if err := applyBlockOverrides(&blockContext, blockOverrides); err != nil {
+		return nil, executor.cadenceHeight, err
+	}
+
+	res, err := executor.dryRunWithContext(blockContext, tx, from)
+	if err != nil {
+		return nil, executor.cadenceHeight, fmt.Errorf(
+			"failed to execute locally at height: %d, with: %w",
+			executor.cadenceHeight,
+			err,
+		)
+	}
+
+	evmResult, err := checkResult(res.ResultSummary())
+	return evmResult, executor.cadenceHeight, err
+}
+
+// scriptDryRunner returns a dry run function which executes the dry run script at
+// the Cadence height mapped from the given EVM height. The latest height is resolved
+// upfront, so all the executions use the same height even if new blocks get sealed.
+func (e *EVM) scriptDryRunner(
+	ctx context.Context,
+	from common.Address,
+	evmHeight int64,
+) (dryRunFunc, uint64, error) {
+	hexEncodedAddress, err := addressToCadenceString(from)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	height, err := e.resolveCadenceHeight(ctx, evmHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	run := func(tx *types.Transaction) (*evmTypes.ResultSummary, error) {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		hexEncodedTx, err := cadence.NewString(hex.EncodeToString(data))
+		if err != nil {
+			return nil, err
+		}
+
+		scriptResult, err := e.executeScriptAtHeight(
+			ctx,
+			dryRun,
+			height,
+			[]cadence.Value{hexEncodedTx, hexEncodedAddress},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute script at height: %d, with: %w", height, err)
+		}
+
+		return decodeResult(scriptResult)
+	}
+
+	return run, height, nil
+}
 
-	return gasConsumed, nil
+// localDryRunner returns a dry run function which executes locally, on top of the
+// state at the given EVM height with the state and block overrides applied.
+func (e *EVM) localDryRunner(
+	from common.Address,
+	evmHeight int64,
+	stateOverrides models.StateOverride,
+	blockOverrides *models.BlockOverrides,
+) (dryRunFunc, uint64, error) {
+	executor, err := e.executorAt(evmHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := executor.applyStateOverrides(stateOverrides); err != nil {
+		return nil, executor.cadenceHeight, err
+	}
+
+	blockContext, err := executor.blockContext()
+	if err != nil {
+		return nil, executor.cadenceHeight, err
+	}
+	if err := applyBlockOverrides(&blockContext, blockOverrides); err != nil {
+		return nil, executor.cadenceHeight, err
+	}
+
+	run := func(tx *types.Transaction) (*evmTypes.ResultSummary, error) {
+		res, err := executor.dryRunWithContext(blockContext, tx, from)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to execute locally at height: %d, with: %w",
+				executor.cadenceHeight,
+				err,
+			)
+		}
+		return res.ResultSummary(), nil
+	}
+
+	return run, executor.cadenceHeight, nil
 }
 
 func (e *EVM) GetCode(
@@ -593,6 +1216,28 @@ func (e *EVM) GetCode(
 	address common.Address,
 	evmHeight int64,
 ) ([]byte, error) {
+	var indexedCode []byte
+	err := e.readIndexedState(evmHeight, func(stateDB *state.StateDB) {
+		indexedCode = stateDB.GetCode(address)
+	})
+	if err == nil {
+		return indexedCode, nil
+	}
+	if errors.Is(err, errs.ErrHeightNotArchived) {
+		return nil, err
+	}
+	if !errors.Is(err, errStateNotIndexed) {
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("indexed get code failed, falling back")
+	}
+
+	if e.config.LocalExecution {
+		code, err := e.localCode(address, evmHeight)
+		if !fallbackToScript(err) {
+			return code, err
+		}
+		e.logger.Warn().Err(err).Int64("evm-height", evmHeight).Msg("local get code failed, falling back to script")
+	}
+
 	hexEncodedAddress, err := addressToCadenceString(address)
 	if err != nil {
 		return nil, err
@@ -667,31 +1312,84 @@ func (e *EVM) GetLatestEVMHeight(ctx context.Context) (uint64, error) {
 	return height, nil
 }
 
-// getSignerNetworkInfo loads the signer account from network and returns key index and sequence number
-func (e *EVM) getSignerNetworkInfo(ctx context.Context) (uint32, uint64, error) {
-	account, err := e.client.GetAccount(ctx, e.config.COAAddress)
+func (e *EVM) GetSealedEVMHeight(ctx context.Context) (uint64, error) {
+	header, err := e.client.GetLatestBlockHeader(ctx, true)
 	if err != nil {
-		return 0, 0, fmt.Errorf(
-			"failed to get signer info account for address: %s, with: %w",
-			e.config.COAAddress,
-			err,
-		)
+		return 0, fmt.Errorf("failed to get latest sealed block header: %w", err)
 	}
 
-	e.collector.OperatorBalance(account)
+	latest, err := e.blocks.LatestEVMHeight()
+	if err != nil {
+		return 0, err
+	}
 
-	signerPub := e.signer.PublicKey()
-	for _, k := range account.Keys {
-		if k.PublicKey.Equals(signerPub) {
-			return k.Index, k.SequenceNumber, nil
+	// usually all the indexed blocks are sealed already
+	cadenceHeight, err := e.blocks.LatestCadenceHeight()
+	if err != nil {
+		return 0, err
+	}
+	if cadenceHeight <= header.Height {
+		return latest, nil
+	}
+
+	// the blocks below the first indexed height, except for the genesis block,
+	// aren't indexed, so the search starts at the first indexed height
+	first, err := e.blocks.FirstEVMHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	// the Cadence heights of the EVM blocks are increasing, so the first
+	// EVM block above the sealed Cadence height is searched for
+	var searchErr error
+	above := first + uint64(sort.Search(int(latest-first)+1, func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		cadenceHeight, err := e.blocks.GetCadenceHeight(first + uint64(i))
+		if err != nil {
+			// the blocks which aren't indexed are below the sealed height
+			if errors.Is(err, errs.ErrEntityNotFound) {
+				return false
+			}
+			searchErr = err
+			return true
 		}
+		return cadenceHeight > header.Height
+	}))
+	if searchErr != nil {
+		return 0, fmt.Errorf("failed to find the latest sealed EVM height: %w", searchErr)
+	}
+	if above > first {
+		return above - 1, nil
 	}
 
-	return 0, 0, fmt.Errorf(
-		"provided account address: %s and signer public key: %s, do not match",
-		e.config.COAAddress,
-		signerPub.String(),
+	// none of the blocks since the first indexed height is sealed, but the block
+	// right below it is sealed if it's indexed, such as the genesis block
+	noneSealed := fmt.Errorf(
+		"%w: no EVM block is sealed at Cadence height %d",
+		errs.ErrEntityNotFound,
+		header.Height,
 	)
+	if first == 0 {
+		return 0, noneSealed
+	}
+	cadenceHeight, err = e.blocks.GetCadenceHeight(first - 1)
+	if err != nil {
+		if errors.Is(err, errs.ErrEntityNotFound) {
+			return 0, noneSealed
+		}
+		return 0, fmt.Errorf("failed to find the latest sealed EVM height: %w", err)
+	}
+	if cadenceHeight > header.Height {
+		return 0, noneSealed
+	}
+
+	return first - 1, nil
+}
+
+func (e *EVM) GetPendingNonce(address common.Address, nonce uint64) uint64 {
+	return e.txPool.PendingNonce(address, nonce)
 }
 
 // replaceAddresses replace the addresses based on the network
@@ -746,6 +1444,25 @@ func (e *EVM) evmToCadenceHeight(height int64) (uint64, error) {
 	return cadenceHeight, nil
 }
 
+// resolveCadenceHeight maps the EVM height to the Cadence height, the latest
+// height is resolved to the height of the latest sealed block.
+func (e *EVM) resolveCadenceHeight(ctx context.Context, evmHeight int64) (uint64, error) {
+	height, err := e.evmToCadenceHeight(evmHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	if height == LatestBlockHeight {
+		h, err := e.client.GetLatestBlockHeader(ctx, true)
+		if err != nil {
+			return 0, err
+		}
+		height = h.Height
+	}
+
+	return height, nil
+}
+
 // executeScriptAtHeight will execute the given script, at the given
 // block height, with the given arguments. A height of `LatestBlockHeight`
 // (math.MaxUint64 - 1) is a special value, which means the script will be
@@ -830,13 +1547,28 @@ func cadenceStringToBytes(value cadence.Value) ([]byte, error) {
 	return code, nil
 }
 
-// parseResult
+// parseResult decodes the EVM result and returns an error if the result contains an error.
 func parseResult(res cadence.Value) (*evmTypes.ResultSummary, error) {
+	result, err := decodeResult(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkResult(result)
+}
+
+// decodeResult decodes the EVM result returned by the scripts.
+func decodeResult(res cadence.Value) (*evmTypes.ResultSummary, error) {
 	result, err := evmImpl.ResultSummaryFromEVMResultValue(res)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode EVM result of type: %s, with: %w", res.Type().ID(), err)
 	}
 
+	return result, nil
+}
+
+// checkResult returns a revert or failed transaction error if the result contains an error.
+func checkResult(result *evmTypes.ResultSummary) (*evmTypes.ResultSummary, error) {
 	if result.ErrorCode != 0 {
 		if result.ErrorCode == evmTypes.ExecutionErrCodeExecutionReverted {
 			return nil, errs.NewRevertError(result.ReturnedData)
@@ -844,7 +1576,16 @@ func parseResult(res cadence.Value) (*evmTypes.ResultSummary, error) {
 		return nil, errs.NewFailedTransactionError(result.ErrorMessage)
 	}
 
-	return result, err
+	return result, nil
+}
+
+// isResultError returns true if the error is produced by the transaction execution
+// or by invalid inputs, and not by a failure of the requester.
+func isResultError(err error) bool {
+	var revertErr *errs.RevertError
+	return errors.Is(err, errs.ErrFailedTransaction) ||
+		errors.Is(err, errs.ErrInvalid) ||
+		errors.As(err, &revertErr)
 }
 
 // cacheKey builds the cache key from the script type, height and arguments.
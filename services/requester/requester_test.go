@@ -2,19 +2,34 @@ package requester
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/mocks"
+	flowCrypto "github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flow-go/fvm/evm/events"
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
 	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/crypto"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/metrics"
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	storageMocks "github.com/onflow/flow-evm-gateway/storage/mocks"
+	"github.com/onflow/flow-evm-gateway/storage/pebble"
 )
 
 func Test_Caching(t *testing.T) {
@@ -210,6 +225,775 @@ func Test_CacheKey(t *testing.T) {
 
 }
 
+func Test_SealedEVMHeight(t *testing.T) {
+	mockClient := &mocks.Client{}
+	e := createEVM(t, nil, mockClient)
+
+	// the EVM block at each height is included in the Cadence block at 100 + 2 * height
+	blocks := &storageMocks.BlockIndexer{}
+	blocks.On("LatestEVMHeight").Return(uint64(10), nil)
+	blocks.On("LatestCadenceHeight").Return(uint64(120), nil)
+	blocks.On("FirstEVMHeight").Return(uint64(1), nil)
+	blocks.
+		On("GetCadenceHeight", mock.Anything).
+		Return(func(height uint64) uint64 { return 100 + 2*height }, nil)
+	e.blocks = blocks
+
+	t.Run("all the indexed blocks are sealed", func(t *testing.T) {
+		mockClient.
+			On("GetLatestBlockHeader", mock.Anything, true).
+			Return(&flow.BlockHeader{Height: 130}, nil).
+			Once()
+
+		height, err := e.GetSealedEVMHeight(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), height)
+	})
+
+	t.Run("latest indexed blocks are not sealed", func(t *testing.T) {
+		mockClient.
+			On("GetLatestBlockHeader", mock.Anything, true).
+			Return(&flow.BlockHeader{Height: 111}, nil).
+			Once()
+
+		height, err := e.GetSealedEVMHeight(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), height)
+	})
+
+	t.Run("no indexed block is sealed", func(t *testing.T) {
+		mockClient.
+			On("GetLatestBlockHeader", mock.Anything, true).
+			Return(&flow.BlockHeader{Height: 99}, nil).
+			Once()
+
+		_, err := e.GetSealedEVMHeight(context.Background())
+		require.ErrorIs(t, err, errs.ErrEntityNotFound)
+	})
+
+	t.Run("only the genesis block is sealed", func(t *testing.T) {
+		mockClient.
+			On("GetLatestBlockHeader", mock.Anything, true).
+			Return(&flow.BlockHeader{Height: 101}, nil).
+			Once()
+
+		height, err := e.GetSealedEVMHeight(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), height)
+	})
+
+	t.Run("blocks before the first indexed height are not indexed", func(t *testing.T) {
+		// the gateway started indexing at the EVM height 6
+		blocks := &storageMocks.BlockIndexer{}
+		blocks.On("LatestEVMHeight").Return(uint64(10), nil)
+		blocks.On("LatestCadenceHeight").Return(uint64(120), nil)
+		blocks.On("FirstEVMHeight").Return(uint64(6), nil)
+		blocks.
+			On("GetCadenceHeight", mock.Anything).
+			Return(func(height uint64) (uint64, error) {
+				if height > 0 && height < 6 {
+					return 0, errs.ErrEntityNotFound
+				}
+				return 100 + 2*height, nil
+			})
+		e.blocks = blocks
+
+		mockClient.
+			On("GetLatestBlockHeader", mock.Anything, true).
+			Return(&flow.BlockHeader{Height: 113}, nil).
+			Once()
+
+		height, err := e.GetSealedEVMHeight(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(6), height)
+
+		mockClient.
+			On("GetLatestBlockHeader", mock.Anything, true).
+			Return(&flow.BlockHeader{Height: 111}, nil).
+			Once()
+
+		_, err = e.GetSealedEVMHeight(context.Background())
+		require.ErrorIs(t, err, errs.ErrEntityNotFound)
+	})
+}
+
+func Test_PendingNonce(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	pool := NewTxPool(nil, nil, nil, newSubmissions(t), nil, &config.Config{}, metrics.NopCollector, zerolog.Nop())
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+	for _, nonce := range []uint64{5, 6} {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+		pool.pool.Store(tx.Hash(), tx)
+	}
+
+	// the transactions in the pool follow the latest nonce
+	require.Equal(t, uint64(7), pool.PendingNonce(from, 5))
+	// the transactions in the pool are already executed
+	require.Equal(t, uint64(8), pool.PendingNonce(from, 8))
+	// no transactions of the address are in the pool
+	require.Equal(t, uint64(3), pool.PendingNonce(common.Address{0x01}, 3))
+}
+
+func Test_PendingTransactions(t *testing.T) {
+	pool := NewTxPool(nil, nil, nil, newSubmissions(t), nil, &config.Config{}, metrics.NopCollector, zerolog.Nop())
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+
+	senders := make([]common.Address, 2)
+	for i, nonces := range [][]uint64{{7, 5, 6}, {1}} {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		senders[i] = crypto.PubkeyToAddress(key.PublicKey)
+
+		for _, nonce := range nonces {
+			tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1)})
+			require.NoError(t, err)
+			pool.pool.Store(tx.Hash(), tx)
+		}
+	}
+
+	pending := pool.Pending()
+	require.Len(t, pending, 2)
+
+	// the transactions of each sender are sorted by nonce
+	var nonces []uint64
+	for _, tx := range pending[senders[0]] {
+		nonces = append(nonces, tx.Nonce())
+	}
+	require.Equal(t, []uint64{5, 6, 7}, nonces)
+
+	require.Len(t, pending[senders[1]], 1)
+	require.Equal(t, uint64(1), pending[senders[1]][0].Nonce())
+}
+
+func Test_AsyncSubmission(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+	evmTx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	send := func(t *testing.T, result *flow.TransactionResult) (*TxPool, common.Hash, flow.Identifier) {
+		mockClient := &mocks.Client{}
+		client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+		require.NoError(t, err)
+
+		flowTx := flow.NewTransaction().SetScript([]byte("transaction {}"))
+
+		// the result is only available once the submission returns
+		sent := make(chan struct{})
+		mockClient.On("SendTransaction", mock.Anything, *flowTx).Return(nil).Once()
+		mockClient.
+			On("GetTransactionResult", mock.Anything, flowTx.ID()).
+			Run(func(mock.Arguments) { <-sent }).
+			Return(result, nil).
+			Once()
+
+		pool := NewTxPool(
+			client,
+			models.NewPublisher[*types.Transaction](),
+			nil,
+			newSubmissions(t),
+			nil,
+			&config.Config{AsyncTxSubmission: true},
+			metrics.NopCollector,
+			zerolog.Nop(),
+		)
+
+		err = pool.Send(context.Background(), flowTx, evmTx, nil)
+		require.NoError(t, err)
+
+		submission, err := pool.Submission(evmTx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, models.SubmissionSubmitted, submission.Status)
+		require.Equal(t, flowTx.ID(), submission.FlowID)
+		require.Len(t, pool.Pending(), 1)
+		close(sent)
+
+		return pool, evmTx.Hash(), flowTx.ID()
+	}
+
+	t.Run("sealed in the background", func(t *testing.T) {
+		pool, hash, _ := send(t, &flow.TransactionResult{
+			Status: flow.TransactionStatusSealed,
+			Events: []flow.Event{executedEvent(t, evmTx, 0)},
+		})
+
+		require.Eventually(t, func() bool {
+			submission, err := pool.Submission(hash)
+			return err == nil && submission.Status == models.SubmissionSealed
+		}, time.Second*5, time.Millisecond*10)
+		require.Empty(t, pool.Pending())
+	})
+
+	t.Run("failure is recorded", func(t *testing.T) {
+		pool, hash, flowID := send(t, &flow.TransactionResult{
+			Status: flow.TransactionStatusSealed,
+			Error:  fmt.Errorf("evm_error=nonce too low\n"),
+		})
+
+		require.Eventually(t, func() bool {
+			submission, err := pool.Submission(hash)
+			return err == nil && submission.Status == models.SubmissionFailed
+		}, time.Second*5, time.Millisecond*10)
+
+		submission, err := pool.Submission(hash)
+		require.NoError(t, err)
+		require.Equal(t, flowID, submission.FlowID)
+		require.Contains(t, submission.Error, "nonce too low")
+	})
+}
+
+func Test_ResumeSubmissions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+	evmTx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+	rawTx, err := evmTx.MarshalBinary()
+	require.NoError(t, err)
+
+	mockClient := &mocks.Client{}
+	client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+	require.NoError(t, err)
+
+	// the submission was sent before the restart, but wasn't sealed yet
+	flowID := flow.Identifier{0x1}
+	submissions := newSubmissions(t)
+	require.NoError(t, submissions.Store(&models.Submission{
+		Hash:     evmTx.Hash(),
+		RawTx:    rawTx,
+		FlowID:   flowID,
+		KeyIndex: 2,
+		Status:   models.SubmissionSubmitted,
+	}))
+
+	sealed := make(chan struct{})
+	mockClient.
+		On("GetTransactionResult", mock.Anything, flowID).
+		Run(func(mock.Arguments) { <-sealed }).
+		Return(&flow.TransactionResult{
+			Status: flow.TransactionStatusSealed,
+			Events: []flow.Event{executedEvent(t, evmTx, 0)},
+		}, nil).
+		Once()
+
+	pool := NewTxPool(client, nil, nil, submissions, nil, &config.Config{}, metrics.NopCollector, zerolog.Nop())
+	require.NoError(t, pool.Resume())
+
+	// the transaction is pending until the flow transaction is sealed
+	require.Len(t, pool.Pending(), 1)
+	close(sealed)
+
+	require.Eventually(t, func() bool {
+		submission, err := pool.Submission(evmTx.Hash())
+		return err == nil && submission.Status == models.SubmissionSealed
+	}, time.Second*5, time.Millisecond*10)
+	require.Empty(t, pool.Pending())
+
+	submission, err := pool.Submission(evmTx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, rawTx, submission.RawTx)
+	require.Equal(t, uint32(2), submission.KeyIndex)
+
+	pending, err := submissions.Pending()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func Test_RecordFailure(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+	newTx := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+		return tx
+	}
+
+	submissions := newSubmissions(t)
+	pool := NewTxPool(nil, nil, nil, submissions, nil, &config.Config{}, metrics.NopCollector, zerolog.Nop())
+
+	// the transaction failed before it was submitted
+	failed := newTx(1)
+	pool.RecordFailure(failed, fmt.Errorf("failed to build transaction"))
+
+	submission, err := pool.Submission(failed.Hash())
+	require.NoError(t, err)
+	require.Equal(t, models.SubmissionFailed, submission.Status)
+	require.Equal(t, "failed to build transaction", submission.Error)
+
+	// the status of the submitted transaction is kept
+	submitted := newTx(2)
+	require.NoError(t, submissions.Store(&models.Submission{
+		Hash:   submitted.Hash(),
+		FlowID: flow.Identifier{0x1},
+		Status: models.SubmissionSubmitted,
+	}))
+	pool.RecordFailure(submitted, fmt.Errorf("failed to await transaction"))
+
+	submission, err = pool.Submission(submitted.Hash())
+	require.NoError(t, err)
+	require.Equal(t, models.SubmissionSubmitted, submission.Status)
+}
+
+func Test_Resubmission(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+	evmTx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	expired := &flow.TransactionResult{Status: flow.TransactionStatusExpired}
+	sequenceMismatch := &flow.TransactionResult{
+		Status: flow.TransactionStatusSealed,
+		Error:  fmt.Errorf("[Error Code: 1007] invalid proposal key: public key 0 has sequence number 5, but given 4"),
+	}
+	sealed := &flow.TransactionResult{
+		Status: flow.TransactionStatusSealed,
+		Events: []flow.Event{executedEvent(t, evmTx, 0)},
+	}
+
+	// send submits the flow transactions in order, each having the result at the same index
+	send := func(
+		t *testing.T,
+		results []*flow.TransactionResult,
+		executed bool,
+	) (*TxPool, []*flow.Transaction, error) {
+		mockClient := &mocks.Client{}
+		client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+		require.NoError(t, err)
+
+		flowTxs := make([]*flow.Transaction, len(results))
+		for i, result := range results {
+			flowTxs[i] = flow.NewTransaction().SetScript([]byte(fmt.Sprintf("transaction { /* %d */ }", i)))
+			mockClient.On("SendTransaction", mock.Anything, *flowTxs[i]).Return(nil).Maybe()
+			mockClient.On("GetTransactionResult", mock.Anything, flowTxs[i].ID()).Return(result, nil).Maybe()
+		}
+
+		rebuilt := 0
+		rebuild := func(_ context.Context, txs []*types.Transaction) (*flow.Transaction, map[common.Hash]bool, error) {
+			require.Equal(t, []*types.Transaction{evmTx}, txs)
+			if executed {
+				return nil, map[common.Hash]bool{evmTx.Hash(): true}, nil
+			}
+			rebuilt++
+			return flowTxs[rebuilt], map[common.Hash]bool{}, nil
+		}
+
+		pool := NewTxPool(
+			client,
+			models.NewPublisher[*types.Transaction](),
+			nil,
+			newSubmissions(t),
+			nil,
+			&config.Config{TxResubmitLimit: 2},
+			metrics.NopCollector,
+			zerolog.Nop(),
+		)
+
+		err = pool.Send(context.Background(), flowTxs[0], evmTx, rebuild)
+		require.Empty(t, pool.Pending())
+		return pool, flowTxs, err
+	}
+
+	t.Run("expired and sequence-conflicted flow transactions are resubmitted", func(t *testing.T) {
+		pool, flowTxs, err := send(t, []*flow.TransactionResult{expired, sequenceMismatch, sealed}, false)
+		require.NoError(t, err)
+
+		submission, err := pool.Submission(evmTx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, models.SubmissionSealed, submission.Status)
+		require.Equal(t, flowTxs[2].ID(), submission.FlowID)
+	})
+
+	t.Run("resubmission limit", func(t *testing.T) {
+		pool, flowTxs, err := send(t, []*flow.TransactionResult{expired, expired, expired}, false)
+		require.ErrorContains(t, err, "expired")
+
+		submission, err := pool.Submission(evmTx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, models.SubmissionFailed, submission.Status)
+		require.Equal(t, flowTxs[2].ID(), submission.FlowID)
+	})
+
+	t.Run("executed transaction is not resubmitted", func(t *testing.T) {
+		pool, flowTxs, err := send(t, []*flow.TransactionResult{expired}, true)
+		require.ErrorIs(t, err, errs.ErrFailedTransaction)
+
+		submission, err := pool.Submission(evmTx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, models.SubmissionFailed, submission.Status)
+		require.Equal(t, flowTxs[0].ID(), submission.FlowID)
+	})
+}
+
+func Test_SendCanceledRequest(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	evmTx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(646)), &types.LegacyTx{GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	mockClient := &mocks.Client{}
+	client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+	require.NoError(t, err)
+
+	// the flow transaction is sealed only after the request is canceled
+	var sealed atomic.Bool
+	flowTx := flow.NewTransaction().SetScript([]byte("transaction {}"))
+	mockClient.On("SendTransaction", mock.Anything, *flowTx).Return(nil).Once()
+	mockClient.
+		On("GetTransactionResult", mock.Anything, flowTx.ID()).
+		Return(func(context.Context, flow.Identifier) *flow.TransactionResult {
+			if !sealed.Load() {
+				return &flow.TransactionResult{Status: flow.TransactionStatusPending}
+			}
+			return &flow.TransactionResult{
+				Status: flow.TransactionStatusSealed,
+				Events: []flow.Event{executedEvent(t, evmTx, 0)},
+			}
+		}, nil)
+
+	pool := NewTxPool(
+		client,
+		models.NewPublisher[*types.Transaction](),
+		nil,
+		newSubmissions(t),
+		nil,
+		&config.Config{},
+		metrics.NopCollector,
+		zerolog.Nop(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+	err = pool.Send(ctx, flowTx, evmTx, nil)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the submission is still tracked, and recorded once the flow transaction is sealed
+	submission, err := pool.Submission(evmTx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, models.SubmissionSubmitted, submission.Status)
+
+	sealed.Store(true)
+	require.Eventually(t, func() bool {
+		submission, err := pool.Submission(evmTx.Hash())
+		return err == nil && submission.Status == models.SubmissionSealed
+	}, time.Second*5, time.Millisecond*10)
+	require.Empty(t, pool.Pending())
+}
+
+func Test_TrackCanceled(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	evmTx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(646)), &types.LegacyTx{GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	mockClient := &mocks.Client{}
+	client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+	require.NoError(t, err)
+
+	flowTx := flow.NewTransaction().SetScript([]byte("transaction {}"))
+	mockClient.On("SendTransaction", mock.Anything, *flowTx).Return(nil).Once()
+	mockClient.
+		On("GetTransactionResult", mock.Anything, flowTx.ID()).
+		Return(&flow.TransactionResult{Status: flow.TransactionStatusPending}, nil)
+
+	pool := NewTxPool(
+		client,
+		models.NewPublisher[*types.Transaction](),
+		nil,
+		newSubmissions(t),
+		nil,
+		&config.Config{},
+		metrics.NopCollector,
+		zerolog.Nop(),
+	)
+
+	evmTxs := []*types.Transaction{evmTx}
+	require.NoError(t, pool.send(context.Background(), flowTx, evmTxs))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	results := pool.track(ctx, flowTx.ID(), flowTx.ProposalKey.KeyIndex, evmTxs, nil)
+	require.ErrorIs(t, results[0], context.DeadlineExceeded)
+
+	// the canceled tracking keeps the submission pending, so it's resumed after a restart
+	submission, err := pool.Submission(evmTx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, models.SubmissionSubmitted, submission.Status)
+	require.Equal(t, flowTx.ID(), submission.FlowID)
+}
+
+func Test_BatchSubmission(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+
+	mockClient := &mocks.Client{}
+	client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+	require.NoError(t, err)
+
+	pool := NewTxPool(
+		client,
+		models.NewPublisher[*types.Transaction](),
+		nil,
+		newSubmissions(t),
+		nil,
+		&config.Config{TxBatchSize: 3, TxBatchInterval: time.Minute},
+		metrics.NopCollector,
+		zerolog.Nop(),
+	)
+
+	evmTxs := make([]*types.Transaction, 3)
+	for i := range evmTxs {
+		evmTxs[i], err = types.SignNewTx(key, signer, &types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+	}
+
+	flowTx := flow.NewTransaction().SetScript([]byte("transaction {}"))
+	var built []*types.Transaction
+	build := func(_ context.Context, txs []*types.Transaction) (*flow.Transaction, error) {
+		built = txs
+		return flowTx, nil
+	}
+
+	// the second transaction is invalid, so it's not executed by the batch
+	executedEvents := []flow.Event{executedEvent(t, evmTxs[0], 0), executedEvent(t, evmTxs[2], 2)}
+
+	mockClient.On("SendTransaction", mock.Anything, *flowTx).Return(nil).Once()
+	mockClient.
+		On("GetTransactionResult", mock.Anything, flowTx.ID()).
+		Return(&flow.TransactionResult{Status: flow.TransactionStatusSealed, Events: executedEvents}, nil).
+		Once()
+
+	// the batch is submitted once full, before the batch interval elapses
+	results := make([]chan error, len(evmTxs))
+	for i, evmTx := range evmTxs {
+		results[i] = make(chan error, 1)
+		go func() {
+			results[i] <- pool.SendBatched(context.Background(), evmTx, build, nil)
+		}()
+		// keep the order of the transactions in the batch
+		if i < len(evmTxs)-1 {
+			require.Eventually(t, func() bool {
+				return len(pool.Pending()[crypto.PubkeyToAddress(key.PublicKey)]) == i+1
+			}, time.Second, time.Millisecond)
+		}
+	}
+
+	require.NoError(t, <-results[0])
+	require.ErrorIs(t, <-results[1], errs.ErrFailedTransaction)
+	require.NoError(t, <-results[2])
+	require.Equal(t, evmTxs, built)
+
+	statuses := []models.SubmissionStatus{models.SubmissionSealed, models.SubmissionFailed, models.SubmissionSealed}
+	for i, status := range statuses {
+		submission, err := pool.Submission(evmTxs[i].Hash())
+		require.NoError(t, err)
+		require.Equal(t, status, submission.Status)
+		require.Equal(t, flowTx.ID(), submission.FlowID)
+	}
+	require.Empty(t, pool.Pending())
+}
+
+func Test_BatchSubmissionWithInvalidTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+
+	mockClient := &mocks.Client{}
+	client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+	require.NoError(t, err)
+
+	pool := NewTxPool(
+		client,
+		models.NewPublisher[*types.Transaction](),
+		nil,
+		newSubmissions(t),
+		nil,
+		&config.Config{TxBatchSize: 3, TxBatchInterval: time.Minute, TxResubmitLimit: 1},
+		metrics.NopCollector,
+		zerolog.Nop(),
+	)
+
+	evmTxs := make([]*types.Transaction, 3)
+	for i := range evmTxs {
+		evmTxs[i], err = types.SignNewTx(key, signer, &types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+	}
+
+	flowTx := flow.NewTransaction().SetScript([]byte("transaction {}"))
+	build := func(_ context.Context, txs []*types.Transaction) (*flow.Transaction, error) {
+		return flowTx, nil
+	}
+	resubmittedTx := flow.NewTransaction().SetScript([]byte("transaction { execute {} }"))
+	var rebuilt []*types.Transaction
+	rebuild := func(_ context.Context, txs []*types.Transaction) (*flow.Transaction, map[common.Hash]bool, error) {
+		rebuilt = txs
+		return resubmittedTx, nil, nil
+	}
+
+	// the second transaction is invalid, so the batch is reverted with its error
+	batchErr := fmt.Errorf(
+		"[Error Code: 1101] cadence runtime error: assertion failed: evm_tx_index=1 evm_error=%s\n",
+		"nonce too high",
+	)
+	mockClient.On("SendTransaction", mock.Anything, *flowTx).Return(nil).Once()
+	mockClient.
+		On("GetTransactionResult", mock.Anything, flowTx.ID()).
+		Return(&flow.TransactionResult{Status: flow.TransactionStatusSealed, Error: batchErr}, nil).
+		Once()
+
+	// the valid transactions are resubmitted without the invalid one
+	executedEvents := []flow.Event{executedEvent(t, evmTxs[0], 0), executedEvent(t, evmTxs[2], 1)}
+	mockClient.On("SendTransaction", mock.Anything, *resubmittedTx).Return(nil).Once()
+	mockClient.
+		On("GetTransactionResult", mock.Anything, resubmittedTx.ID()).
+		Return(&flow.TransactionResult{Status: flow.TransactionStatusSealed, Events: executedEvents}, nil).
+		Once()
+
+	results := make([]chan error, len(evmTxs))
+	for i, evmTx := range evmTxs {
+		results[i] = make(chan error, 1)
+		go func() {
+			results[i] <- pool.SendBatched(context.Background(), evmTx, build, rebuild)
+		}()
+		// keep the order of the transactions in the batch
+		if i < len(evmTxs)-1 {
+			require.Eventually(t, func() bool {
+				return len(pool.Pending()[crypto.PubkeyToAddress(key.PublicKey)]) == i+1
+			}, time.Second, time.Millisecond)
+		}
+	}
+
+	require.NoError(t, <-results[0])
+	invalidErr := <-results[1]
+	require.ErrorIs(t, invalidErr, errs.ErrFailedTransaction)
+	require.ErrorContains(t, invalidErr, "nonce too high")
+	require.NoError(t, <-results[2])
+	require.Equal(t, []*types.Transaction{evmTxs[0], evmTxs[2]}, rebuilt)
+
+	flowIDs := []flow.Identifier{resubmittedTx.ID(), flowTx.ID(), resubmittedTx.ID()}
+	statuses := []models.SubmissionStatus{models.SubmissionSealed, models.SubmissionFailed, models.SubmissionSealed}
+	for i, status := range statuses {
+		submission, err := pool.Submission(evmTxs[i].Hash())
+		require.NoError(t, err)
+		require.Equal(t, status, submission.Status)
+		require.Equal(t, flowIDs[i], submission.FlowID)
+	}
+	require.Empty(t, pool.Pending())
+}
+
+func Test_ParseBatchInvalidErrors(t *testing.T) {
+	err := errors.New("assertion failed: evm_tx_index=0 evm_error=nonce too low\nevm_tx_index=2 evm_error=insufficient funds\n")
+
+	invalid := parseBatchInvalidErrors(err)
+	require.Len(t, invalid, 2)
+	require.ErrorIs(t, invalid[0], errs.ErrFailedTransaction)
+	require.ErrorContains(t, invalid[0], "nonce too low")
+	require.ErrorContains(t, invalid[2], "insufficient funds")
+
+	// the error of a single transaction has no index
+	require.Empty(t, parseBatchInvalidErrors(errors.New("evm_error=nonce too low\n")))
+}
+
+// executedEvent returns the transaction executed event of the EVM transaction.
+func Test_COAResourceCreation(t *testing.T) {
+	key := testKeys(t)[0]
+	address := flow.HexToAddress("0x01")
+
+	evmKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	evmTx, err := types.SignNewTx(evmKey, types.LatestSignerForChainID(big.NewInt(646)), &types.LegacyTx{GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	mockClient := &mocks.Client{}
+	client, err := NewCrossSporkClient(mockClient, nil, zerolog.Nop(), flowGo.Emulator)
+	require.NoError(t, err)
+
+	// the account has a single key, so the submission waits for the COA creation to be sealed
+	mockClient.On("GetAccount", mock.Anything, address).Return(&flow.Account{
+		Address: address,
+		Balance: minFlowBalance,
+		Keys: []*flow.AccountKey{{
+			Index:          0,
+			PublicKey:      key.PublicKey(),
+			SequenceNumber: 5,
+			Weight:         flow.AccountKeyWeightThreshold,
+		}},
+	}, nil)
+	mockClient.On("GetLatestBlock", mock.Anything, true).Return(&flow.Block{}, nil)
+	mockClient.
+		On("GetTransactionResult", mock.Anything, mock.Anything).
+		Return(&flow.TransactionResult{
+			Status: flow.TransactionStatusSealed,
+			Events: []flow.Event{executedEvent(t, evmTx, 0)},
+		}, nil)
+
+	sequenceNumbers := make(chan uint64, 2)
+	mockClient.
+		On("SendTransaction", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			sequenceNumbers <- args.Get(1).(flow.Transaction).ProposalKey.SequenceNumber
+		}).
+		Return(nil)
+
+	cfg := &config.Config{
+		COAAddress:        address,
+		CreateCOAResource: true,
+		EVMNetworkID:      evmTypes.FlowEVMPreviewNetChainID,
+		FlowNetworkID:     flowGo.Emulator,
+	}
+	signer, err := flowCrypto.NewInMemorySigner(key, flowCrypto.SHA3_256)
+	require.NoError(t, err)
+	keys, err := NewKeyPool(context.Background(), client, cfg, signer, metrics.NopCollector, zerolog.Nop())
+	require.NoError(t, err)
+
+	pool := NewTxPool(
+		client,
+		models.NewPublisher[*types.Transaction](),
+		nil,
+		newSubmissions(t),
+		keys,
+		cfg,
+		metrics.NopCollector,
+		zerolog.Nop(),
+	)
+	evm, err := NewEVM(context.Background(), client, cfg, keys, zerolog.Nop(), nil, nil, pool, nil, metrics.NopCollector)
+	require.NoError(t, err)
+
+	// the proposal key is released once the COA creation is sealed, so it's leased to the submission
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	flowTx, err := evm.buildTransaction(ctx, []byte("transaction {}"))
+	require.NoError(t, err)
+	require.NoError(t, pool.Send(ctx, flowTx, evmTx, nil))
+
+	require.Equal(t, uint64(5), <-sequenceNumbers)
+	require.Equal(t, uint64(6), <-sequenceNumbers)
+}
+
+func executedEvent(t *testing.T, evmTx *types.Transaction, index uint16) flow.Event {
+	event := events.NewTransactionEvent(
+		&evmTypes.Result{TxType: evmTx.Type(), TxHash: evmTx.Hash(), Index: index},
+		nil,
+		1,
+	)
+	value, err := event.Payload.ToCadence(flowGo.Emulator)
+	require.NoError(t, err)
+	return flow.Event{Value: value}
+}
+
+func newSubmissions(t *testing.T) *pebble.Submissions {
+	store, err := pebble.New(t.TempDir(), zerolog.Nop())
+	require.NoError(t, err)
+	return pebble.NewSubmissions(store)
+}
+
 func createEVM(t *testing.T, cache *expirable.LRU[string, cadence.Value], mockClient *mocks.Client) *EVM {
 	networkID := flowGo.Emulator
 	log := zerolog.New(zerolog.NewTestWriter(t))
@@ -7,7 +7,7 @@ import (
 	"github.com/onflow/flow-go-sdk/crypto"
 )
 
-var _ crypto.Signer = &KeyRotationSigner{}
+var _ MultiKeySigner = &KeyRotationSigner{}
 
 // KeyRotationSigner is a crypto signer that contains a pool of key pairs to sign with,
 // and it rotates the key used for each signing request. This allows for faster
@@ -18,11 +18,12 @@ var _ crypto.Signer = &KeyRotationSigner{}
 // get executed so the new sequence key can be obtained.
 // The signer is concurrency-safe.
 type KeyRotationSigner struct {
-	mux    sync.RWMutex
-	keys   []crypto.PrivateKey
-	hasher crypto.Hasher
-	index  int
-	keyLen int
+	mux     sync.RWMutex
+	keys    []crypto.PrivateKey
+	signers []crypto.Signer
+	hasher  crypto.Hasher
+	index   int
+	keyLen  int
 }
 
 func NewKeyRotationSigner(keys []crypto.PrivateKey, hashAlgo crypto.HashAlgorithm) (*KeyRotationSigner, error) {
@@ -42,10 +43,19 @@ func NewKeyRotationSigner(keys []crypto.PrivateKey, hashAlgo crypto.HashAlgorith
 		return nil, fmt.Errorf("signer with hasher %s can't be instantiated with this function", hashAlgo)
 	}
 
+	signers := make([]crypto.Signer, len(keys))
+	for i, key := range keys {
+		signers[i], err = crypto.NewInMemorySigner(key, hashAlgo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create signer of key %d: %w", i, err)
+		}
+	}
+
 	return &KeyRotationSigner{
-		keys:   keys,
-		hasher: hasher,
-		keyLen: len(keys),
+		keys:    keys,
+		signers: signers,
+		hasher:  hasher,
+		keyLen:  len(keys),
 	}, nil
 }
 
@@ -71,3 +81,8 @@ func (k *KeyRotationSigner) PublicKey() crypto.PublicKey {
 	defer k.mux.RUnlock()
 	return k.keys[k.index].PublicKey()
 }
+
+// Signers returns an in-memory signer for each of the keys.
+func (k *KeyRotationSigner) Signers() []crypto.Signer {
+	return k.signers
+}
@@ -0,0 +1,387 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/onflow/flow-go/fvm/evm/emulator"
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/core/tracing"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/core/vm"
+	"github.com/onflow/go-ethereum/eth/tracers"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+// maxSimulateBlocks is the maximum number of blocks a single simulation can contain.
+const maxSimulateBlocks = 256
+
+var (
+	// transferAddress is the address the native value transfer logs are emitted
+	// from, when the transfers are traced.
+	transferAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+	// transferTopic is the topic of the ERC-20 Transfer(address,address,uint256) event.
+	transferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+)
+
+// SimulateCall is an unsigned transaction executed as the provided sender.
+type SimulateCall struct {
+	From common.Address
+	Data []byte
+}
+
+// SimulateBlock contains the calls executed within a single simulated block,
+// and the overrides applied before the calls are executed.
+type SimulateBlock struct {
+	BlockOverrides *models.BlockOverrides
+	StateOverrides models.StateOverride
+	Calls          []SimulateCall
+}
+
+// SimulatedCall is the result of a simulated call.
+type SimulatedCall struct {
+	Transaction models.Transaction
+	Result      *evmTypes.Result
+	Logs        []*types.Log
+}
+
+// SimulatedBlock is a block produced by the simulation, with the results of its calls.
+type SimulatedBlock struct {
+	Block *models.Block
+	Calls []*SimulatedCall
+}
+
+// simulate executes the blocks of calls one after another, where each call is
+// executed on top of the state changes of all the previous calls.
+// If traceTransfers is set, the native value transfers are included in the logs.
+func (l *localExecutor) simulate(
+	ctx context.Context,
+	blocks []SimulateBlock,
+	traceTransfers bool,
+) ([]*SimulatedBlock, error) {
+	if len(blocks) > maxSimulateBlocks {
+		return nil, fmt.Errorf(
+			"%w: too many blocks: %d, maximum is %d",
+			errs.ErrInvalid,
+			len(blocks),
+			maxSimulateBlocks,
+		)
+	}
+
+	proposal, err := l.blockProposal()
+	if err != nil {
+		return nil, err
+	}
+	baseContext, err := l.blockContext()
+	if err != nil {
+		return nil, err
+	}
+
+	// the hashes of the simulated blocks are available to the following blocks
+	hashes := make(map[uint64]common.Hash)
+	getHash := baseContext.GetHashFunc
+	baseContext.GetHashFunc = func(n uint64) common.Hash {
+		if hash, ok := hashes[n]; ok {
+			return hash
+		}
+		return getHash(n)
+	}
+
+	var tracer *transferTracer
+	if traceTransfers {
+		tracer = &transferTracer{}
+		baseContext.Tracer = &tracers.Tracer{Hooks: tracer.hooks()}
+	}
+
+	parentHash := proposal.ParentBlockHash
+	prevNumber := proposal.Height - 1
+	prevTimestamp := proposal.Timestamp - 1
+
+	simulated := make([]*SimulatedBlock, 0, len(blocks))
+	for i, block := range blocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		blockContext := baseContext
+		blockContext.BlockNumber = prevNumber + 1
+		blockContext.BlockTimestamp = prevTimestamp + 1
+		blockContext.TxCountSoFar = 0
+		blockContext.TotalGasUsedSoFar = 0
+		if err := applyBlockOverrides(&blockContext, block.BlockOverrides); err != nil {
+			return nil, err
+		}
+		if blockContext.BlockNumber <= prevNumber {
+			return nil, fmt.Errorf(
+				"%w: block numbers must be in order: %d <= %d",
+				errs.ErrInvalid,
+				blockContext.BlockNumber,
+				prevNumber,
+			)
+		}
+		if i > 0 && blockContext.BlockTimestamp <= prevTimestamp {
+			return nil, fmt.Errorf(
+				"%w: block timestamps must be in order: %d <= %d",
+				errs.ErrInvalid,
+				blockContext.BlockTimestamp,
+				prevTimestamp,
+			)
+		}
+
+		if err := l.applyStateOverrides(block.StateOverrides); err != nil {
+			return nil, err
+		}
+
+		result, err := l.simulateBlock(blockContext, parentHash, proposal.TotalSupply, block.Calls, tracer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate block %d: %w", i, err)
+		}
+
+		parentHash, err = result.Block.Hash()
+		if err != nil {
+			return nil, err
+		}
+		hashes[blockContext.BlockNumber] = parentHash
+		prevNumber = blockContext.BlockNumber
+		prevTimestamp = blockContext.BlockTimestamp
+
+		simulated = append(simulated, result)
+	}
+
+	return simulated, nil
+}
+
+// simulateBlock executes the calls within a block built from the block context,
+// and commits the state changes of each call to the executor state.
+func (l *localExecutor) simulateBlock(
+	ctx evmTypes.BlockContext,
+	parentHash common.Hash,
+	totalSupply *big.Int,
+	calls []SimulateCall,
+	tracer *transferTracer,
+) (*SimulatedBlock, error) {
+	blockView, err := emulator.NewEmulator(l.storage, l.rootAddress).NewBlockView(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := evmTypes.NewBlockProposal(
+		parentHash,
+		ctx.BlockNumber,
+		ctx.BlockTimestamp,
+		totalSupply,
+		ctx.Random,
+	)
+
+	results := make([]*SimulatedCall, 0, len(calls))
+	for i, c := range calls {
+		tx := &types.Transaction{}
+		if err := tx.UnmarshalBinary(c.Data); err != nil {
+			return nil, errs.NewInvalidTransactionError(err)
+		}
+
+		call := directCall(c.From, tx)
+		res, err := blockView.DirectCall(call)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return nil, evmTypes.ErrUnexpectedEmptyResult
+		}
+		if l.unsupportedArchCall {
+			return nil, fmt.Errorf("%w: %w", errs.ErrFailedTransaction, errLocalArchCall)
+		}
+		if res.ValidationError != nil {
+			return nil, errs.NewFailedTransactionError(
+				fmt.Sprintf("call %d: %s", i, res.ValidationError.Error()),
+			)
+		}
+
+		var logs []*types.Log
+		if res.Successful() {
+			logs = res.Logs
+			if tracer != nil {
+				logs = tracer.logs
+			}
+		}
+
+		proposal.AppendTransaction(res)
+		results = append(results, &SimulatedCall{
+			Transaction: models.DirectCall{DirectCall: call},
+			Result:      res,
+			Logs:        logs,
+		})
+	}
+
+	proposal.PopulateRoots()
+	hash, err := proposal.Block.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	logIndex := uint(0)
+	for i, call := range results {
+		for _, log := range call.Logs {
+			log.BlockNumber = ctx.BlockNumber
+			log.BlockHash = hash
+			log.TxHash = call.Result.TxHash
+			log.TxIndex = uint(i)
+			log.Index = logIndex
+			logIndex++
+		}
+	}
+
+	return &SimulatedBlock{
+		Block: &models.Block{
+			Block:             &proposal.Block,
+			TransactionHashes: proposal.TxHashes,
+		},
+		Calls: results,
+	}, nil
+}
+
+// directCall builds a direct call from the unsigned transaction, direct calls
+// skip the nonce and fees checks, and persist the state changes.
+func directCall(from common.Address, tx *types.Transaction) *evmTypes.DirectCall {
+	value := tx.Value()
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	if tx.To() == nil {
+		return evmTypes.NewDeployCall(evmTypes.NewAddress(from), tx.Data(), tx.Gas(), value, 0)
+	}
+	return evmTypes.NewContractCall(
+		evmTypes.NewAddress(from),
+		evmTypes.NewAddress(*tx.To()),
+		tx.Data(),
+		tx.Gas(),
+		value,
+		0,
+	)
+}
+
+// transferTracer collects the logs emitted by a transaction, including the native
+// value transfers as ERC-20 Transfer logs, in the order of execution.
+// The logs emitted by the reverted call frames are discarded.
+type transferTracer struct {
+	frames [][]*types.Log
+	logs   []*types.Log
+}
+
+func (t *transferTracer) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: t.onTxStart,
+		OnEnter:   t.onEnter,
+		OnExit:    t.onExit,
+		OnOpcode:  t.onOpcode,
+	}
+}
+
+func (t *transferTracer) onTxStart(*tracing.VMContext, *types.Transaction, common.Address) {
+	t.frames = nil
+	t.logs = nil
+}
+
+func (t *transferTracer) onEnter(
+	_ int,
+	typ byte,
+	from common.Address,
+	to common.Address,
+	_ []byte,
+	_ uint64,
+	value *big.Int,
+) {
+	t.frames = append(t.frames, []*types.Log{})
+
+	if value == nil || value.Sign() <= 0 || vm.OpCode(typ) == vm.DELEGATECALL {
+		return
+	}
+	t.append(&types.Log{
+		Address: transferAddress,
+		Topics: []common.Hash{
+			transferTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.BigToHash(value).Bytes(),
+	})
+}
+
+func (t *transferTracer) onExit(_ int, _ []byte, _ uint64, _ error, reverted bool) {
+	if len(t.frames) == 0 {
+		return
+	}
+
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if reverted {
+		return
+	}
+
+	if len(t.frames) == 0 {
+		t.logs = frame
+		return
+	}
+	t.frames[len(t.frames)-1] = append(t.frames[len(t.frames)-1], frame...)
+}
+
+func (t *transferTracer) onOpcode(
+	_ uint64,
+	op byte,
+	_ uint64,
+	_ uint64,
+	scope tracing.OpContext,
+	_ []byte,
+	_ int,
+	err error,
+) {
+	opcode := vm.OpCode(op)
+	if err != nil || opcode < vm.LOG0 || opcode > vm.LOG4 {
+		return
+	}
+
+	stack := scope.StackData()
+	size := int(opcode-vm.LOG0) + 2
+	if len(stack) < size {
+		return
+	}
+
+	offset := stack[len(stack)-1]
+	length := stack[len(stack)-2]
+	// the memory is already expanded to cover the logged range
+	if !offset.IsUint64() || !length.IsUint64() || length.Uint64() > uint64(len(scope.MemoryData())) {
+		return
+	}
+	topics := make([]common.Hash, 0, size-2)
+	for i := 3; i <= size; i++ {
+		topics = append(topics, common.Hash(stack[len(stack)-i].Bytes32()))
+	}
+
+	t.append(&types.Log{
+		Address: scope.Address(),
+		Topics:  topics,
+		Data:    memoryCopy(scope.MemoryData(), offset.Uint64(), length.Uint64()),
+	})
+}
+
+func (t *transferTracer) append(log *types.Log) {
+	if len(t.frames) == 0 {
+		return
+	}
+	t.frames[len(t.frames)-1] = append(t.frames[len(t.frames)-1], log)
+}
+
+// memoryCopy returns a copy of the memory range, padded with zeros
+// if the range exceeds the memory size.
+func memoryCopy(memory []byte, offset uint64, length uint64) []byte {
+	data := make([]byte, length)
+	if offset < uint64(len(memory)) {
+		copy(data, memory[offset:])
+	}
+	return data
+}
@@ -0,0 +1,159 @@
+package requester
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+func Test_LocalExecutorSimulate(t *testing.T) {
+	chainID := flowGo.Emulator
+	from := common.HexToAddress("0x1000")
+	contract := common.HexToAddress("0x2000")
+	other := common.HexToAddress("0x3000")
+	target := common.HexToAddress("0x4000")
+
+	encodeCall := func(t *testing.T, to common.Address, value int64) []byte {
+		tx := types.NewTx(&types.LegacyTx{
+			To:       &to,
+			Value:    big.NewInt(value),
+			Gas:      1_000_000,
+			GasPrice: big.NewInt(0),
+		})
+		data, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("calls observe previous state changes", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		// returns the balance of the other address
+		code := append(append([]byte{0x73}, other.Bytes()...),
+			0x31, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3,
+		)
+
+		simulated, err := executor.simulate(context.Background(), []SimulateBlock{{
+			Calls: []SimulateCall{{From: from, Data: encodeCall(t, other, 100)}},
+		}, {
+			StateOverrides: models.StateOverride{
+				target: {Code: (*hexutil.Bytes)(&code)},
+			},
+			Calls: []SimulateCall{
+				{From: from, Data: encodeCall(t, other, 50)},
+				{From: from, Data: encodeCall(t, target, 0)},
+			},
+		}}, false)
+		require.NoError(t, err)
+		require.Len(t, simulated, 2)
+
+		first, second := simulated[0], simulated[1]
+		require.Len(t, first.Calls, 1)
+		require.Len(t, second.Calls, 2)
+
+		result := second.Calls[1].Result
+		require.NoError(t, result.VMError)
+		assert.Equal(t, common.BigToHash(big.NewInt(150)).Bytes(), result.ReturnedData)
+
+		firstHash, err := first.Block.Hash()
+		require.NoError(t, err)
+		assert.Equal(t, firstHash, second.Block.ParentBlockHash)
+		assert.Equal(t, first.Block.Height+1, second.Block.Height)
+		assert.Greater(t, second.Block.Timestamp, first.Block.Timestamp)
+		assert.Len(t, second.Block.TransactionHashes, 2)
+		assert.Equal(t, second.Calls[0].Result.TxHash, second.Calls[0].Transaction.Hash())
+	})
+
+	t.Run("trace transfers", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		// emits a log with the data 0x2a
+		code := []byte{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xa0, 0x00}
+
+		simulated, err := executor.simulate(context.Background(), []SimulateBlock{{
+			StateOverrides: models.StateOverride{
+				target: {Code: (*hexutil.Bytes)(&code)},
+			},
+			Calls: []SimulateCall{
+				{From: from, Data: encodeCall(t, other, 100)},
+				{From: from, Data: encodeCall(t, target, 5)},
+			},
+		}}, true)
+		require.NoError(t, err)
+		require.Len(t, simulated, 1)
+
+		block := simulated[0]
+		hash, err := block.Block.Hash()
+		require.NoError(t, err)
+
+		transfer := block.Calls[0].Logs
+		require.Len(t, transfer, 1)
+		assert.Equal(t, transferAddress, transfer[0].Address)
+		assert.Equal(t, []common.Hash{
+			transferTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(other.Bytes()),
+		}, transfer[0].Topics)
+		assert.Equal(t, common.BigToHash(big.NewInt(100)).Bytes(), transfer[0].Data)
+		assert.Equal(t, uint(0), transfer[0].Index)
+		assert.Equal(t, hash, transfer[0].BlockHash)
+
+		logs := block.Calls[1].Logs
+		require.Len(t, logs, 2)
+		assert.Equal(t, transferAddress, logs[0].Address)
+		assert.Equal(t, target, logs[1].Address)
+		assert.Equal(t, common.BigToHash(big.NewInt(0x2a)).Bytes(), logs[1].Data)
+		assert.Equal(t, uint(1), logs[0].TxIndex)
+		assert.Equal(t, uint(2), logs[1].Index)
+	})
+
+	t.Run("reverted call", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		// reverts with the value 0x2a
+		code := []byte{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xfd}
+
+		simulated, err := executor.simulate(context.Background(), []SimulateBlock{{
+			StateOverrides: models.StateOverride{
+				target: {Code: (*hexutil.Bytes)(&code)},
+			},
+			Calls: []SimulateCall{{From: from, Data: encodeCall(t, target, 0)}},
+		}}, true)
+		require.NoError(t, err)
+
+		call := simulated[0].Calls[0]
+		require.Error(t, call.Result.VMError)
+		assert.Equal(t, common.BigToHash(big.NewInt(0x2a)).Bytes(), call.Result.ReturnedData)
+		assert.Empty(t, call.Logs)
+	})
+
+	t.Run("block numbers must be in order", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		number := (*hexutil.Big)(big.NewInt(1_000))
+		_, err := executor.simulate(context.Background(), []SimulateBlock{
+			{BlockOverrides: &models.BlockOverrides{Number: number}},
+			{BlockOverrides: &models.BlockOverrides{Number: number}},
+		}, false)
+		require.ErrorIs(t, err, errs.ErrInvalid)
+	})
+
+	t.Run("insufficient balance fails the simulation", func(t *testing.T) {
+		executor := newTestExecutor(t, chainID, from, contract, testContractCode(other))
+
+		_, err := executor.simulate(context.Background(), []SimulateBlock{{
+			Calls: []SimulateCall{{From: from, Data: encodeCall(t, other, 10_000_000)}},
+		}}, false)
+		require.ErrorIs(t, err, errs.ErrFailedTransaction)
+	})
+}
@@ -0,0 +1,120 @@
+package requester
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onflow/go-ethereum/common"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+// queuedTx is a transaction held in the queue since the time it was received.
+type queuedTx struct {
+	tx       *gethTypes.Transaction
+	queuedAt time.Time
+}
+
+// txQueue holds the transactions with a nonce ahead of the next nonce of the sender,
+// until the transactions filling the nonce gap are submitted. The transactions are
+// held for at most the TTL, and each sender can have at most the limit of transactions queued.
+type txQueue struct {
+	mux     sync.Mutex
+	senders map[common.Address]map[uint64]*queuedTx
+	limit   uint64
+	ttl     time.Duration
+}
+
+func newTxQueue(limit uint64, ttl time.Duration) *txQueue {
+	return &txQueue{
+		senders: make(map[common.Address]map[uint64]*queuedTx),
+		limit:   limit,
+		ttl:     ttl,
+	}
+}
+
+// add queues the transaction of the sender, replacing the queued transaction with the same nonce.
+func (q *txQueue) add(from common.Address, tx *gethTypes.Transaction) error {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	queued, ok := q.senders[from]
+	if !ok {
+		queued = make(map[uint64]*queuedTx)
+		q.senders[from] = queued
+	}
+
+	if _, replaced := queued[tx.Nonce()]; !replaced && uint64(len(queued)) >= q.limit {
+		return errs.NewInvalidTransactionError(fmt.Errorf(
+			"sender %s has reached the limit of %d queued transactions",
+			from,
+			q.limit,
+		))
+	}
+
+	queued[tx.Nonce()] = &queuedTx{tx: tx, queuedAt: time.Now()}
+	return nil
+}
+
+// take removes and returns the queued transaction of the sender with the nonce, if any.
+func (q *txQueue) take(from common.Address, nonce uint64) (*gethTypes.Transaction, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	queued, ok := q.senders[from][nonce]
+	if !ok {
+		return nil, false
+	}
+
+	delete(q.senders[from], nonce)
+	if len(q.senders[from]) == 0 {
+		delete(q.senders, from)
+	}
+
+	return queued.tx, true
+}
+
+// expire removes and returns the transactions queued for longer than the TTL.
+func (q *txQueue) expire(now time.Time) []*gethTypes.Transaction {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	var expired []*gethTypes.Transaction
+	for from, queued := range q.senders {
+		for nonce, tx := range queued {
+			if now.Sub(tx.queuedAt) < q.ttl {
+				continue
+			}
+			expired = append(expired, tx.tx)
+			delete(queued, nonce)
+		}
+		if len(queued) == 0 {
+			delete(q.senders, from)
+		}
+	}
+
+	return expired
+}
+
+// queued returns the queued transactions grouped by the sender and sorted by nonce.
+func (q *txQueue) queued() map[common.Address][]*gethTypes.Transaction {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	result := make(map[common.Address][]*gethTypes.Transaction, len(q.senders))
+	for from, queued := range q.senders {
+		txs := make([]*gethTypes.Transaction, 0, len(queued))
+		for _, tx := range queued {
+			txs = append(txs, tx.tx)
+		}
+		sort.Slice(txs, func(i, j int) bool {
+			return txs[i].Nonce() < txs[j].Nonce()
+		})
+		result[from] = txs
+	}
+
+	return result
+}
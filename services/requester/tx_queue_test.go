@@ -0,0 +1,82 @@
+package requester
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+func Test_TxQueue(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(646))
+
+	newTx := func(nonce uint64, gasPrice int64) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(gasPrice)})
+		require.NoError(t, err)
+		return tx
+	}
+
+	t.Run("queued transactions sorted by nonce", func(t *testing.T) {
+		queue := newTxQueue(3, time.Minute)
+		require.NoError(t, queue.add(from, newTx(7, 1)))
+		require.NoError(t, queue.add(from, newTx(5, 1)))
+
+		queued := queue.queued()[from]
+		require.Len(t, queued, 2)
+		require.Equal(t, uint64(5), queued[0].Nonce())
+		require.Equal(t, uint64(7), queued[1].Nonce())
+	})
+
+	t.Run("limit of queued transactions per sender", func(t *testing.T) {
+		queue := newTxQueue(2, time.Minute)
+		require.NoError(t, queue.add(from, newTx(5, 1)))
+		require.NoError(t, queue.add(from, newTx(6, 1)))
+
+		err := queue.add(from, newTx(7, 1))
+		require.ErrorIs(t, err, errs.ErrInvalidTransaction)
+
+		// the transaction with the same nonce is replaced
+		replacement := newTx(6, 2)
+		require.NoError(t, queue.add(from, replacement))
+		require.Equal(t, replacement.Hash(), queue.queued()[from][1].Hash())
+
+		// other senders have their own limit
+		require.NoError(t, queue.add(common.Address{0x01}, newTx(7, 1)))
+	})
+
+	t.Run("take queued transaction by nonce", func(t *testing.T) {
+		queue := newTxQueue(2, time.Minute)
+		tx := newTx(5, 1)
+		require.NoError(t, queue.add(from, tx))
+
+		_, ok := queue.take(from, 4)
+		require.False(t, ok)
+
+		taken, ok := queue.take(from, 5)
+		require.True(t, ok)
+		require.Equal(t, tx.Hash(), taken.Hash())
+		require.Empty(t, queue.queued())
+	})
+
+	t.Run("expire transactions queued longer than TTL", func(t *testing.T) {
+		queue := newTxQueue(2, time.Minute)
+		tx := newTx(5, 1)
+		require.NoError(t, queue.add(from, tx))
+
+		require.Empty(t, queue.expire(time.Now()))
+
+		expired := queue.expire(time.Now().Add(time.Minute))
+		require.Len(t, expired, 1)
+		require.Equal(t, tx.Hash(), expired[0].Hash())
+		require.Empty(t, queue.queued())
+	})
+}
@@ -15,6 +15,8 @@ type MockClient struct {
 	GetBlockHeaderByHeightFunc       func(context.Context, uint64) (*flow.BlockHeader, error)
 	SubscribeEventsByBlockHeightFunc func(context.Context, uint64, flow.EventFilter, ...access.SubscribeOption) (<-chan flow.BlockEvents, <-chan error, error)
 	GetNodeVersionInfoFunc           func(ctx context.Context) (*flow.NodeVersionInfo, error)
+
+	SubscribeExecutionDataByBlockHeightFunc func(context.Context, uint64) (<-chan flow.ExecutionDataStreamResponse, <-chan error, error)
 }
 
 func (c *MockClient) GetBlockHeaderByHeight(ctx context.Context, height uint64) (*flow.BlockHeader, error) {
@@ -38,6 +40,13 @@ func (c *MockClient) SubscribeEventsByBlockHeight(
 	return c.SubscribeEventsByBlockHeightFunc(ctx, startHeight, filter, opts...)
 }
 
+func (c *MockClient) SubscribeExecutionDataByBlockHeight(
+	ctx context.Context,
+	startHeight uint64,
+) (<-chan flow.ExecutionDataStreamResponse, <-chan error, error) {
+	return c.SubscribeExecutionDataByBlockHeightFunc(ctx, startHeight)
+}
+
 func SetupClientForRange(startHeight uint64, endHeight uint64) *MockClient {
 	client, events := SetupClient(startHeight, endHeight)
 	go func() {
@@ -6,6 +6,7 @@ import (
 	"github.com/cockroachdb/pebble"
 	"github.com/goccy/go-json"
 	"github.com/onflow/flow-go-sdk"
+	flowGo "github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/go-ethereum/common"
 
 	"github.com/onflow/flow-evm-gateway/models"
@@ -38,6 +39,13 @@ type BlockIndexer interface {
 	// - errors.NotInitialized if the storage was not initialized
 	LatestEVMHeight() (uint64, error)
 
+	// FirstEVMHeight returns the first EVM block height indexed after the genesis block,
+	// which is stored when the storage is initialized, or zero if no block was indexed since.
+	// The blocks between the genesis block and the first height are not indexed.
+	// Expected errors:
+	// - errors.NotInitialized if the storage was not initialized
+	FirstEVMHeight() (uint64, error)
+
 	// LatestCadenceHeight return the latest stored Cadence height.
 	// Expected errors:
 	// - errors.NotInitialized if the storage was not initialized
@@ -76,6 +84,12 @@ type ReceiptIndexer interface {
 	// - errors.NotFound if the receipt is not found
 	GetByBlockHeight(height uint64) ([]*models.Receipt, error)
 
+	// FeesByBlockHeight returns the fees of the transactions in the block,
+	// sorted by the effective tip in ascending order.
+	// Expected errors:
+	// - errors.NotFound if the receipts of the block are not found
+	FeesByBlockHeight(height uint64) ([]*models.TransactionFee, error)
+
 	// BloomsForBlockRange returns slice of bloom values and a slice of block heights
 	// corresponding to each item in the bloom slice. It only matches the blooms between
 	// inclusive start and end block height.
@@ -98,16 +112,21 @@ type TransactionIndexer interface {
 }
 
 type AccountIndexer interface {
-	// Update account with executed transactions.
+	// Update stores the nonce and balance of the accounts changed at the EVM height.
 	// Batch is required to batch multiple indexer operations, skipped if nil.
-	Update(tx models.Transaction, receipt *models.Receipt, batch *pebble.Batch) error
+	Update(accounts []*models.Account, evmHeight uint64, batch *pebble.Batch) error
 
-	// GetNonce gets an account nonce. If no nonce was indexed it returns 0.
-	// todo add getting nonce at provided block height / hash
-	GetNonce(address common.Address) (uint64, error)
+	// GetNonce gets the account nonce at the EVM height, which is the nonce
+	// stored at the latest height at or below the provided one.
+	// Expected errors:
+	// - errors.NotFound if the account wasn't changed since the accounts are indexed
+	GetNonce(address common.Address, evmHeight uint64) (uint64, error)
 
-	// GetBalance gets an account balance. If no balance was indexed it returns 0.
-	GetBalance(address common.Address) (*big.Int, error)
+	// GetBalance gets the account balance at the EVM height, which is the balance
+	// stored at the latest height at or below the provided one.
+	// Expected errors:
+	// - errors.NotFound if the account wasn't changed since the accounts are indexed
+	GetBalance(address common.Address, evmHeight uint64) (*big.Int, error)
 }
 
 type TraceIndexer interface {
@@ -118,3 +137,74 @@ type TraceIndexer interface {
 	// GetTransaction will retrieve transaction trace by the transaction ID.
 	GetTransaction(ID common.Hash) (json.RawMessage, error)
 }
+
+type SubmissionIndexer interface {
+	// Store the submission of an EVM transaction, replacing the stored submission of
+	// the transaction if any. The submissions which are neither sealed nor failed are
+	// also indexed as pending.
+	Store(submission *models.Submission) error
+
+	// Get the submission of an EVM transaction by the hash.
+	// Expected errors:
+	// - errors.NotFound if the submission is not found
+	Get(hash common.Hash) (*models.Submission, error)
+
+	// Pending returns the submissions which are neither sealed nor failed.
+	Pending() ([]*models.Submission, error)
+}
+
+type RegisterIndexer interface {
+	// Store the provided register values updated at the Cadence height.
+	// Batch is required to batch multiple indexer operations, skipped if nil.
+	Store(registers flowGo.RegisterEntries, cadenceHeight uint64, batch *pebble.Batch) error
+
+	// LatestCadenceHeight returns the latest Cadence height the registers are indexed at.
+	// Expected errors:
+	// - errors.NotInitialized if no registers were indexed
+	LatestCadenceHeight() (uint64, error)
+
+	// ViewAt returns a read-only view of the registers at the Cadence height,
+	// which is only available if the registers are versioned by height.
+	// Expected errors:
+	// - errors.HeightNotArchived if the height is before the archive started
+	// - errors.HeightOutOfRange if the registers at the height are not indexed
+	// - errors.NotInitialized if no registers were indexed
+	ViewAt(cadenceHeight uint64) (RegisterArchiveView, error)
+
+	// Snapshot returns a consistent read-only view of the latest indexed registers.
+	// The snapshot must be closed once it's no longer used.
+	// Expected errors:
+	// - errors.NotInitialized if no registers were indexed
+	Snapshot() (RegisterSnapshot, error)
+}
+
+// RegisterView is a read-only view of the registers indexed at a Cadence height.
+type RegisterView interface {
+	// GetValue returns the register value, and whether the register was indexed.
+	// Registers which weren't updated since the indexing started are not indexed.
+	GetValue(owner, key []byte) ([]byte, bool, error)
+}
+
+// RegisterArchiveView is a read-only view of the registers archived at a Cadence height.
+type RegisterArchiveView interface {
+	RegisterView
+
+	// UpdatedAfter returns whether the register was updated after the view height.
+	// The registers which were neither updated up to nor after the view height
+	// since the archive started, have the same value at the latest height.
+	UpdatedAfter(owner, key []byte) (bool, error)
+}
+
+// RegisterSnapshot is a read-only view of the registers indexed up to a Cadence height.
+type RegisterSnapshot interface {
+	RegisterView
+
+	// CadenceHeight returns the Cadence height the registers are indexed at.
+	CadenceHeight() uint64
+
+	// EVMHeight returns the latest EVM height indexed together with the registers.
+	EVMHeight() uint64
+
+	// Close releases the snapshot.
+	Close() error
+}
@@ -2,13 +2,13 @@ package storage
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/goccy/go-json"
 	"github.com/onflow/flow-go-sdk"
-	evmEmulator "github.com/onflow/flow-go/fvm/evm/emulator"
+	evmTypes "github.com/onflow/flow-go/fvm/evm/types"
 	"github.com/onflow/go-ethereum/common"
 	"github.com/onflow/go-ethereum/core/types"
-	"github.com/onflow/go-ethereum/crypto"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/onflow/flow-evm-gateway/models"
@@ -256,6 +256,40 @@ func (s *ReceiptTestSuite) TestGetReceiptByBlockHeight() {
 	})
 }
 
+func (s *ReceiptTestSuite) TestFeesByBlockHeight() {
+	s.Run("existing block height", func() {
+		height := uint64(30)
+		expensive := mocks.NewReceipt(height, common.HexToHash("0x1"))
+		expensive.EffectiveGasPrice = big.NewInt(300)
+		expensive.GasUsed = 21000
+		cheap := mocks.NewReceipt(height, common.HexToHash("0x1"))
+		cheap.EffectiveGasPrice = big.NewInt(100)
+		cheap.GasUsed = 50000
+		cheap.TransactionIndex = 1
+		// direct calls are not priced
+		directCall := mocks.NewReceipt(height, common.HexToHash("0x1"))
+		directCall.Type = evmTypes.DirectCallTxType
+		directCall.TransactionIndex = 2
+
+		err := s.ReceiptIndexer.Store([]*models.Receipt{expensive, cheap, directCall}, nil)
+		s.Require().NoError(err)
+
+		fees, err := s.ReceiptIndexer.FeesByBlockHeight(height)
+		s.Require().NoError(err)
+		// the tips are the gas prices above the base fee
+		s.Require().Equal([]*models.TransactionFee{
+			{GasUsed: 50000, Tip: big.NewInt(99)},
+			{GasUsed: 21000, Tip: big.NewInt(299)},
+		}, fees)
+	})
+
+	s.Run("non-existing block height", func() {
+		fees, err := s.ReceiptIndexer.FeesByBlockHeight(1337)
+		s.Require().Nil(fees)
+		s.Require().ErrorIs(err, errors.ErrEntityNotFound)
+	})
+}
+
 func (s *ReceiptTestSuite) TestBloomsForBlockRange() {
 
 	s.Run("valid block range", func() {
@@ -493,61 +527,92 @@ type AccountTestSuite struct {
 func (a *AccountTestSuite) TestNonce() {
 
 	a.Run("update account and increase nonce", func() {
-		// todo add multiple accounts test
 		from := common.HexToAddress("FACF71692421039876a5BB4F10EF7A439D8ef61E")
-		rawKey := "f6d5333177711e562cabf1f311916196ee6ffc2a07966d9d4628094073bd5442"
-		key, err := crypto.HexToECDSA(rawKey)
-		a.Require().NoError(err)
 
-		nonce, err := a.AccountIndexer.GetNonce(from)
-		a.Require().NoError(err)
-		a.Require().Equal(uint64(0), nonce)
+		_, err := a.AccountIndexer.GetNonce(from, 10)
+		a.Require().ErrorIs(err, errors.ErrEntityNotFound)
 
 		for i := 1; i < 5; i++ {
-			tx := mocks.NewTransaction(0)
-
-			txCall, ok := tx.(models.TransactionCall)
-			a.Require().True(ok)
-
-			txHash := tx.Hash()
-
-			rcp := mocks.NewReceipt(uint64(i+5), txHash)
-			gethTx, err := types.SignTx(txCall.Transaction, evmEmulator.GetDefaultSigner(), key)
+			account := &models.Account{Address: from, Nonce: uint64(i), Balance: big.NewInt(0)}
+			err = a.AccountIndexer.Update([]*models.Account{account}, uint64(i+5), nil)
 			a.Require().NoError(err)
 
-			tx = models.TransactionCall{Transaction: gethTx}
+			nonce, err := a.AccountIndexer.GetNonce(from, uint64(i+5))
+			a.Require().NoError(err)
+			a.Require().Equal(uint64(i), nonce)
+		}
 
-			err = a.AccountIndexer.Update(tx, rcp, nil)
+		// if run second time we should still see same nonce values, since the
+		// accounts are stored by the evm height, and the same values are overwritten
+		for i := 1; i < 5; i++ {
+			account := &models.Account{Address: from, Nonce: uint64(i), Balance: big.NewInt(0)}
+			err = a.AccountIndexer.Update([]*models.Account{account}, uint64(i+5), nil)
 			a.Require().NoError(err)
 
-			nonce, err = a.AccountIndexer.GetNonce(from)
+			nonce, err := a.AccountIndexer.GetNonce(from, 20)
 			a.Require().NoError(err)
-			a.Require().Equal(uint64(i), nonce)
+			a.Require().Equal(uint64(4), nonce) // always equal to latest nonce
 		}
 
-		// if run second time we should still see same nonce values, since they won't be incremented
-		// because we track nonce with evm height, and if same height is used twice we don't update
-		for i := 1; i < 5; i++ {
-			tx := mocks.NewTransaction(0)
+		// the nonce at the heights before the account was indexed is not known
+		_, err = a.AccountIndexer.GetNonce(from, 5)
+		a.Require().ErrorIs(err, errors.ErrEntityNotFound)
 
-			txCall, ok := tx.(models.TransactionCall)
-			a.Require().True(ok)
+		// the nonce at the heights the account didn't change is the previous one
+		err = a.AccountIndexer.Update([]*models.Account{{Address: from, Nonce: 10}}, 30, nil)
+		a.Require().NoError(err)
 
-			txHash := tx.Hash()
+		nonce, err := a.AccountIndexer.GetNonce(from, 29)
+		a.Require().NoError(err)
+		a.Require().Equal(uint64(4), nonce)
 
-			rcp := mocks.NewReceipt(uint64(i+5), txHash)
-			gethTx, err := types.SignTx(txCall.Transaction, evmEmulator.GetDefaultSigner(), key)
-			a.Require().NoError(err)
+		nonce, err = a.AccountIndexer.GetNonce(from, 30)
+		a.Require().NoError(err)
+		a.Require().Equal(uint64(10), nonce)
+	})
+}
 
-			tx = models.TransactionCall{Transaction: gethTx}
+func (a *AccountTestSuite) TestBalance() {
 
-			err = a.AccountIndexer.Update(tx, rcp, nil)
-			a.Require().NoError(err)
+	a.Run("update account balances at heights", func() {
+		first := common.HexToAddress("0x0101")
+		second := common.HexToAddress("0x0102")
 
-			nonce, err = a.AccountIndexer.GetNonce(from)
-			a.Require().NoError(err)
-			a.Require().Equal(uint64(4), nonce) // always equal to latest nonce
-		}
+		err := a.AccountIndexer.Update([]*models.Account{
+			{Address: first, Nonce: 1, Balance: big.NewInt(1000)},
+			{Address: second, Nonce: 0, Balance: big.NewInt(5)},
+		}, 10, nil)
+		a.Require().NoError(err)
+
+		err = a.AccountIndexer.Update([]*models.Account{
+			{Address: first, Nonce: 2, Balance: big.NewInt(800)},
+		}, 12, nil)
+		a.Require().NoError(err)
+
+		_, err = a.AccountIndexer.GetBalance(first, 9)
+		a.Require().ErrorIs(err, errors.ErrEntityNotFound)
+
+		balance, err := a.AccountIndexer.GetBalance(first, 11)
+		a.Require().NoError(err)
+		a.Require().Equal(big.NewInt(1000), balance)
+
+		balance, err = a.AccountIndexer.GetBalance(first, 12)
+		a.Require().NoError(err)
+		a.Require().Equal(big.NewInt(800), balance)
+
+		balance, err = a.AccountIndexer.GetBalance(second, 12)
+		a.Require().NoError(err)
+		a.Require().Equal(big.NewInt(5), balance)
+
+		// deleted accounts are stored with zero values
+		err = a.AccountIndexer.Update([]*models.Account{
+			{Address: second, Nonce: 0, Balance: big.NewInt(0)},
+		}, 13, nil)
+		a.Require().NoError(err)
+
+		balance, err = a.AccountIndexer.GetBalance(second, 13)
+		a.Require().NoError(err)
+		a.Require().Equal(0, balance.Sign())
 	})
 }
 
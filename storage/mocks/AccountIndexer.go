@@ -18,9 +18,9 @@ type AccountIndexer struct {
 	mock.Mock
 }
 
-// GetBalance provides a mock function with given fields: address
-func (_m *AccountIndexer) GetBalance(address common.Address) (*big.Int, error) {
-	ret := _m.Called(address)
+// GetBalance provides a mock function with given fields: address, evmHeight
+func (_m *AccountIndexer) GetBalance(address common.Address, evmHeight uint64) (*big.Int, error) {
+	ret := _m.Called(address, evmHeight)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetBalance")
@@ -28,19 +28,19 @@ func (_m *AccountIndexer) GetBalance(address common.Address) (*big.Int, error) {
 
 	var r0 *big.Int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(common.Address) (*big.Int, error)); ok {
-		return rf(address)
+	if rf, ok := ret.Get(0).(func(common.Address, uint64) (*big.Int, error)); ok {
+		return rf(address, evmHeight)
 	}
-	if rf, ok := ret.Get(0).(func(common.Address) *big.Int); ok {
-		r0 = rf(address)
+	if rf, ok := ret.Get(0).(func(common.Address, uint64) *big.Int); ok {
+		r0 = rf(address, evmHeight)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*big.Int)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(common.Address) error); ok {
-		r1 = rf(address)
+	if rf, ok := ret.Get(1).(func(common.Address, uint64) error); ok {
+		r1 = rf(address, evmHeight)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -48,9 +48,9 @@ func (_m *AccountIndexer) GetBalance(address common.Address) (*big.Int, error) {
 	return r0, r1
 }
 
-// GetNonce provides a mock function with given fields: address
-func (_m *AccountIndexer) GetNonce(address common.Address) (uint64, error) {
-	ret := _m.Called(address)
+// GetNonce provides a mock function with given fields: address, evmHeight
+func (_m *AccountIndexer) GetNonce(address common.Address, evmHeight uint64) (uint64, error) {
+	ret := _m.Called(address, evmHeight)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetNonce")
@@ -58,17 +58,17 @@ func (_m *AccountIndexer) GetNonce(address common.Address) (uint64, error) {
 
 	var r0 uint64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(common.Address) (uint64, error)); ok {
-		return rf(address)
+	if rf, ok := ret.Get(0).(func(common.Address, uint64) (uint64, error)); ok {
+		return rf(address, evmHeight)
 	}
-	if rf, ok := ret.Get(0).(func(common.Address) uint64); ok {
-		r0 = rf(address)
+	if rf, ok := ret.Get(0).(func(common.Address, uint64) uint64); ok {
+		r0 = rf(address, evmHeight)
 	} else {
 		r0 = ret.Get(0).(uint64)
 	}
 
-	if rf, ok := ret.Get(1).(func(common.Address) error); ok {
-		r1 = rf(address)
+	if rf, ok := ret.Get(1).(func(common.Address, uint64) error); ok {
+		r1 = rf(address, evmHeight)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -76,17 +76,17 @@ func (_m *AccountIndexer) GetNonce(address common.Address) (uint64, error) {
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: tx, receipt, batch
-func (_m *AccountIndexer) Update(tx models.Transaction, receipt *models.Receipt, batch *pebble.Batch) error {
-	ret := _m.Called(tx, receipt, batch)
+// Update provides a mock function with given fields: accounts, evmHeight, batch
+func (_m *AccountIndexer) Update(accounts []*models.Account, evmHeight uint64, batch *pebble.Batch) error {
+	ret := _m.Called(accounts, evmHeight, batch)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(models.Transaction, *models.Receipt, *pebble.Batch) error); ok {
-		r0 = rf(tx, receipt, batch)
+	if rf, ok := ret.Get(0).(func([]*models.Account, uint64, *pebble.Batch) error); ok {
+		r0 = rf(accounts, evmHeight, batch)
 	} else {
 		r0 = ret.Error(0)
 	}
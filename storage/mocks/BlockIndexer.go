@@ -18,6 +18,34 @@ type BlockIndexer struct {
 	mock.Mock
 }
 
+// FirstEVMHeight provides a mock function with given fields:
+func (_m *BlockIndexer) FirstEVMHeight() (uint64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FirstEVMHeight")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (uint64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByHeight provides a mock function with given fields: height
 func (_m *BlockIndexer) GetByHeight(height uint64) (*models.Block, error) {
 	ret := _m.Called(height)
@@ -46,6 +46,36 @@ func (_m *ReceiptIndexer) BloomsForBlockRange(start uint64, end uint64) ([]*mode
 	return r0, r1
 }
 
+// FeesByBlockHeight provides a mock function with given fields: height
+func (_m *ReceiptIndexer) FeesByBlockHeight(height uint64) ([]*models.TransactionFee, error) {
+	ret := _m.Called(height)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FeesByBlockHeight")
+	}
+
+	var r0 []*models.TransactionFee
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) ([]*models.TransactionFee, error)); ok {
+		return rf(height)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) []*models.TransactionFee); ok {
+		r0 = rf(height)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.TransactionFee)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(height)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByBlockHeight provides a mock function with given fields: height
 func (_m *ReceiptIndexer) GetByBlockHeight(height uint64) ([]*models.Receipt, error) {
 	ret := _m.Called(height)
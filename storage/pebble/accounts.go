@@ -2,7 +2,6 @@ package pebble
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -17,6 +16,8 @@ import (
 
 var _ storage.AccountIndexer = &Accounts{}
 
+// Accounts stores the nonce and balance of the accounts, versioned by the
+// EVM height they were changed at, so they can be read at any indexed height.
 type Accounts struct {
 	store *Storage
 	mux   sync.RWMutex
@@ -30,95 +31,113 @@ func NewAccounts(db *Storage) *Accounts {
 }
 
 func (a *Accounts) Update(
-	tx models.Transaction,
-	receipt *models.Receipt,
+	accounts []*models.Account,
+	evmHeight uint64,
 	batch *pebble.Batch,
 ) error {
 	a.mux.Lock()
 	defer a.mux.Unlock()
 
-	from, err := tx.From()
-	if err != nil {
-		return err
+	// storing the same accounts at the same height again overwrites them with
+	// the same values, which makes the operation idempotent.
+	for _, account := range accounts {
+		err := a.store.set(
+			accountAtHeightKey,
+			accountAtHeight(account.Address, evmHeight),
+			encodeAccount(account.Nonce, account.Balance),
+			batch,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to store account: %s at height: %d, with: %w",
+				account.Address,
+				evmHeight,
+				err,
+			)
+		}
 	}
 
-	nonce, height, err := a.getNonce(from, batch)
-	if err != nil {
-		return err
-	}
+	return nil
+}
 
-	// make sure the transaction height is bigger than the height we already
-	// recorded for the nonce. this makes the operation idempotent and safer.
-	txHeight := receipt.BlockNumber.Uint64()
-	if txHeight <= height {
-		return nil
-	}
+func (a *Accounts) GetNonce(address common.Address, evmHeight uint64) (uint64, error) {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
 
-	nonce += 1
+	nonce, _, err := a.getAccount(address, evmHeight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce of address: %s, with: %w", address, err)
+	}
 
-	data := encodeNonce(nonce, txHeight)
-	return a.store.set(accountNonceKey, from.Bytes(), data, batch)
+	return nonce, nil
 }
 
-func (a *Accounts) getNonce(address common.Address, batch *pebble.Batch) (uint64, uint64, error) {
-	var val []byte
-	var err error
-	if batch != nil {
-		val, err = a.store.batchGet(batch, accountNonceKey, address.Bytes())
-	} else {
-		val, err = a.store.get(accountNonceKey, address.Bytes())
-	}
-	if err != nil {
-		// if no nonce was yet saved for the account the nonce is 0
-		if errors.Is(err, errs.ErrEntityNotFound) {
-			return 0, 0, nil
-		}
+func (a *Accounts) GetBalance(address common.Address, evmHeight uint64) (*big.Int, error) {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
 
-		return 0, 0, err
+	_, balance, err := a.getAccount(address, evmHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance of address: %s, with: %w", address, err)
 	}
 
-	nonce, height, err := decodeNonce(val)
+	return balance, nil
+}
+
+// getAccount returns the nonce and balance of the account stored at the
+// latest height at or below the EVM height.
+func (a *Accounts) getAccount(address common.Address, evmHeight uint64) (uint64, *big.Int, error) {
+	// the versions of the account are ordered by height, and the upper
+	// bound is exclusive, so the last version is the account at the height
+	iterator, err := a.store.db.NewIter(&pebble.IterOptions{
+		LowerBound: makePrefix(accountAtHeightKey, accountAtHeight(address, 0)),
+		UpperBound: makePrefix(accountAtHeightKey, accountAtHeight(address, evmHeight+1)),
+	})
 	if err != nil {
-		return 0, 0, err
+		return 0, nil, err
 	}
+	defer func() {
+		if err := iterator.Close(); err != nil {
+			a.store.log.Error().Err(err).Msg("failed to close accounts iterator")
+		}
+	}()
 
-	return nonce, height, nil
-}
+	if !iterator.Last() {
+		if err := iterator.Error(); err != nil {
+			return 0, nil, err
+		}
+		return 0, nil, fmt.Errorf("%w: account not indexed at height: %d", errs.ErrEntityNotFound, evmHeight)
+	}
 
-func (a *Accounts) GetNonce(address common.Address) (uint64, error) {
-	a.mux.RLock()
-	defer a.mux.RUnlock()
-	nonce, _, err := a.getNonce(address, nil)
+	val, err := iterator.ValueAndErr()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get nonce of address: %s, with: %w", address, err)
+		return 0, nil, err
 	}
 
-	return nonce, nil
+	return decodeAccount(val)
 }
 
-func (a *Accounts) GetBalance(address common.Address) (*big.Int, error) {
-	panic("not supported")
+// accountAtHeight makes the key of the account at the EVM height.
+func accountAtHeight(address common.Address, evmHeight uint64) []byte {
+	return append(address.Bytes(), uint64Bytes(evmHeight)...)
 }
 
-// decodeNonce converts nonce data into nonce and height
-func decodeNonce(data []byte) (uint64, uint64, error) {
-	if len(data) != 16 {
-		return 0, 0, fmt.Errorf("invalid nonce data, expected length: %d, got: %d", 16, len(data))
+// decodeAccount converts account data into nonce and balance
+func decodeAccount(data []byte) (uint64, *big.Int, error) {
+	if len(data) < 8 {
+		return 0, nil, fmt.Errorf("invalid account data, expected length of at least: %d, got: %d", 8, len(data))
 	}
 	nonce := binary.BigEndian.Uint64(data[:8])
-	height := binary.BigEndian.Uint64(data[8:])
+	balance := new(big.Int).SetBytes(data[8:])
 
-	return nonce, height, nil
+	return nonce, balance, nil
 }
 
-// encodeNonce converts nonce and height into nonce data
-func encodeNonce(nonce uint64, height uint64) []byte {
-	payload := make([]byte, 16)
-	for i, b := range uint64Bytes(nonce) {
-		payload[i] = b
-	}
-	for i, b := range uint64Bytes(height) {
-		payload[i+8] = b
+// encodeAccount converts nonce and balance into account data
+func encodeAccount(nonce uint64, balance *big.Int) []byte {
+	payload := uint64Bytes(nonce)
+	if balance != nil {
+		payload = append(payload, balance.Bytes()...)
 	}
 
 	return payload
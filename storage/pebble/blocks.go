@@ -195,6 +195,35 @@ func (b *Blocks) latestEVMHeight() (uint64, error) {
 	return binary.BigEndian.Uint64(val), nil
 }
 
+func (b *Blocks) FirstEVMHeight() (uint64, error) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	if _, err := b.latestEVMHeight(); err != nil {
+		return 0, err
+	}
+
+	// the genesis block at height zero is skipped
+	iterator, err := b.store.db.NewIter(&pebble.IterOptions{
+		LowerBound: makePrefix(evmHeightToCadenceHeightKey, uint64Bytes(1)),
+		UpperBound: makePrefix(evmHeightToCadenceHeightKey + 1),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := iterator.Close(); err != nil {
+			b.store.log.Error().Err(err).Msg("failed to close blocks iterator")
+		}
+	}()
+
+	if !iterator.First() {
+		return 0, iterator.Error()
+	}
+
+	return binary.BigEndian.Uint64(stripPrefix(iterator.Key())), nil
+}
+
 func (b *Blocks) LatestCadenceHeight() (uint64, error) {
 	b.mux.RLock()
 	defer b.mux.RUnlock()
@@ -16,21 +16,32 @@ const (
 	receiptTxIDToHeightKey = byte(20)
 	receiptHeightKey       = byte(21)
 	bloomHeightKey         = byte(22)
+	feesHeightKey          = byte(23)
 
-	// account keys
-	accountNonceKey   = byte(30)
-	accountBalanceKey = byte(31)
+	// account keys, the nonce and balance keys are no longer written
+	// and are kept so the codes aren't reused by the existing databases
+	accountNonceKey    = byte(30)
+	accountBalanceKey  = byte(31)
+	accountAtHeightKey = byte(32)
 
 	// traces keys
 	traceTxIDKey = byte(40)
 
+	// submission keys
+	submissionKey        = byte(45)
+	pendingSubmissionKey = byte(46)
+
 	// ledger value
 	ledgerValue     = byte(50)
 	ledgerSlabIndex = byte(51)
+	// ledger value versioned by Cadence height
+	ledgerValueAtHeight = byte(52)
 
 	// special keys
-	latestEVMHeightKey     = byte(100)
-	latestCadenceHeightKey = byte(102)
+	latestEVMHeightKey      = byte(100)
+	latestCadenceHeightKey  = byte(102)
+	latestRegisterHeightKey = byte(103)
+	archiveStartHeightKey   = byte(104)
 )
 
 // makePrefix makes a key used internally to store the values
@@ -1,24 +1,33 @@
 package pebble
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"sync"
 
+	"github.com/cockroachdb/pebble"
 	"github.com/onflow/atree"
+	"github.com/onflow/flow-go/model/flow"
 
 	errs "github.com/onflow/flow-evm-gateway/models/errors"
+	"github.com/onflow/flow-evm-gateway/storage"
 )
 
 var _ atree.Ledger = &Ledger{}
+var _ storage.RegisterIndexer = &Ledger{}
+var _ storage.RegisterSnapshot = &LedgerSnapshot{}
+var _ storage.RegisterArchiveView = &LedgerView{}
 
-// todo we need to support historic data,
-// we likely need to create ledger with the context of block height
-// and then prepend all keys with that height
-
+// Ledger stores the latest register values. In the archive mode the register
+// values are also versioned by the Cadence height they were updated at,
+// so the registers can be read at any height since the archive started.
 type Ledger struct {
-	store *Storage
-	mux   sync.RWMutex
+	store   *Storage
+	mux     sync.RWMutex
+	archive bool
 }
 
 func NewLedger(store *Storage) *Ledger {
@@ -28,6 +37,15 @@ func NewLedger(store *Storage) *Ledger {
 	}
 }
 
+// NewArchiveLedger creates a ledger which keeps the register values at every height.
+func NewArchiveLedger(store *Storage) *Ledger {
+	return &Ledger{
+		store:   store,
+		mux:     sync.RWMutex{},
+		archive: true,
+	}
+}
+
 func (l *Ledger) GetValue(owner, key []byte) ([]byte, error) {
 	l.mux.RLock()
 	defer l.mux.RUnlock()
@@ -112,3 +130,283 @@ func (l *Ledger) AllocateSlabIndex(owner []byte) (atree.SlabIndex, error) {
 
 	return index, nil
 }
+
+// Store the register values updated at the Cadence height, together with the height.
+// The registers are indexed by the owner and key, so only the latest values are kept.
+func (l *Ledger) Store(registers flow.RegisterEntries, cadenceHeight uint64, batch *pebble.Batch) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for _, register := range registers {
+		id := append([]byte(register.Key.Owner), register.Key.Key...)
+		if err := l.store.set(ledgerValue, id, register.Value, batch); err != nil {
+			return fmt.Errorf(
+				"failed to store ledger value for owner %x and key %x: %w",
+				register.Key.Owner,
+				register.Key.Key,
+				err,
+			)
+		}
+
+		if !l.archive {
+			continue
+		}
+		versionedID := registerAtHeight(register.Key, cadenceHeight)
+		if err := l.store.set(ledgerValueAtHeight, versionedID, register.Value, batch); err != nil {
+			return fmt.Errorf(
+				"failed to store ledger value for owner %x and key %x at height %d: %w",
+				register.Key.Owner,
+				register.Key.Key,
+				cadenceHeight,
+				err,
+			)
+		}
+	}
+
+	if l.archive {
+		if err := l.initArchive(cadenceHeight, batch); err != nil {
+			return err
+		}
+	}
+
+	if err := l.store.set(latestRegisterHeightKey, nil, uint64Bytes(cadenceHeight), batch); err != nil {
+		return fmt.Errorf("failed to store latest register height %d: %w", cadenceHeight, err)
+	}
+
+	return nil
+}
+
+func (l *Ledger) LatestCadenceHeight() (uint64, error) {
+	val, err := l.store.get(latestRegisterHeightKey)
+	if err != nil {
+		if errors.Is(err, errs.ErrEntityNotFound) {
+			return 0, errs.ErrStorageNotInitialized
+		}
+		return 0, fmt.Errorf("failed to get latest register height: %w", err)
+	}
+
+	return binary.BigEndian.Uint64(val), nil
+}
+
+// ViewAt returns a read-only view of the registers at the Cadence height, the height
+// must be between the height the archive started at and the latest indexed height.
+// The heights before the archive start return errors.HeightNotArchived.
+func (l *Ledger) ViewAt(cadenceHeight uint64) (storage.RegisterArchiveView, error) {
+	if !l.archive {
+		return nil, fmt.Errorf("registers at height %d not available: %w", cadenceHeight, errs.ErrHeightOutOfRange)
+	}
+
+	start, err := l.store.get(archiveStartHeightKey)
+	if err != nil {
+		if errors.Is(err, errs.ErrEntityNotFound) {
+			return nil, errs.ErrStorageNotInitialized
+		}
+		return nil, fmt.Errorf("failed to get archive start height: %w", err)
+	}
+
+	latest, err := l.LatestCadenceHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// the registers not updated since the archive started aren't archived,
+	// so the state before the start height can't be read
+	if startHeight := binary.BigEndian.Uint64(start); cadenceHeight < startHeight {
+		return nil, errs.NewHeightNotArchivedError(cadenceHeight, startHeight)
+	}
+	if cadenceHeight > latest {
+		return nil, errs.NewHeightOutOfRangeError(cadenceHeight)
+	}
+
+	return &LedgerView{store: l.store, height: cadenceHeight}, nil
+}
+
+// initArchive stores the height the archive started at, if not stored yet.
+func (l *Ledger) initArchive(cadenceHeight uint64, batch *pebble.Batch) error {
+	var err error
+	if batch != nil {
+		_, err = l.store.batchGet(batch, archiveStartHeightKey)
+	} else {
+		_, err = l.store.get(archiveStartHeightKey)
+	}
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, errs.ErrEntityNotFound) {
+		return fmt.Errorf("failed to get archive start height: %w", err)
+	}
+
+	if err := l.store.set(archiveStartHeightKey, nil, uint64Bytes(cadenceHeight), batch); err != nil {
+		return fmt.Errorf("failed to store archive start height %d: %w", cadenceHeight, err)
+	}
+
+	return nil
+}
+
+// Snapshot returns a read-only view of the registers at the latest indexed height.
+// The snapshot is consistent with the blocks indexed in the same batch as the registers.
+func (l *Ledger) Snapshot() (storage.RegisterSnapshot, error) {
+	snapshot := l.store.db.NewSnapshot()
+
+	cadenceHeight, err := snapshotHeight(snapshot, latestRegisterHeightKey)
+	if err != nil {
+		_ = snapshot.Close()
+		return nil, fmt.Errorf("failed to get latest register height: %w", err)
+	}
+
+	evmHeight, err := snapshotHeight(snapshot, latestEVMHeightKey)
+	if err != nil {
+		_ = snapshot.Close()
+		return nil, fmt.Errorf("failed to get latest EVM height: %w", err)
+	}
+
+	return &LedgerSnapshot{
+		snapshot:      snapshot,
+		cadenceHeight: cadenceHeight,
+		evmHeight:     evmHeight,
+	}, nil
+}
+
+// LedgerSnapshot is a read-only view of the registers indexed up to a Cadence height.
+type LedgerSnapshot struct {
+	snapshot      *pebble.Snapshot
+	cadenceHeight uint64
+	evmHeight     uint64
+}
+
+func (s *LedgerSnapshot) CadenceHeight() uint64 {
+	return s.cadenceHeight
+}
+
+func (s *LedgerSnapshot) EVMHeight() uint64 {
+	return s.evmHeight
+}
+
+func (s *LedgerSnapshot) GetValue(owner, key []byte) ([]byte, bool, error) {
+	id := append(append([]byte{}, owner...), key...)
+	val, err := snapshotGet(s.snapshot, makePrefix(ledgerValue, id))
+	if err != nil {
+		if errors.Is(err, errs.ErrEntityNotFound) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf(
+			"failed to get ledger value at owner %x and key %x: %w",
+			owner,
+			key,
+			err,
+		)
+	}
+
+	return val, true, nil
+}
+
+func (s *LedgerSnapshot) Close() error {
+	return s.snapshot.Close()
+}
+
+func snapshotHeight(snapshot *pebble.Snapshot, keyCode byte) (uint64, error) {
+	val, err := snapshotGet(snapshot, makePrefix(keyCode))
+	if err != nil {
+		if errors.Is(err, errs.ErrEntityNotFound) {
+			return 0, errs.ErrStorageNotInitialized
+		}
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint64(val), nil
+}
+
+func snapshotGet(snapshot *pebble.Snapshot, key []byte) ([]byte, error) {
+	data, closer, err := snapshot.Get(key)
+	if err != nil {
+		if errors.Is(err, pebble.ErrNotFound) {
+			return nil, errs.ErrEntityNotFound
+		}
+		return nil, err
+	}
+	defer func(closer io.Closer) {
+		_ = closer.Close()
+	}(closer)
+
+	cp := make([]byte, len(data))
+	copy(cp, data)
+	return cp, nil
+}
+
+// LedgerView is a read-only view of the registers at a Cadence height.
+type LedgerView struct {
+	store  *Storage
+	height uint64
+}
+
+// GetValue returns the latest register value stored at or below the view height.
+func (v *LedgerView) GetValue(owner, key []byte) ([]byte, bool, error) {
+	id := flow.RegisterID{Owner: string(owner), Key: string(key)}
+	// the versions of the register are ordered by height, and the upper
+	// bound is exclusive, so the last version is the value at the height
+	iterator, err := v.store.db.NewIter(&pebble.IterOptions{
+		LowerBound: makePrefix(ledgerValueAtHeight, registerAtHeight(id, 0)),
+		UpperBound: makePrefix(ledgerValueAtHeight, registerAtHeight(id, v.height+1)),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		if err := iterator.Close(); err != nil {
+			v.store.log.Error().Err(err).Msg("failed to close ledger iterator")
+		}
+	}()
+
+	if !iterator.Last() {
+		return nil, false, iterator.Error()
+	}
+
+	val, err := iterator.ValueAndErr()
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"failed to get ledger value at owner %x and key %x at height %d: %w",
+			owner,
+			key,
+			v.height,
+			err,
+		)
+	}
+
+	cp := make([]byte, len(val))
+	copy(cp, val)
+	return cp, true, nil
+}
+
+// UpdatedAfter returns whether any version of the register is stored above the view height.
+func (v *LedgerView) UpdatedAfter(owner, key []byte) (bool, error) {
+	id := flow.RegisterID{Owner: string(owner), Key: string(key)}
+	iterator, err := v.store.db.NewIter(&pebble.IterOptions{
+		LowerBound: makePrefix(ledgerValueAtHeight, registerAtHeight(id, v.height+1)),
+		UpperBound: makePrefix(ledgerValueAtHeight, registerAtHeight(id, math.MaxUint64)),
+	})
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := iterator.Close(); err != nil {
+			v.store.log.Error().Err(err).Msg("failed to close ledger iterator")
+		}
+	}()
+
+	if !iterator.First() {
+		return false, iterator.Error()
+	}
+
+	return true, nil
+}
+
+// registerAtHeight makes the key of the register value at the height, the register
+// key is length prefixed so the versions of different registers don't interleave.
+func registerAtHeight(id flow.RegisterID, height uint64) []byte {
+	key := make([]byte, 0, len(id.Owner)+binary.MaxVarintLen64+len(id.Key)+8)
+	key = append(key, id.Owner...)
+	key = binary.AppendUvarint(key, uint64(len(id.Key)))
+	key = append(key, id.Key...)
+	return append(key, uint64Bytes(height)...)
+}
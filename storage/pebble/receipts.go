@@ -2,6 +2,7 @@ package pebble
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -36,6 +37,7 @@ func NewReceipts(store *Storage) *Receipts {
 // - receipt transaction ID => block height bytes
 // - receipt block height => list of encoded receipts (1+ per block)
 // - receipt block height => list of bloom filters (1+ per block)
+// - receipt block height => list of transaction fees sorted by tip (0+ per block)
 func (r *Receipts) Store(receipts []*models.Receipt, batch *pebble.Batch) error {
 	r.mux.Lock()
 	defer r.mux.Unlock()
@@ -87,6 +89,15 @@ func (r *Receipts) Store(receipts []*models.Receipt, batch *pebble.Batch) error
 		return fmt.Errorf("failed to store blooms at height: %d, with: %w", height, err)
 	}
 
+	feesBytes, err := rlp.EncodeToBytes(models.TransactionFees(receipts))
+	if err != nil {
+		return fmt.Errorf("failed to encode fees for height: %d, with: %w", height, err)
+	}
+
+	if err := r.store.set(feesHeightKey, heightBytes, feesBytes, batch); err != nil {
+		return fmt.Errorf("failed to store fees at height: %d, with: %w", height, err)
+	}
+
 	return nil
 }
 
@@ -124,6 +135,27 @@ func (r *Receipts) GetByBlockHeight(height uint64) ([]*models.Receipt, error) {
 	return r.getByBlockHeight(uint64Bytes(height), nil)
 }
 
+func (r *Receipts) FeesByBlockHeight(height uint64) ([]*models.TransactionFee, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	val, err := r.store.get(feesHeightKey, uint64Bytes(height))
+	if err != nil {
+		if !errors.Is(err, errs.ErrEntityNotFound) {
+			return nil, err
+		}
+		// the fees of the blocks indexed before the fees were
+		// indexed are derived from the receipts
+		receipts, err := r.getByBlockHeight(uint64Bytes(height), nil)
+		if err != nil {
+			return nil, err
+		}
+		return models.TransactionFees(receipts), nil
+	}
+
+	return models.TransactionFeesFromBytes(val)
+}
+
 func (r *Receipts) getByBlockHeight(height []byte, batch *pebble.Batch) ([]*models.Receipt, error) {
 	var val []byte
 	var err error
@@ -84,6 +84,20 @@ func (s *Storage) set(keyCode byte, key []byte, value []byte, batch *pebble.Batc
 	return s.db.Set(prefixedKey, value, nil)
 }
 
+// delete the key identified by key code (which act as an entity identifier).
+//
+// Optional batch argument makes the operation atomic, but it's up to the caller to
+// commit the batch or revert it.
+func (s *Storage) delete(keyCode byte, key []byte, batch *pebble.Batch) error {
+	prefixedKey := makePrefix(keyCode, key)
+
+	if batch != nil {
+		return batch.Delete(prefixedKey, nil)
+	}
+
+	return s.db.Delete(prefixedKey, nil)
+}
+
 func (s *Storage) get(keyCode byte, key ...[]byte) ([]byte, error) {
 	prefixedKey := makePrefix(keyCode, key...)
 
@@ -1,6 +1,7 @@
 package pebble
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/cockroachdb/pebble"
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/onflow/flow-evm-gateway/config"
+	"github.com/onflow/flow-evm-gateway/models"
 	"github.com/onflow/flow-evm-gateway/models/errors"
 	"github.com/onflow/flow-evm-gateway/storage"
 	"github.com/onflow/flow-evm-gateway/storage/mocks"
@@ -107,6 +109,29 @@ func TestBlock(t *testing.T) {
 		require.Equal(t, cadenceID, cid)
 	})
 
+	runDB("first EVM height", t, func(t *testing.T, db *Storage) {
+		blocks := NewBlocks(db, flowGo.Emulator)
+		_, err := blocks.FirstEVMHeight()
+		require.ErrorIs(t, err, errors.ErrStorageNotInitialized)
+
+		err = blocks.InitHeights(config.EmulatorInitCadenceHeight, flow.Identifier{0x1})
+		require.NoError(t, err)
+
+		// only the genesis block is stored
+		first, err := blocks.FirstEVMHeight()
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), first)
+
+		for _, height := range []uint64{10, 11, 12} {
+			err = blocks.Store(height+100, flow.Identifier{byte(height)}, mocks.NewBlock(height), nil)
+			require.NoError(t, err)
+		}
+
+		first, err = blocks.FirstEVMHeight()
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), first)
+	})
+
 	runDB("get not found block error", t, func(t *testing.T, db *Storage) {
 		blocks := NewBlocks(db, flowGo.Emulator)
 		err := blocks.InitHeights(config.EmulatorInitCadenceHeight, flow.Identifier{0x1})
@@ -124,14 +149,14 @@ func TestBlock(t *testing.T) {
 }
 
 func TestAccount(t *testing.T) {
-	t.Run("encoding decoding nonce data", func(t *testing.T) {
+	t.Run("encoding decoding account data", func(t *testing.T) {
 		nonce := uint64(10)
-		height := uint64(20)
-		raw := encodeNonce(10, 20)
-		decNonce, decHeight, err := decodeNonce(raw)
+		balance := big.NewInt(20)
+		raw := encodeAccount(nonce, balance)
+		decNonce, decBalance, err := decodeAccount(raw)
 		require.NoError(t, err)
 		assert.Equal(t, nonce, decNonce)
-		assert.Equal(t, height, decHeight)
+		assert.Equal(t, balance, decBalance)
 	})
 }
 
@@ -212,6 +237,187 @@ func TestBatch(t *testing.T) {
 	})
 }
 
+func TestLedger(t *testing.T) {
+	owner := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	register := func(key string, value []byte) flowGo.RegisterEntry {
+		return flowGo.RegisterEntry{
+			Key:   flowGo.RegisterID{Owner: string(owner), Key: key},
+			Value: value,
+		}
+	}
+
+	runDB("snapshot requires indexed registers", t, func(t *testing.T, db *Storage) {
+		ledger := NewLedger(db)
+
+		_, err := ledger.Snapshot()
+		require.ErrorIs(t, err, errors.ErrStorageNotInitialized)
+
+		_, err = ledger.LatestCadenceHeight()
+		require.ErrorIs(t, err, errors.ErrStorageNotInitialized)
+	})
+
+	runDB("snapshot of the stored registers", t, func(t *testing.T, db *Storage) {
+		ledger := NewLedger(db)
+		blocks := NewBlocks(db, flowGo.Emulator)
+		require.NoError(t, blocks.InitHeights(config.EmulatorInitCadenceHeight, flow.Identifier{0x1}))
+
+		batch := db.NewBatch()
+		require.NoError(t, blocks.Store(20, flow.Identifier{0x2}, mocks.NewBlock(10), batch))
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{
+			register("a", []byte{1}),
+			register("b", []byte{}),
+		}, 20, batch))
+		require.NoError(t, batch.Commit(pebble.Sync))
+		require.NoError(t, batch.Close())
+
+		snapshot, err := ledger.Snapshot()
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, snapshot.Close())
+		}()
+
+		// the following updates are not visible in the snapshot
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{register("a", []byte{2})}, 21, nil))
+
+		assert.Equal(t, uint64(20), snapshot.CadenceHeight())
+		assert.Equal(t, uint64(10), snapshot.EVMHeight())
+
+		value, ok, err := snapshot.GetValue(owner, []byte("a"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte{1}, value)
+
+		value, ok, err = snapshot.GetValue(owner, []byte("b"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Empty(t, value)
+
+		_, ok, err = snapshot.GetValue(owner, []byte("c"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		height, err := ledger.LatestCadenceHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(21), height)
+
+		value, err = ledger.GetValue(owner, []byte("a"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, value)
+	})
+
+	runDB("view of the registers at height", t, func(t *testing.T, db *Storage) {
+		ledger := NewArchiveLedger(db)
+
+		_, err := ledger.ViewAt(10)
+		require.ErrorIs(t, err, errors.ErrStorageNotInitialized)
+
+		// multiple heights stored in the same batch
+		batch := db.NewBatch()
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{register("a", []byte{1})}, 10, batch))
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{register("a\x00", []byte{9})}, 11, batch))
+		require.NoError(t, batch.Commit(pebble.Sync))
+		require.NoError(t, batch.Close())
+
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{register("a", []byte{2})}, 12, nil))
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{register("a", []byte{})}, 14, nil))
+
+		_, err = ledger.ViewAt(9)
+		require.ErrorIs(t, err, errors.ErrHeightNotArchived)
+		_, err = ledger.ViewAt(15)
+		require.ErrorIs(t, err, errors.ErrHeightOutOfRange)
+
+		for height, expected := range map[uint64][]byte{10: {1}, 11: {1}, 12: {2}, 13: {2}, 14: {}} {
+			view, err := ledger.ViewAt(height)
+			require.NoError(t, err)
+
+			value, ok, err := view.GetValue(owner, []byte("a"))
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, expected, value, "height %d", height)
+		}
+
+		view, err := ledger.ViewAt(10)
+		require.NoError(t, err)
+		_, ok, err := view.GetValue(owner, []byte("a\x00"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+		_, ok, err = view.GetValue(owner, []byte("b"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		view, err = ledger.ViewAt(14)
+		require.NoError(t, err)
+		value, ok, err := view.GetValue(owner, []byte("a\x00"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte{9}, value)
+
+		// the registers updated after the height
+		view, err = ledger.ViewAt(12)
+		require.NoError(t, err)
+		for key, expected := range map[string]bool{"a": true, "a\x00": false, "b": false} {
+			updated, err := view.UpdatedAfter(owner, []byte(key))
+			require.NoError(t, err)
+			assert.Equal(t, expected, updated, "register %q", key)
+		}
+
+		view, err = ledger.ViewAt(10)
+		require.NoError(t, err)
+		updated, err := view.UpdatedAfter(owner, []byte("a\x00"))
+		require.NoError(t, err)
+		assert.True(t, updated)
+	})
+
+	runDB("view requires the archive", t, func(t *testing.T, db *Storage) {
+		ledger := NewLedger(db)
+		require.NoError(t, ledger.Store(flowGo.RegisterEntries{register("a", []byte{1})}, 10, nil))
+
+		_, err := ledger.ViewAt(10)
+		require.ErrorIs(t, err, errors.ErrHeightOutOfRange)
+	})
+}
+
+func TestSubmissions(t *testing.T) {
+	runDB("pending submissions", t, func(t *testing.T, db *Storage) {
+		submissions := NewSubmissions(db)
+
+		pending := &models.Submission{
+			Hash:        common.HexToHash("0x1"),
+			RawTx:       []byte{0x1, 0x2},
+			FlowID:      flow.Identifier{0x1},
+			KeyIndex:    3,
+			Status:      models.SubmissionSubmitted,
+			SubmittedAt: 100,
+		}
+		require.NoError(t, submissions.Store(pending))
+		require.NoError(t, submissions.Store(&models.Submission{
+			Hash:   common.HexToHash("0x2"),
+			FlowID: flow.Identifier{0x2},
+			Status: models.SubmissionSealed,
+		}))
+
+		stored, err := submissions.Get(pending.Hash)
+		require.NoError(t, err)
+		require.Equal(t, pending, stored)
+
+		submitted, err := submissions.Pending()
+		require.NoError(t, err)
+		require.Equal(t, []*models.Submission{pending}, submitted)
+
+		// the submission is no longer pending once completed
+		pending.Status = models.SubmissionFailed
+		pending.Error = "not sealed in time"
+		require.NoError(t, submissions.Store(pending))
+
+		submitted, err = submissions.Pending()
+		require.NoError(t, err)
+		require.Empty(t, submitted)
+
+		_, err = submissions.Get(common.HexToHash("0x3"))
+		require.ErrorIs(t, err, errors.ErrEntityNotFound)
+	})
+}
+
 func runDB(name string, t *testing.T, f func(t *testing.T, db *Storage)) {
 	dir := t.TempDir()
 
@@ -0,0 +1,111 @@
+package pebble
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/onflow/go-ethereum/common"
+
+	"github.com/onflow/flow-evm-gateway/models"
+	"github.com/onflow/flow-evm-gateway/storage"
+)
+
+var _ storage.SubmissionIndexer = &Submissions{}
+
+// Submissions stores the submissions of the EVM transactions made by the gateway,
+// so the submissions in flight can be tracked again after a restart.
+type Submissions struct {
+	store *Storage
+	mux   sync.RWMutex
+}
+
+func NewSubmissions(store *Storage) *Submissions {
+	return &Submissions{
+		store: store,
+		mux:   sync.RWMutex{},
+	}
+}
+
+func (s *Submissions) Store(submission *models.Submission) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	val, err := submission.ToBytes()
+	if err != nil {
+		return err
+	}
+
+	batch := s.store.NewBatch()
+	defer func() {
+		if err := batch.Close(); err != nil {
+			s.store.log.Error().Err(err).Msg("failed to close submissions batch")
+		}
+	}()
+
+	hash := submission.Hash.Bytes()
+	if err := s.store.set(submissionKey, hash, val, batch); err != nil {
+		return fmt.Errorf("failed to store submission of transaction: %s, with: %w", submission.Hash, err)
+	}
+
+	if submission.Status == models.SubmissionSubmitted {
+		err = s.store.set(pendingSubmissionKey, hash, []byte{}, batch)
+	} else {
+		err = s.store.delete(pendingSubmissionKey, hash, batch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to index pending submission of transaction: %s, with: %w", submission.Hash, err)
+	}
+
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return fmt.Errorf("failed to commit submission of transaction: %s, with: %w", submission.Hash, err)
+	}
+
+	return nil
+}
+
+func (s *Submissions) Get(hash common.Hash) (*models.Submission, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	return s.getSubmission(hash)
+}
+
+func (s *Submissions) Pending() ([]*models.Submission, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	iterator, err := s.store.db.NewIter(&pebble.IterOptions{
+		LowerBound: makePrefix(pendingSubmissionKey),
+		UpperBound: makePrefix(pendingSubmissionKey + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := iterator.Close(); err != nil {
+			s.store.log.Error().Err(err).Msg("failed to close submissions iterator")
+		}
+	}()
+
+	var submissions []*models.Submission
+	for iterator.First(); iterator.Valid(); iterator.Next() {
+		hash := common.BytesToHash(stripPrefix(iterator.Key()))
+		submission, err := s.getSubmission(hash)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, submission)
+	}
+
+	return submissions, iterator.Error()
+}
+
+func (s *Submissions) getSubmission(hash common.Hash) (*models.Submission, error) {
+	val, err := s.store.get(submissionKey, hash.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission of transaction: %s, with: %w", hash, err)
+	}
+
+	return models.NewSubmissionFromBytes(val)
+}